@@ -34,26 +34,31 @@ type Entry struct {
 	Start     time.Time
 	Duration  time.Duration
 	RouteName string //CtrlState map[string]string
+	Priority  string
 
 	// Request
-	Url      string
-	Path     string
-	Host     string
-	Protocol string
-	Method   string
-	Header   http.Header
+	Url          string
+	Path         string
+	Host         string
+	UpstreamHost string
+	Protocol     string
+	Method       string
+	Header       http.Header
 
 	// Response
 	StatusCode    int
 	BytesSent     int64
 	BytesReceived int64
+	GRPCStatus    string // numeric gRPC status code, empty if the call was not gRPC
 
 	// State and
 	Timeout        int
 	RateLimit      rate.Limit
 	RateBurst      int
 	RateThreshold  string
+	RateKey        string
 	Retry          string
+	RetryAttempt   int
 	Proxy          string
 	ProxyThreshold string
 	StatusFlags    string
@@ -63,12 +68,13 @@ func NewEmptyEntry() *Entry {
 	return new(Entry)
 }
 
-func NewEntry(traffic string, start time.Time, duration time.Duration, req *http.Request, resp *http.Response, routeName string, timeout int, rateLimit rate.Limit, rateBurst int, rateThreshold, retry, proxy, proxyThreshold, statusFlags string) *Entry {
+func NewEntry(traffic string, start time.Time, duration time.Duration, req *http.Request, resp *http.Response, routeName, priority string, timeout int, rateLimit rate.Limit, rateBurst int, rateThreshold, rateKey, retry string, attempt int, proxy, proxyThreshold, statusFlags string) *Entry {
 	e := new(Entry)
 	e.Traffic = traffic
 	e.Start = start
 	e.Duration = duration
 	e.RouteName = routeName
+	e.Priority = priority
 
 	e.AddRequest(req)
 	e.AddResponse(resp)
@@ -76,20 +82,22 @@ func NewEntry(traffic string, start time.Time, duration time.Duration, req *http
 	e.Timeout = timeout
 	e.RateLimit = rateLimit
 	e.RateBurst = rateBurst
+	e.RateKey = rateKey
 	e.Retry = retry
+	e.RetryAttempt = attempt
 	e.Proxy = proxy
 	e.StatusFlags = statusFlags
 	return e
 }
 
 // NewEgressEntry - create an Entry for egress traffic
-func NewEgressEntry(start time.Time, duration time.Duration, req *http.Request, resp *http.Response, routeName string, timeout int, rateLimit rate.Limit, rateBurst int, rateThreshold, retry, proxy, proxyThreshold, statusFlags string) *Entry {
-	return NewEntry(EgressTraffic, start, duration, req, resp, routeName, timeout, rateLimit, rateBurst, rateThreshold, retry, proxy, proxyThreshold, statusFlags)
+func NewEgressEntry(start time.Time, duration time.Duration, req *http.Request, resp *http.Response, routeName, priority string, timeout int, rateLimit rate.Limit, rateBurst int, rateThreshold, rateKey, retry string, attempt int, proxy, proxyThreshold, statusFlags string) *Entry {
+	return NewEntry(EgressTraffic, start, duration, req, resp, routeName, priority, timeout, rateLimit, rateBurst, rateThreshold, rateKey, retry, attempt, proxy, proxyThreshold, statusFlags)
 }
 
 // NewIngressEntry - create an Entry for ingress traffic
-func NewIngressEntry(start time.Time, duration time.Duration, req *http.Request, resp *http.Response, routeName string, timeout int, rateLimit rate.Limit, rateBurst int, rateThreshold, retry, proxy, proxyThreshold, statusFlags string) *Entry {
-	return NewEntry(IngressTraffic, start, duration, req, resp, routeName, timeout, rateLimit, rateBurst, rateThreshold, retry, proxy, proxyThreshold, statusFlags)
+func NewIngressEntry(start time.Time, duration time.Duration, req *http.Request, resp *http.Response, routeName, priority string, timeout int, rateLimit rate.Limit, rateBurst int, rateThreshold, rateKey, retry string, attempt int, proxy, proxyThreshold, statusFlags string) *Entry {
+	return NewEntry(IngressTraffic, start, duration, req, resp, routeName, priority, timeout, rateLimit, rateBurst, rateThreshold, rateKey, retry, attempt, proxy, proxyThreshold, statusFlags)
 }
 
 func (l *Entry) AddResponse(resp *http.Response) {
@@ -98,6 +106,12 @@ func (l *Entry) AddResponse(resp *http.Response) {
 	}
 	l.StatusCode = resp.StatusCode
 	l.BytesReceived = resp.ContentLength
+	// Trailers-only gRPC responses carry the status in the headers
+	if s := resp.Trailer.Get(GRPCStatusHeaderName); s != "" {
+		l.GRPCStatus = s
+	} else if s = resp.Header.Get(GRPCStatusHeaderName); s != "" {
+		l.GRPCStatus = s
+	}
 }
 
 func (l *Entry) AddUrl(uri string) {
@@ -144,6 +158,7 @@ func (l *Entry) AddRequest(req *http.Request) {
 	} else {
 		l.Url = req.URL.String()
 		l.Path = req.URL.Path
+		l.UpstreamHost = req.URL.Host
 		if req.Host == "" {
 			l.Host = req.URL.Host
 		} else {
@@ -201,16 +216,22 @@ func (l *Entry) Value(value string) string {
 		// Response
 	case StatusFlagsOperator:
 		return l.StatusFlags
+	case UpstreamHostOperator:
+		return l.UpstreamHost
 	case ResponseBytesReceivedOperator:
 		return strconv.Itoa(int(l.BytesReceived))
 	case ResponseBytesSentOperator:
 		return fmt.Sprintf("%v", l.BytesSent)
 	case ResponseStatusCodeOperator:
 		return strconv.Itoa(l.StatusCode)
+	case GRPCStatusNumberOperator:
+		return grpcStatusValue(l.GRPCStatus, GRPCNumber)
 
 	// Controller State
 	case RouteNameOperator:
 		return l.RouteName
+	case PriorityOperator:
+		return l.Priority
 	case TimeoutDurationOperator:
 		return strconv.Itoa(l.Timeout)
 	case RateLimitOperator:
@@ -219,12 +240,19 @@ func (l *Entry) Value(value string) string {
 		return strconv.Itoa(l.RateBurst)
 	case RateThresholdOperator:
 		return l.RateThreshold
+	case RateKeyOperator:
+		return l.RateKey
 	case ProxyOperator:
 		return l.Proxy
 	case ProxyThresholdOperator:
 		return l.ProxyThreshold
 	case RetryOperator:
 		return l.Retry
+	case RetryAttemptOperator:
+		if l.RetryAttempt <= 0 {
+			return ""
+		}
+		return strconv.Itoa(l.RetryAttempt)
 		//case RetryRateLimitOperator:
 		//		return l.CtrlState[RetryRateLimitName]
 		//	case RetryRateBurstOperator:
@@ -234,6 +262,9 @@ func (l *Entry) Value(value string) string {
 		name := requestOperatorHeaderName(value)
 		return l.Header.Get(name)
 	}
+	if strings.HasPrefix(value, GRPCStatusPrefix) {
+		return grpcStatusValue(l.GRPCStatus, grpcStatusFormat(value))
+	}
 	if !strings.HasPrefix(value, OperatorPrefix) {
 		return value
 	}
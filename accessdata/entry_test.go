@@ -51,7 +51,7 @@ func Example_Value_Controller() {
 	data = Entry{RouteName: name}
 	fmt.Printf("test: Value(\"%v\") -> [route_name:%v]\n", name, data.Value(op))
 
-	data1 := NewEntry(PingTraffic, start, time.Since(start), nil, nil, name, -1, -1, -1, "95/500s", "", "", "", "")
+	data1 := NewEntry(PingTraffic, start, time.Since(start), nil, nil, name, "", -1, -1, -1, "95/500s", "", "", -1, "", "", "")
 	fmt.Printf("test: Value(\"%v\") -> [traffic:%v]\n", name, data1.Value(TrafficOperator))
 
 	data = Entry{Timeout: 500}
@@ -87,6 +87,17 @@ func Example_Value_Request() {
 	//test: Value("headers") -> [request-id:123-456-789] [from-route:calling-route]
 }
 
+func Example_Value_UpstreamHost() {
+	req, _ := http.NewRequest("GET", "https://www.google.com/search", nil)
+	req.URL.Host = "host-1:8080"
+	data := &Entry{}
+	data.AddRequest(req)
+	fmt.Printf("test: Value(\"upstream-host\") -> [host:%v] [upstream-host:%v]\n", data.Value(RequestHostOperator), data.Value(UpstreamHostOperator))
+
+	//Output:
+	//test: Value("upstream-host") -> [host:www.google.com] [upstream-host:host-1:8080]
+}
+
 func Example_Value_Response() {
 	op := ResponseStatusCodeOperator
 
@@ -103,6 +114,33 @@ func Example_Value_Response() {
 	//test: Value("code") -> [200]
 }
 
+func Example_Value_GRPCStatus() {
+	data := &Entry{}
+	fmt.Printf("test: Value(\"grpc-status\") -> [%v]\n", data.Value(GRPCStatusOperator))
+
+	resp := &http.Response{StatusCode: 504, Trailer: http.Header{}}
+	resp.Trailer.Set(GRPCStatusHeaderName, "4")
+	data.AddResponse(resp)
+	fmt.Printf("test: Value(\"grpc-status\") -> [default:%v] [camel:%v] [snake:%v] [number:%v] [grpc-number:%v]\n", data.Value(GRPCStatusOperator), data.Value("%GRPC_STATUS(CAMEL_STRING)%"),
+		data.Value("%GRPC_STATUS(SNAKE_STRING)%"), data.Value("%GRPC_STATUS(NUMBER)%"), data.Value(GRPCStatusNumberOperator))
+
+	// Trailers-only response
+	resp = &http.Response{StatusCode: 200, Header: http.Header{}}
+	resp.Header.Set(GRPCStatusHeaderName, "1")
+	data = &Entry{}
+	data.AddResponse(resp)
+	fmt.Printf("test: Value(\"grpc-status\") -> [camel:%v] [snake:%v]\n", data.Value("%GRPC_STATUS(CAMEL_STRING)%"), data.Value("%GRPC_STATUS(SNAKE_STRING)%"))
+
+	data = &Entry{GRPCStatus: "94"}
+	fmt.Printf("test: Value(\"grpc-status\") -> [camel:%v]\n", data.Value("%GRPC_STATUS(CAMEL_STRING)%"))
+
+	//Output:
+	//test: Value("grpc-status") -> []
+	//test: Value("grpc-status") -> [default:DeadlineExceeded] [camel:DeadlineExceeded] [snake:DEADLINE_EXCEEDED] [number:4] [grpc-number:4]
+	//test: Value("grpc-status") -> [camel:Canceled] [snake:CANCELLED]
+	//test: Value("grpc-status") -> [camel:94]
+}
+
 func Example_Value_Request_Header() {
 	req, _ := http.NewRequest("", "www.google.com", nil)
 	req.Header.Add("customer", "Ted's Bait & Tackle")
@@ -125,7 +163,7 @@ func Example_EgressEntry() {
 	resp := new(http.Response)
 	resp.StatusCode = 201
 
-	e := NewEgressEntry(start, 0, req, resp, "egress-route", -1, -1, -1, "", "", "", "", "RL")
+	e := NewEgressEntry(start, 0, req, resp, "egress-route", "", -1, -1, -1, "", "", "", -1, "", "", "RL")
 	fmt.Printf("test: String() -> {%v}\n", e)
 
 	//Output:
@@ -0,0 +1,45 @@
+package accessdata
+
+import (
+	"strconv"
+)
+
+// https://grpc.github.io/grpc/core/md_doc_statuscodes.html
+
+var grpcStatusNames = []struct {
+	camel string
+	snake string
+}{
+	{"OK", "OK"},
+	{"Canceled", "CANCELLED"},
+	{"Unknown", "UNKNOWN"},
+	{"InvalidArgument", "INVALID_ARGUMENT"},
+	{"DeadlineExceeded", "DEADLINE_EXCEEDED"},
+	{"NotFound", "NOT_FOUND"},
+	{"AlreadyExists", "ALREADY_EXISTS"},
+	{"PermissionDenied", "PERMISSION_DENIED"},
+	{"ResourceExhausted", "RESOURCE_EXHAUSTED"},
+	{"FailedPrecondition", "FAILED_PRECONDITION"},
+	{"Aborted", "ABORTED"},
+	{"OutOfRange", "OUT_OF_RANGE"},
+	{"Unimplemented", "UNIMPLEMENTED"},
+	{"Internal", "INTERNAL"},
+	{"Unavailable", "UNAVAILABLE"},
+	{"DataLoss", "DATA_LOSS"},
+	{"Unauthenticated", "UNAUTHENTICATED"},
+}
+
+// grpcStatusValue - format a numeric gRPC status, codes without a name are formatted as a number
+func grpcStatusValue(status, format string) string {
+	code, err := strconv.Atoi(status)
+	if err != nil {
+		return ""
+	}
+	if format == GRPCNumber || code < 0 || code >= len(grpcStatusNames) {
+		return strconv.Itoa(code)
+	}
+	if format == GRPCSnakeString {
+		return grpcStatusNames[code].snake
+	}
+	return grpcStatusNames[code].camel
+}
@@ -19,12 +19,15 @@ var operators = map[string]*Operator{
 
 	// Route
 	RouteNameOperator:       {"route-name", RouteNameOperator},
+	PriorityOperator:        {"priority", PriorityOperator},
 	TimeoutDurationOperator: {"timeout-ms", TimeoutDurationOperator},
 	RateLimitOperator:       {"rate-limit", RateLimitOperator},
 	RateBurstOperator:       {"rate-burst", RateBurstOperator},
 	RateThresholdOperator:   {"rate-threshold", RateThresholdOperator},
+	RateKeyOperator:         {"rate-key", RateKeyOperator},
 
-	RetryOperator: {"retry", RetryOperator},
+	RetryOperator:        {"retry", RetryOperator},
+	RetryAttemptOperator: {"retry-attempt", RetryAttemptOperator},
 	//RetryRateLimitOperator:  {"retry_rate_limit", RetryRateLimitOperator},
 	//RetryRateBurstOperator:  {"retry_rate_burst", RetryRateBurstOperator},
 	ProxyOperator:          {"proxy", ProxyOperator},
@@ -35,7 +38,7 @@ var operators = map[string]*Operator{
 	ResponseBytesReceivedOperator: {"bytes-received", ResponseBytesReceivedOperator},
 	ResponseBytesSentOperator:     {"bytes-sent", ResponseBytesSentOperator},
 	StatusFlagsOperator:           {"status-flags", StatusFlagsOperator},
-	//UpstreamHostOperator:  {"upstream_host", UpstreamHostOperator},
+	UpstreamHostOperator:          {"upstream-host", UpstreamHostOperator},
 
 	// Request
 	RequestProtocolOperator: {"protocol", RequestProtocolOperator},
@@ -107,5 +110,12 @@ func createOperator(op Operator) (Operator, error) {
 	if IsRequestOperator(op) {
 		return Operator{Name: RequestOperatorHeaderName(op), Value: op.Value}, nil
 	}
+	if IsGRPCStatusOperator(op) {
+		newOp := Operator{Name: operators[GRPCStatusOperator].Name, Value: op.Value}
+		if !IsEmpty(op.Name) {
+			newOp.Name = op.Name
+		}
+		return newOp, nil
+	}
 	return Operator{}, errors.New(fmt.Sprintf("invalid operator: value not found or invalid %v", op.Value))
 }
@@ -7,11 +7,13 @@ import (
 const (
 	OperatorPrefix         = "%"
 	RequestReferencePrefix = "%REQ("
+	GRPCStatusPrefix       = "%GRPC_STATUS("
 
 	RequestIdHeaderName    = "X-REQUEST-ID"
 	FromRouteHeaderName    = "FROM-ROUTE"
 	UserAgentHeaderName    = "USER-AGENT"
 	ForwardedForHeaderName = "X-FORWARDED-FOR"
+	GRPCStatusHeaderName   = "Grpc-Status"
 
 	TrafficOperator        = "%TRAFFIC%"      // ingress, egress, ping
 	StartTimeOperator      = "%START_TIME%"   // start time
@@ -25,11 +27,14 @@ const (
 	OriginInstanceIdOperator = "%INSTANCE_ID%" // origin instance id
 
 	RouteNameOperator       = "%ROUTE_NAME%"
+	PriorityOperator        = "%PRIORITY%"
 	TimeoutDurationOperator = "%TIMEOUT_DURATION%"
 	RateLimitOperator       = "%RATE_LIMIT%"
 	RateBurstOperator       = "%RATE_BURST%"
 	RateThresholdOperator   = "%RATE_THRESHOLD%"
+	RateKeyOperator         = "%RATE_KEY%"
 	RetryOperator           = "%RETRY%"
+	RetryAttemptOperator    = "%RETRY_ATTEMPT%"
 	ProxyOperator           = "%PROXY%"
 	ProxyThresholdOperator  = "%PROXY_THRESHOLD%"
 
@@ -37,7 +42,7 @@ const (
 	ResponseBytesReceivedOperator = "%BYTES_RECEIVED%" // bytes received
 	ResponseBytesSentOperator     = "%BYTES_SENT%"     // bytes sent
 	StatusFlagsOperator           = "%STATUS_FLAGS%"   // status flags
-	//UpstreamHostOperator  = "%UPSTREAM_HOST%"  // Upstream host URL (e.g., tcp://ip:port for TCP connections).
+	UpstreamHostOperator          = "%UPSTREAM_HOST%"  // upstream host, the selected endpoint of a pool
 
 	RequestProtocolOperator = "%PROTOCOL%" // HTTP Protocol
 	RequestMethodOperator   = "%METHOD%"   // HTTP method
@@ -54,6 +59,9 @@ const (
 	GRPCStatusOperator       = "%GRPC_STATUS(X)%"     // gRPC status code formatted according to the optional parameter X, which can be CAMEL_STRING, SNAKE_STRING and NUMBER. X-REQUEST-ID request header value
 	GRPCStatusNumberOperator = "%GRPC_STATUS_NUMBER%" // gRPC status code.
 
+	GRPCCamelString = "CAMEL_STRING" // DeadlineExceeded, the default
+	GRPCSnakeString = "SNAKE_STRING" // DEADLINE_EXCEEDED
+	GRPCNumber      = "NUMBER"       // 4
 )
 
 // Operator - configuration of logging entries
@@ -90,11 +98,38 @@ func requestOperatorHeaderName(value string) string {
 	return value[len(RequestReferencePrefix) : len(value)-2]
 }
 
+// IsGRPCStatusOperator - determine if an operator is a %GRPC_STATUS(X)% operator with a valid format
+func IsGRPCStatusOperator(op Operator) bool {
+	if !strings.HasPrefix(op.Value, GRPCStatusPrefix) {
+		return false
+	}
+	if len(op.Value) < (len(GRPCStatusPrefix) + 2) {
+		return false
+	}
+	if op.Value[len(op.Value)-2:] != ")%" {
+		return false
+	}
+	switch grpcStatusFormat(op.Value) {
+	case "X", GRPCCamelString, GRPCSnakeString, GRPCNumber:
+		return true
+	}
+	return false
+}
+
+// grpcStatusFormat - the X parameter of a %GRPC_STATUS(X)% operator
+func grpcStatusFormat(value string) string {
+	if len(value) < (len(GRPCStatusPrefix) + 2) {
+		return ""
+	}
+	return value[len(GRPCStatusPrefix) : len(value)-2]
+}
+
 func IsStringValue(op Operator) bool {
 	switch op.Value {
 	case DurationOperator, TimeoutDurationOperator, RateBurstOperator,
-		RateLimitOperator, RetryOperator, ProxyOperator, //RetryRateLimitOperator, RetryRateBurstOperator,
-		ResponseStatusCodeOperator, ResponseBytesSentOperator, ResponseBytesReceivedOperator:
+		RateLimitOperator, RetryOperator, RetryAttemptOperator, ProxyOperator, //RetryRateLimitOperator, RetryRateBurstOperator,
+		ResponseStatusCodeOperator, ResponseBytesSentOperator, ResponseBytesReceivedOperator,
+		GRPCStatusNumberOperator, GRPCStatusPrefix + GRPCNumber + ")%":
 		return false
 	}
 	return true
@@ -20,3 +20,23 @@ func Example_WriteJson() {
 	//Output:
 	//test: writeMarkup() -> [{"first":"string value","second":100,"third":"another string value","fourth":true,"null-value":null}]
 }
+
+func Example_WriteJson_GRPCStatus() {
+	items, err := CreateOperators([]string{ResponseStatusCodeOperator, "%GRPC_STATUS(SNAKE_STRING)%", GRPCStatusNumberOperator})
+	fmt.Printf("test: CreateOperators() -> [err:%v] [items:%v]\n", err, items)
+
+	data := &Entry{StatusCode: 503, GRPCStatus: "14"}
+	fmt.Printf("test: WriteJson() -> [%v]\n", WriteJson(items, data))
+
+	data = &Entry{StatusCode: 200}
+	fmt.Printf("test: WriteJson() -> [%v]\n", WriteJson(items, data))
+
+	_, err = CreateOperators([]string{"%GRPC_STATUS(KEBAB_STRING)%"})
+	fmt.Printf("test: CreateOperators(invalid) -> [err:%v]\n", err)
+
+	//Output:
+	//test: CreateOperators() -> [err:<nil>] [items:[{status-code %STATUS_CODE%} {grpc-status %GRPC_STATUS(SNAKE_STRING)%} {grpc-number %GRPC_STATUS_NUMBER%}]]
+	//test: WriteJson() -> [{"status-code":503,"grpc-status":"UNAVAILABLE","grpc-number":14}]
+	//test: WriteJson() -> [{"status-code":200,"grpc-status":null,"grpc-number":null}]
+	//test: CreateOperators(invalid) -> [err:invalid operator: value not found or invalid %GRPC_STATUS(KEBAB_STRING)%]
+}
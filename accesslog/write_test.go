@@ -12,7 +12,7 @@ func ExampleLog_Error() {
 	start := time.Now()
 
 	Write[TestOutputHandler, accessdata.TextFormatter](nil)
-	Write[TestOutputHandler, accessdata.JsonFormatter](accessdata.NewEgressEntry(start, time.Since(start), nil, nil, "egress-route", -1, -1, -1, "", "", ""))
+	Write[TestOutputHandler, accessdata.JsonFormatter](accessdata.NewEgressEntry(start, time.Since(start), nil, nil, "egress-route", "", -1, -1, -1, "", "", "", -1, "", "", ""))
 
 	//Output:
 	//test: Write() -> [access data entry is nil]
@@ -35,7 +35,7 @@ func ExampleLog_Origin() {
 		return
 	}
 	var start1 time.Time
-	entry := accessdata.NewIngressEntry(start1, time.Since(start), nil, nil, name, 500, 100, 10, "false", "false", "")
+	entry := accessdata.NewIngressEntry(start1, time.Since(start), nil, nil, name, "", 500, 100, 10, "", "", "false", -1, "false", "", "")
 	Write[TestOutputHandler, accessdata.JsonFormatter](entry)
 	Write[TestOutputHandler, accessdata.TextFormatter](entry)
 
@@ -80,7 +80,7 @@ func ExampleLog_Timeout() {
 		return
 	}
 	var start1 time.Time
-	Write[TestOutputHandler, accessdata.JsonFormatter](accessdata.NewEgressEntry(start1, time.Since(start), nil, nil, "handler-route", 5000, -1, -1, "", "", ""))
+	Write[TestOutputHandler, accessdata.JsonFormatter](accessdata.NewEgressEntry(start1, time.Since(start), nil, nil, "handler-route", "", 5000, -1, -1, "", "", "", -1, "", "", ""))
 
 	//Output:
 	//test: Write() -> [{"start-time":"0001-01-01 00:00:00.000000","duration_ms":0,"traffic":"egress","route-name":"handler-route","timeout-ms":5000,"static":"value"}]
@@ -97,7 +97,7 @@ func ExampleLog_RateLimiter_500() {
 		return
 	}
 	var start1 time.Time
-	Write[TestOutputHandler, accessdata.JsonFormatter](accessdata.NewEgressEntry(start1, time.Since(start), nil, nil, "handler-route", -1, 500, 10, "", "", ""))
+	Write[TestOutputHandler, accessdata.JsonFormatter](accessdata.NewEgressEntry(start1, time.Since(start), nil, nil, "handler-route", "", -1, 500, 10, "", "", "", -1, "", "", ""))
 
 	//Output:
 	//test: Write() -> [{"start-time":"0001-01-01 00:00:00.000000","duration":0,"traffic":"egress","route-name":"handler-route","rate-limit":500,"rate-burst":10,"static2":"value2"}]
@@ -115,8 +115,8 @@ func ExampleLog_Retry_Proxy() {
 		return
 	}
 	var start1 time.Time
-	Write[TestOutputHandler, accessdata.JsonFormatter](accessdata.NewEgressEntry(start1, time.Since(start), nil, nil, "handler-route", -1, 123, 67, "", "", ""))
-	Write[TestOutputHandler, accessdata.JsonFormatter](accessdata.NewEgressEntry(start1, time.Since(start), nil, nil, "handler-route", -1, 123, 67, "true", "false", ""))
+	Write[TestOutputHandler, accessdata.JsonFormatter](accessdata.NewEgressEntry(start1, time.Since(start), nil, nil, "handler-route", "", -1, 123, 67, "", "", "", -1, "", "", ""))
+	Write[TestOutputHandler, accessdata.JsonFormatter](accessdata.NewEgressEntry(start1, time.Since(start), nil, nil, "handler-route", "", -1, 123, 67, "", "", "true", -1, "false", "", ""))
 
 	//Output:
 	//test: Write() -> [{"start-time":"0001-01-01 00:00:00.000000","duration_ms":0,"traffic":"egress","route-name":"handler-route","rate-limit":123,"rate-burst":67,"retry":null,"proxy":null}]
@@ -135,8 +135,8 @@ func ExampleLog_Request() {
 		fmt.Printf("%v\n", err)
 		return
 	}
-	Write[TestOutputHandler, accessdata.JsonFormatter](accessdata.NewEgressEntry(start, time.Since(start), nil, nil, "handler-route", -1, -1, -1, "", "", ""))
-	Write[TestOutputHandler, accessdata.JsonFormatter](accessdata.NewEgressEntry(start, time.Since(start), req, nil, "handler-route", -1, -1, -1, "", "", ""))
+	Write[TestOutputHandler, accessdata.JsonFormatter](accessdata.NewEgressEntry(start, time.Since(start), nil, nil, "handler-route", "", -1, -1, -1, "", "", "", -1, "", "", ""))
+	Write[TestOutputHandler, accessdata.JsonFormatter](accessdata.NewEgressEntry(start, time.Since(start), req, nil, "handler-route", "", -1, -1, -1, "", "", "", -1, "", "", ""))
 
 	//Output:
 	//test: Write() -> [{"protocol":null,"method":null,"url":null,"path":null,"host":null,"customer":null}]
@@ -153,8 +153,8 @@ func ExampleLog_Response() {
 		return
 	}
 	var start time.Time
-	Write[TestOutputHandler, accessdata.JsonFormatter](accessdata.NewEgressEntry(start, time.Since(start), nil, nil, "handler-route", -1, -1, -1, "", "", "UT"))
-	Write[TestOutputHandler, accessdata.JsonFormatter](accessdata.NewEgressEntry(start, time.Since(start), nil, resp, "handler-route", -1, -1, -1, "", "", "UT"))
+	Write[TestOutputHandler, accessdata.JsonFormatter](accessdata.NewEgressEntry(start, time.Since(start), nil, nil, "handler-route", "", -1, -1, -1, "", "", "", -1, "", "", "UT"))
+	Write[TestOutputHandler, accessdata.JsonFormatter](accessdata.NewEgressEntry(start, time.Since(start), nil, resp, "handler-route", "", -1, -1, -1, "", "", "", -1, "", "", "UT"))
 
 	//Output:
 	//test: Write() -> [{"status-code":0,"bytes-received":0,"status-flags":"UT"}]
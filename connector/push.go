@@ -27,6 +27,7 @@ var (
 		{Name: "duration-ms", Value: accessdata.DurationOperator},
 		{Name: "traffic", Value: accessdata.TrafficOperator},
 		{Name: "route-name", Value: accessdata.RouteNameOperator},
+		{Name: "priority", Value: accessdata.PriorityOperator},
 
 		{Name: "region", Value: accessdata.OriginRegionOperator},
 		{Name: "zone", Value: accessdata.OriginZoneOperator},
@@ -48,7 +49,9 @@ var (
 		{Name: "rate-limit", Value: accessdata.RateLimitOperator},
 		{Name: "rate-burst", Value: accessdata.RateBurstOperator},
 		{Name: "rate-threshold", Value: accessdata.RateThresholdOperator},
+		{Name: "rate-key", Value: accessdata.RateKeyOperator},
 		{Name: "retry", Value: accessdata.RetryOperator},
+		{Name: "retry-attempt", Value: accessdata.RetryAttemptOperator},
 		{Name: "proxy", Value: accessdata.ProxyOperator},
 		{Name: "proxy-threshold", Value: accessdata.ProxyThresholdOperator},
 		{Name: "status-flags", Value: accessdata.StatusFlagsOperator},
@@ -80,8 +83,8 @@ func ShutdownPush() {
 	}
 }
 
-func extract(traffic string, start time.Time, duration time.Duration, req *http.Request, resp *http.Response, routeName string, timeout int, limit rate.Limit, burst int, rateThreshold, retry, proxy, proxyThreshold, statusFlags string) {
-	pushC <- accessdata.NewEntry(traffic, start, duration, req, resp, routeName, timeout, limit, burst, rateThreshold, retry, proxy, proxyThreshold, statusFlags)
+func extract(traffic string, start time.Time, duration time.Duration, req *http.Request, resp *http.Response, routeName, priority string, timeout int, limit rate.Limit, burst int, rateThreshold, rateKey, retry string, attempt int, proxy, proxyThreshold, statusFlags string) {
+	pushC <- accessdata.NewEntry(traffic, start, duration, req, resp, routeName, priority, timeout, limit, burst, rateThreshold, rateKey, retry, attempt, proxy, proxyThreshold, statusFlags)
 }
 
 func pushDo(entry *accessdata.Entry) bool {
@@ -4,12 +4,15 @@ import (
 	"errors"
 	"fmt"
 	"golang.org/x/time/rate"
+	"math"
 	"net/url"
 	"strconv"
 )
 
 const (
 	BehaviorKey = "behavior"
+	CallerKey   = "caller"
+	TTLKey      = "ttl"
 
 	RateLimitKey = "limit"
 	RateBurstKey = "burst"
@@ -18,6 +21,28 @@ const (
 	PatternKey   = "pattern"
 	WaitKey      = "wait"
 	PercentKey   = "pct"
+	RatioKey     = "ratio"
+	FailuresKey  = "failures"
+	CoolDownKey  = "cool-down"
+	StateKey     = "state"
+
+	ConcurrencyKey  = "concurrency"
+	QueueKey        = "queue"
+	QueueTimeoutKey = "queue-timeout"
+
+	AttemptsKey = "attempts"
+	BackoffKey  = "backoff"
+	MaxWaitKey  = "max-wait"
+	BudgetKey   = "budget"
+
+	DelayKey  = "delay"
+	HedgesKey = "hedges"
+
+	WeightKey = "weight"
+	SampleKey = "sample"
+	PolicyKey = "policy"
+
+	IntervalKey = "interval"
 
 	FalseValue = "false"
 	TrueValue  = "true"
@@ -27,6 +52,13 @@ const (
 	RateLimitBehavior = "rate-limit"
 	ProxyBehavior     = "proxy"
 
+	CircuitBreakerBehavior = "circuit-breaker"
+	BulkheadBehavior       = "bulkhead"
+	HedgeBehavior          = "hedge"
+	MirrorBehavior         = "mirror"
+	PoolBehavior           = "pool"
+	HealthCheckBehavior    = "health-check"
+
 	NilPercentageValue = float64(-1)
 )
 
@@ -49,6 +81,15 @@ func IsEnable(values url.Values) bool {
 	return !IsDisable(values)
 }
 
+// IsBehavior - determine if the name is a behavior name
+func IsBehavior(name string) bool {
+	switch name {
+	case TimeoutBehavior, RetryBehavior, RateLimitBehavior, ProxyBehavior, CircuitBreakerBehavior, BulkheadBehavior, HedgeBehavior, MirrorBehavior, PoolBehavior, HealthCheckBehavior:
+		return true
+	}
+	return false
+}
+
 func NewValues(key, value string) url.Values {
 	values := url.Values{}
 	values.Set(key, value)
@@ -110,22 +151,64 @@ func ParseLimitAndBurst(values url.Values) (rate.Limit, int, error) {
 	return limit, burst, nil
 }
 
-func ParsePercentage(values url.Values) float64 {
+// ParsePercentage - parse a percentage adjustment as a ratio, the accepted range is > -100 and <= 1000, excluding 0.
+// Returns NilPercentageValue if there is no percentage
+func ParsePercentage(values url.Values) (float64, error) {
 	if values == nil {
-		return NilPercentageValue
+		return NilPercentageValue, nil
 	}
 	if values.Has(PercentKey) {
 		s := values.Get(PercentKey)
 		if len(s) > 0 {
 			temp, err := strconv.ParseFloat(s, 64)
-			if err != nil {
-				return NilPercentageValue
-			}
-			if temp <= 0 || temp > 1000 {
-				return NilPercentageValue
+			if err != nil || math.IsNaN(temp) || temp == 0 || temp <= -100 || temp > 1000 {
+				return NilPercentageValue, errors.New(fmt.Sprintf("invalid argument: percentage value must be > -100 and <= 1000, excluding 0 [%v]", s))
 			}
-			return temp / float64(100)
+			return temp / float64(100), nil
 		}
 	}
-	return NilPercentageValue
+	return NilPercentageValue, nil
+}
+
+// limitAdjust - scale a limit by a percentage ratio, an infinite limit is not adjusted
+func limitAdjust(limit rate.Limit, pct float64, min, max rate.Limit) rate.Limit {
+	if limit == rate.Inf || limit <= 0 {
+		return limit
+	}
+	limit += rate.Limit(pct * float64(limit))
+	if min > 0 && limit < min {
+		limit = min
+	}
+	if max > 0 && limit > max {
+		limit = max
+	}
+	return limit
+}
+
+// burstAdjust - scale a burst by a percentage ratio, the burst is rounded and is at least 1
+func burstAdjust(burst int, pct float64, min, max int) int {
+	if burst <= 0 {
+		return burst
+	}
+	burst += int(math.Round(pct * float64(burst)))
+	if burst < 1 {
+		burst = 1
+	}
+	if min > 0 && burst < min {
+		burst = min
+	}
+	if max > 0 && burst > max {
+		burst = max
+	}
+	return burst
+}
+
+func validateBounds(minLimit, maxLimit rate.Limit, minBurst, maxBurst int) error {
+	if minLimit < 0 || maxLimit < 0 || minBurst < 0 || maxBurst < 0 {
+		return errors.New("limit and burst bounds are < 0")
+	}
+	if (maxLimit > 0 && minLimit > maxLimit) || (maxBurst > 0 && minBurst > maxBurst) {
+		return errors.New("limit and burst floors are greater than the ceilings")
+	}
+	return nil
 }
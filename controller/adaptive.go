@@ -0,0 +1,175 @@
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+)
+
+// https://github.com/Netflix/concurrency-limits
+
+const (
+	AIMDLimit     = "aimd"
+	GradientLimit = "gradient"
+
+	DefaultInitialLimit = 20
+	DefaultMinLimit     = 1
+	DefaultMaxLimit     = 1000
+	DefaultBackoffRatio = 0.9
+
+	gradientSmoothing = 0.2
+	gradientTolerance = 1.5
+	longRttWindow     = 600
+)
+
+// AdaptiveLimitConfig - configuration for an adaptive concurrency limit, the limit is adjusted from observed
+// latency and failures, rather than a static limit and burst
+type AdaptiveLimitConfig struct {
+	Algorithm    string  // aimd or gradient
+	InitialLimit int     // starting concurrency limit
+	MinLimit     int     // the limit never decreases below the minimum
+	MaxLimit     int     // the limit never increases above the maximum
+	BackoffRatio float64 // aimd multiplicative decrease on a failure, in the range 0.5..1
+}
+
+func NewAdaptiveLimitConfig(algorithm string, initialLimit, minLimit, maxLimit int) *AdaptiveLimitConfig {
+	c := new(AdaptiveLimitConfig)
+	if initialLimit <= 0 {
+		initialLimit = DefaultInitialLimit
+	}
+	if minLimit <= 0 {
+		minLimit = DefaultMinLimit
+	}
+	if maxLimit <= 0 {
+		maxLimit = DefaultMaxLimit
+	}
+	c.Algorithm = algorithm
+	c.InitialLimit = initialLimit
+	c.MinLimit = minLimit
+	c.MaxLimit = maxLimit
+	c.BackoffRatio = DefaultBackoffRatio
+	return c
+}
+
+func (c *AdaptiveLimitConfig) validate(name string) error {
+	if c.Algorithm != AIMDLimit && c.Algorithm != GradientLimit {
+		return errors.New(fmt.Sprintf("invalid configuration: RateLimiter adaptive algorithm is invalid [%v] [%v]", c.Algorithm, name))
+	}
+	if c.MinLimit <= 0 || c.MaxLimit < c.MinLimit {
+		return errors.New(fmt.Sprintf("invalid configuration: RateLimiter adaptive minimum and maximum limits are invalid [%v]", name))
+	}
+	if c.InitialLimit < c.MinLimit || c.InitialLimit > c.MaxLimit {
+		return errors.New(fmt.Sprintf("invalid configuration: RateLimiter adaptive initial limit is not in the range of the minimum and maximum limits [%v]", name))
+	}
+	if c.Algorithm == AIMDLimit && (c.BackoffRatio < 0.5 || c.BackoffRatio >= 1) {
+		return errors.New(fmt.Sprintf("invalid configuration: RateLimiter adaptive backoff ratio is not in the range 0.5..1 [%v]", name))
+	}
+	return nil
+}
+
+// adaptiveLimit - concurrency limit state shared by all clones of a rate limiter
+type adaptiveLimit struct {
+	mu       sync.Mutex
+	config   AdaptiveLimitConfig
+	limit    float64
+	inFlight int
+	longRtt  float64
+	samples  int
+}
+
+func newAdaptiveLimit(config *AdaptiveLimitConfig) *adaptiveLimit {
+	a := new(adaptiveLimit)
+	a.config = *config
+	if a.config.BackoffRatio == 0 {
+		a.config.BackoffRatio = DefaultBackoffRatio
+	}
+	a.limit = float64(a.config.InitialLimit)
+	return a
+}
+
+// acquire - acquire a permit if the number of requests in flight is below the current limit, less the
+// reserve for higher priorities. Critical requests always acquire a permit
+func (a *adaptiveLimit) acquire(reserve float64, critical bool) bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if !critical && a.inFlight >= a.reserved(reserve) {
+		return false
+	}
+	a.inFlight++
+	return true
+}
+
+func (a *adaptiveLimit) reserved(reserve float64) int {
+	return int(math.Ceil(a.limit * (1 - reserve)))
+}
+
+// available - determine if a permit could be acquired
+func (a *adaptiveLimit) available(reserve float64) bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.inFlight < a.reserved(reserve)
+}
+
+// release - release a permit and update the limit from the observed latency and outcome
+func (a *adaptiveLimit) release(latency time.Duration, failure bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	inFlight := a.inFlight
+	if a.inFlight > 0 {
+		a.inFlight--
+	}
+	switch a.config.Algorithm {
+	case AIMDLimit:
+		a.aimd(inFlight, failure)
+	case GradientLimit:
+		a.gradient(inFlight, latency, failure)
+	}
+	a.limit = math.Min(float64(a.config.MaxLimit), math.Max(float64(a.config.MinLimit), a.limit))
+}
+
+// aimd - additive increase when the limit is being used, multiplicative decrease on a failure
+func (a *adaptiveLimit) aimd(inFlight int, failure bool) {
+	if failure {
+		a.limit = math.Floor(a.limit * a.config.BackoffRatio)
+		return
+	}
+	if inFlight*2 >= int(a.limit) {
+		a.limit++
+	}
+}
+
+// gradient - adjust the limit by the ratio of the long term latency to the current latency, allowing
+// a queue of sqrt(limit) requests. Failures are treated as a latency at the tolerance
+func (a *adaptiveLimit) gradient(inFlight int, latency time.Duration, failure bool) {
+	rtt := float64(latency)
+	if rtt <= 0 {
+		return
+	}
+	if a.samples < longRttWindow {
+		a.samples++
+	}
+	if a.longRtt == 0 {
+		a.longRtt = rtt
+	} else {
+		a.longRtt += (rtt - a.longRtt) / float64(a.samples)
+	}
+	ratio := a.longRtt * gradientTolerance / rtt
+	if failure {
+		ratio = 1 / gradientTolerance
+	}
+	gradient := math.Max(0.5, math.Min(1.0, ratio))
+	// Do not grow the limit when the limit is not being used
+	if gradient == 1.0 && inFlight*2 < int(a.limit) {
+		return
+	}
+	newLimit := a.limit*gradient + math.Sqrt(a.limit)
+	a.limit = a.limit*(1-gradientSmoothing) + newLimit*gradientSmoothing
+}
+
+func (a *adaptiveLimit) current() int {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return int(a.limit)
+}
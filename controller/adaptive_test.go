@@ -0,0 +1,91 @@
+package controller
+
+import (
+	"fmt"
+	"time"
+)
+
+func Example_newAdaptiveLimit() {
+	config := NewRateLimiterConfig(true, 503, 0, 0, "")
+	config.Adaptive = NewAdaptiveLimitConfig(AIMDLimit, 4, 2, 10)
+	t := newRateLimiter("test-route", nil, config)
+	limit, burst, _ := rateLimiterState(t)
+	fmt.Printf("test: newRateLimiter() -> [adaptive:%v] [limit:%v] [state-limit:%v] [state-burst:%v]\n", t.IsAdaptive(), t.ConcurrencyLimit(), limit, burst)
+
+	config.Adaptive = NewAdaptiveLimitConfig("vegas", 4, 2, 10)
+	fmt.Printf("test: validate() -> [%v]\n", newRateLimiter("test-route", nil, config).validate())
+
+	config.Adaptive = NewAdaptiveLimitConfig(GradientLimit, 40, 2, 10)
+	fmt.Printf("test: validate() -> [%v]\n", newRateLimiter("test-route", nil, config).validate())
+
+	fmt.Printf("test: newRateLimiter(static) -> [adaptive:%v] [limit:%v]\n", nilRateLimiter.IsAdaptive(), nilRateLimiter.ConcurrencyLimit())
+
+	config.Adaptive = NewAdaptiveLimitConfig(AIMDLimit, 4, 2, 10)
+	errs := newTable(true, false).AddController(NewRoute("test-route", EgressTraffic, "", false, config))
+	fmt.Printf("test: AddController(egress) -> %v\n", errs)
+
+	//Output:
+	//test: newRateLimiter() -> [adaptive:true] [limit:4] [state-limit:4] [state-burst:-1]
+	//test: validate() -> [invalid configuration: RateLimiter adaptive algorithm is invalid [vegas] [test-route]]
+	//test: validate() -> [invalid configuration: RateLimiter adaptive initial limit is not in the range of the minimum and maximum limits [test-route]]
+	//test: newRateLimiter(static) -> [adaptive:false] [limit:-1]
+	//test: AddController(egress) -> [invalid configuration: RateLimiter adaptive limits are not valid for egress traffic]
+
+}
+
+func ExampleRateLimiter_AIMD() {
+	config := NewRateLimiterConfig(true, 503, 0, 0, "")
+	config.Adaptive = NewAdaptiveLimitConfig(AIMDLimit, 4, 2, 5)
+	t := newRateLimiter("test-route", nil, config)
+
+	fmt.Printf("test: Acquire() -> [%v %v %v %v %v]\n", t.Acquire(""), t.Acquire(""), t.Acquire(""), t.Acquire(""), t.Acquire(""))
+
+	t.Release(time.Millisecond, false)
+	fmt.Printf("test: Release(success) -> [limit:%v]\n", t.ConcurrencyLimit())
+
+	t.Release(time.Millisecond, false)
+	fmt.Printf("test: Release(max) -> [limit:%v]\n", t.ConcurrencyLimit())
+
+	t.Release(time.Millisecond, true)
+	fmt.Printf("test: Release(failure) -> [limit:%v]\n", t.ConcurrencyLimit())
+
+	t.Release(time.Millisecond, true)
+	t.Release(time.Millisecond, true)
+	fmt.Printf("test: Release(min) -> [limit:%v] [allow:%v]\n", t.ConcurrencyLimit(), t.Allow())
+
+	//Output:
+	//test: Acquire() -> [true true true true false]
+	//test: Release(success) -> [limit:5]
+	//test: Release(max) -> [limit:5]
+	//test: Release(failure) -> [limit:4]
+	//test: Release(min) -> [limit:2] [allow:true]
+
+}
+
+func ExampleRateLimiter_Gradient() {
+	config := NewRateLimiterConfig(true, 503, 0, 0, "")
+	config.Adaptive = NewAdaptiveLimitConfig(GradientLimit, 10, 2, 100)
+	t := newRateLimiter("test-route", nil, config)
+
+	for i := 0; i < 20; i++ {
+		for j := 0; j < 10; j++ {
+			t.Acquire("")
+		}
+		for j := 0; j < 10; j++ {
+			t.Release(time.Millisecond*10, false)
+		}
+	}
+	grown := t.ConcurrencyLimit()
+	fmt.Printf("test: Release(steady-latency) -> [grown:%v]\n", grown > 10)
+
+	for i := 0; i < 20; i++ {
+		t.Acquire("")
+		t.Release(time.Millisecond*100, false)
+	}
+	fmt.Printf("test: Release(latency-increase) -> [reduced:%v]\n", t.ConcurrencyLimit() < grown)
+
+	//Output:
+	//test: Release(steady-latency) -> [grown:true]
+	//test: Release(latency-increase) -> [reduced:true]
+
+}
@@ -3,6 +3,7 @@ package controller
 import (
 	"context"
 	"github.com/go-sre/core/runtime"
+	"google.golang.org/grpc/codes"
 	"time"
 )
 
@@ -25,7 +26,7 @@ func (e *DebugEgressController) Apply(ctx context.Context, statusCode func() int
 	return nil, nil, false
 }
 
-// EgressApply - function to be used by non Http egress traffic to apply an controller
+// Apply - function to be used by non Http egress traffic to apply a controller
 func Apply(ctx context.Context, statusCode func() int, uri, requestId, method string) (func(), context.Context, bool) {
 	statusFlags := ""
 	limited := false
@@ -38,6 +39,20 @@ func Apply(ctx context.Context, statusCode func() int, uri, requestId, method st
 		limited = true
 		statusFlags = RateLimitFlag
 	}
+	// The bulkhead is acquired first, so that a rejected request does not hold a half-open probe of the circuit breaker
+	bh := ctrl.Bulkhead()
+	acquired := false
+	if !limited && bh.IsEnabled() {
+		if acquired = bh.Acquire(ctx); !acquired {
+			limited = true
+			statusFlags = BulkheadFlag
+		}
+	}
+	cb := ctrl.CircuitBreaker()
+	if !limited && cb.IsEnabled() && !cb.Allow() {
+		limited = true
+		statusFlags = CircuitBreakerFlag
+	}
 	if !limited {
 		if to := ctrl.Timeout(); to.IsEnabled() {
 			newCtx, cancelCtx = context.WithTimeout(ctx, to.Duration())
@@ -47,10 +62,17 @@ func Apply(ctx context.Context, statusCode func() int, uri, requestId, method st
 		if cancelCtx != nil {
 			cancelCtx()
 		}
+		if acquired {
+			bh.Release()
+		}
 		code := statusCode()
 		if code == StatusDeadlineExceeded {
 			statusFlags = UpstreamTimeoutFlag
 		}
+		if !limited && cb.IsEnabled() {
+			// Circuit breaker failure status codes are HTTP status codes
+			cb.Record(HttpStatusFromCode(codes.Code(code)), nil)
+		}
 		ctrl.LogEgress(start, time.Since(start), code, uri, requestId, method, statusFlags)
 	}, newCtx, limited
 }
@@ -29,7 +29,7 @@ func (t *testStatus) Code() uint32 {
 }
 
 func init() {
-	defaultLogFn = func(traffic string, start time.Time, duration time.Duration, req *http.Request, resp *http.Response, routeName string, timeout int, limit rate.Limit, burst int, rateThreshold, retry, proxy, proxyThreshold, statusFlags string) {
+	defaultLogFn = func(traffic string, start time.Time, duration time.Duration, req *http.Request, resp *http.Response, routeName, priority string, timeout int, limit rate.Limit, burst int, rateThreshold, rateKey, retry string, attempt int, proxy, proxyThreshold, statusFlags string) {
 		_, host, path := ParseUri(req.URL.String())
 		s := fmt.Sprintf("traffic:%v ,"+
 			"route:%v ,"+
@@ -57,7 +57,7 @@ func init() {
 	}
 }
 
-func ExampleEgressApply() {
+func ExampleApply() {
 	function(context.Background())
 
 	//Output:
@@ -65,7 +65,7 @@ func ExampleEgressApply() {
 
 }
 
-func ExampleEgressApply_RateLimit() {
+func ExampleApply_RateLimit() {
 	name := "rate-limit-route"
 	egressTable = NewEgressTable()
 
@@ -84,7 +84,7 @@ func ExampleEgressApply_RateLimit() {
 
 }
 
-func ExampleEgressApply_Timeout() {
+func ExampleApply_Timeout() {
 	name := "timeout-route"
 	egressTable = NewEgressTable()
 
@@ -101,10 +101,39 @@ func ExampleEgressApply_Timeout() {
 	
 }
 
+func ExampleApply_CircuitBreaker() {
+	name := "circuit-breaker-route"
+	egressTable = NewEgressTable()
+
+	route := NewRoute(name, EgressTraffic, "", false, NewCircuitBreakerConfig(true, 0, 0, 2, 0, time.Second, nil))
+	EgressTable().AddController(route)
+	EgressTable().SetUriMatcher(func(uri string, method string) (string, bool) {
+		return name, true
+	})
+
+	// Unavailable and Not Found map to 503 and 404, only 503 is a failure
+	for _, code := range []uint32{14, 5, 14, 14} {
+		fn, _, _ := Apply(context.Background(), func() int { return int(code) }, applyTestUri, "123-456-7890", "GET")
+		fn()
+	}
+	fn, _, limited := Apply(context.Background(), func() int { return StatusRateLimited }, applyTestUri, "123-456-7890", "GET")
+	fn()
+	fmt.Printf("test: Apply() -> [state:%v] [limited:%v]\n", EgressTable().LookupByName(name).CircuitBreaker().CircuitState(), limited)
+
+	//Output:
+	//{traffic:egress ,route:circuit-breaker-route ,request-id:123-456-7890, status-code:14, method:GET, url:urn:postgresql.us-test-1:query.access-log, host:postgresql.us-test-1, path:query.access-log, timeout:-1, rate-limit:-1, rate-burst:-1, rate-threshold:, retry:, proxy:, proxy-threshold:, status-flags:}
+	//{traffic:egress ,route:circuit-breaker-route ,request-id:123-456-7890, status-code:5, method:GET, url:urn:postgresql.us-test-1:query.access-log, host:postgresql.us-test-1, path:query.access-log, timeout:-1, rate-limit:-1, rate-burst:-1, rate-threshold:, retry:, proxy:, proxy-threshold:, status-flags:}
+	//{traffic:egress ,route:circuit-breaker-route ,request-id:123-456-7890, status-code:14, method:GET, url:urn:postgresql.us-test-1:query.access-log, host:postgresql.us-test-1, path:query.access-log, timeout:-1, rate-limit:-1, rate-burst:-1, rate-threshold:, retry:, proxy:, proxy-threshold:, status-flags:}
+	//{traffic:egress ,route:circuit-breaker-route ,request-id:123-456-7890, status-code:14, method:GET, url:urn:postgresql.us-test-1:query.access-log, host:postgresql.us-test-1, path:query.access-log, timeout:-1, rate-limit:-1, rate-burst:-1, rate-threshold:, retry:, proxy:, proxy-threshold:, status-flags:}
+	//{traffic:egress ,route:circuit-breaker-route ,request-id:123-456-7890, status-code:94, method:GET, url:urn:postgresql.us-test-1:query.access-log, host:postgresql.us-test-1, path:query.access-log, timeout:-1, rate-limit:-1, rate-burst:-1, rate-threshold:, retry:, proxy:, proxy-threshold:, status-flags:CB}
+	//test: Apply() -> [state:open] [limited:true]
+
+}
+
 func function(ctx context.Context) (status *testStatus) {
 	var fn func()
 
-	fn, ctx, _ = EgressApply(ctx, func() int { return int((*(&status)).Code()) }, applyTestUri, "123-456-7890", "GET")
+	fn, ctx, _ = Apply(ctx, func() int { return int((*(&status)).Code()) }, applyTestUri, "123-456-7890", "GET")
 	defer fn()
 	return newStatusOK()
 }
@@ -113,7 +142,7 @@ func functionRateLimited(ctx context.Context) (status *testStatus) {
 	var fn func()
 	var limited = false
 
-	fn, ctx, limited = EgressApply(ctx, func() int { return int((*(&status)).Code()) }, applyTestUri, "123-456-7890", "GET")
+	fn, ctx, limited = Apply(ctx, func() int { return int((*(&status)).Code()) }, applyTestUri, "123-456-7890", "GET")
 	defer fn()
 	if limited {
 		return newStatusCode(StatusRateLimited)
@@ -125,7 +154,7 @@ func functionTimeout(ctx context.Context) (status *testStatus) {
 	var fn func()
 	var limited = false
 
-	fn, ctx, limited = EgressApply(ctx, func() int { return int((*(&status)).Code()) }, applyTestUri, "123-456-7890", "GET")
+	fn, ctx, limited = Apply(ctx, func() int { return int((*(&status)).Code()) }, applyTestUri, "123-456-7890", "GET")
 	defer fn()
 	if limited {
 		return newStatusCode(StatusRateLimited)
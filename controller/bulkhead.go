@@ -0,0 +1,315 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Bulkhead - interface for limiting concurrent requests
+type Bulkhead interface {
+	State
+	Actuator
+	Acquire(ctx context.Context) bool
+	Release()
+	StatusCode() int
+	MaxConcurrent() int
+	MaxQueue() int
+	QueueTimeout() time.Duration
+	InFlight() int
+}
+
+type BulkheadConfig struct {
+	Enabled       bool
+	StatusCode    int
+	MaxConcurrent int
+	MaxQueue      int           // requests allowed to wait for a slot, 0 rejects immediately
+	QueueTimeout  time.Duration // maximum wait for a queued request, 0 waits until the request context is done
+}
+
+var nilBulkhead = newBulkhead(NilBehaviorName, nil, NewBulkheadConfig(false, 0, 1, 0, 0))
+
+func NewBulkheadConfig(enabled bool, statusCode int, maxConcurrent, maxQueue int, queueTimeout time.Duration) *BulkheadConfig {
+	c := new(BulkheadConfig)
+	if statusCode <= 0 {
+		statusCode = http.StatusServiceUnavailable
+	}
+	c.Enabled = enabled
+	c.StatusCode = statusCode
+	c.MaxConcurrent = maxConcurrent
+	c.MaxQueue = maxQueue
+	c.QueueTimeout = queueTimeout
+	return c
+}
+
+type bulkhead struct {
+	name   string
+	table  *table
+	config BulkheadConfig
+	slots  *bulkheadSlots
+}
+
+// bulkheadSlots - concurrency state shared by all clones of a bulkhead. The capacity is resized in place, so the
+// maximum holds while requests acquired before a resize are in flight
+type bulkheadSlots struct {
+	mu       sync.Mutex
+	max      int
+	inFlight int
+	waiting  int
+	released chan struct{} // closed, and replaced, when a slot is released or the capacity is resized
+}
+
+func cloneBulkhead(curr *bulkhead) *bulkhead {
+	t := new(bulkhead)
+	*t = *curr
+	return t
+}
+
+func newBulkhead(name string, table *table, config *BulkheadConfig) *bulkhead {
+	t := new(bulkhead)
+	t.name = name
+	t.table = table
+	if config != nil {
+		t.config = *config
+	}
+	t.slots = newBulkheadSlots(t.config.MaxConcurrent)
+	return t
+}
+
+func newBulkheadSlots(max int) *bulkheadSlots {
+	s := new(bulkheadSlots)
+	s.max = max
+	s.released = make(chan struct{})
+	return s
+}
+
+// tryAcquire - acquire a slot if one is available, or add the request to the queue if there is room
+func (s *bulkheadSlots) tryAcquire(maxQueue int) (acquired, queued bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.inFlight < s.max {
+		s.inFlight++
+		return true, false
+	}
+	if s.waiting >= maxQueue {
+		return false, false
+	}
+	s.waiting++
+	return false, true
+}
+
+// wait - wait for a slot as a queued request, until the request is expired or the context is done
+func (s *bulkheadSlots) wait(ctx context.Context, expired <-chan time.Time) bool {
+	s.mu.Lock()
+	defer func() {
+		s.waiting--
+		s.mu.Unlock()
+	}()
+	for s.inFlight >= s.max {
+		released := s.released
+		s.mu.Unlock()
+		select {
+		case <-released:
+		case <-expired:
+			s.mu.Lock()
+			return false
+		case <-ctx.Done():
+			s.mu.Lock()
+			return false
+		}
+		s.mu.Lock()
+	}
+	s.inFlight++
+	return true
+}
+
+func (s *bulkheadSlots) release() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.inFlight > 0 {
+		s.inFlight--
+	}
+	s.notify()
+}
+
+// resize - set the capacity, requests in flight over a reduced capacity drain before new requests are admitted
+func (s *bulkheadSlots) resize(max int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.max == max {
+		return
+	}
+	s.max = max
+	s.notify()
+}
+
+func (s *bulkheadSlots) count() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.inFlight
+}
+
+// notify - wake the queued requests, the caller holds the lock
+func (s *bulkheadSlots) notify() {
+	close(s.released)
+	s.released = make(chan struct{})
+}
+
+func (b *bulkhead) validate() error {
+	if b.config.MaxConcurrent <= 0 {
+		return errors.New(fmt.Sprintf("invalid configuration: Bulkhead max concurrent is <= 0 [%v]", b.name))
+	}
+	if b.config.MaxQueue < 0 {
+		return errors.New(fmt.Sprintf("invalid configuration: Bulkhead max queue is < 0 [%v]", b.name))
+	}
+	if b.config.QueueTimeout < 0 {
+		return errors.New(fmt.Sprintf("invalid configuration: Bulkhead queue timeout is < 0 [%v]", b.name))
+	}
+	return nil
+}
+
+func (b *bulkhead) IsEnabled() bool { return b.config.Enabled }
+
+func (b *bulkhead) IsNil() bool { return b.name == NilBehaviorName }
+
+func (b *bulkhead) Enable() {
+	if b.IsEnabled() {
+		return
+	}
+	b.enableBulkhead(true)
+}
+
+func (b *bulkhead) Disable() {
+	if !b.IsEnabled() {
+		return
+	}
+	b.enableBulkhead(false)
+}
+
+func (b *bulkhead) Signal(values url.Values) error {
+	if b.IsNil() {
+		return errors.New("invalid signal: bulkhead is not configured")
+	}
+	if values == nil {
+		return errors.New("invalid argument: values are nil for bulkhead signal")
+	}
+	UpdateEnable(b, values)
+	config := b.config
+	if values.Has(ConcurrencyKey) {
+		max, err := strconv.Atoi(values.Get(ConcurrencyKey))
+		if err != nil {
+			return err
+		}
+		if max <= 0 {
+			return errors.New(fmt.Sprintf("invalid argument: concurrency value is <= 0 [%v]", max))
+		}
+		config.MaxConcurrent = max
+	}
+	if values.Has(QueueKey) {
+		queue, err := strconv.Atoi(values.Get(QueueKey))
+		if err != nil {
+			return err
+		}
+		if queue < 0 {
+			return errors.New(fmt.Sprintf("invalid argument: queue value is < 0 [%v]", queue))
+		}
+		config.MaxQueue = queue
+	}
+	if values.Has(QueueTimeoutKey) {
+		duration, err := ParseDuration(values.Get(QueueTimeoutKey))
+		if err != nil {
+			return err
+		}
+		if duration < 0 {
+			return errors.New("invalid configuration: queue timeout duration is < 0")
+		}
+		config.QueueTimeout = duration
+	}
+	if config.MaxConcurrent != b.config.MaxConcurrent || config.MaxQueue != b.config.MaxQueue || config.QueueTimeout != b.config.QueueTimeout {
+		b.setBulkhead(config)
+	}
+	return nil
+}
+
+// Acquire - acquire a slot, waiting in the queue if configured, returns false if the request is rejected.
+// A successful Acquire must be paired with a call to Release on the same Bulkhead
+func (b *bulkhead) Acquire(ctx context.Context) bool {
+	acquired, queued := b.slots.tryAcquire(b.config.MaxQueue)
+	if !queued {
+		return acquired
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	var expired <-chan time.Time
+	if b.config.QueueTimeout > 0 {
+		timer := time.NewTimer(b.config.QueueTimeout)
+		defer timer.Stop()
+		expired = timer.C
+	}
+	return b.slots.wait(ctx, expired)
+}
+
+func (b *bulkhead) Release() {
+	b.slots.release()
+}
+
+func (b *bulkhead) StatusCode() int {
+	return b.config.StatusCode
+}
+
+func (b *bulkhead) MaxConcurrent() int {
+	return b.config.MaxConcurrent
+}
+
+func (b *bulkhead) MaxQueue() int {
+	return b.config.MaxQueue
+}
+
+func (b *bulkhead) QueueTimeout() time.Duration {
+	return b.config.QueueTimeout
+}
+
+func (b *bulkhead) InFlight() int {
+	return b.slots.count()
+}
+
+// syncSlots - set the slots to the configured maximum. The slots are shared by the clones of a bulkhead, so the
+// maximum holds across signals, overrides and rollbacks
+func (b *bulkhead) syncSlots() {
+	b.slots.resize(b.config.MaxConcurrent)
+}
+
+func (b *bulkhead) enableBulkhead(enabled bool) {
+	if b.table == nil || b.IsNil() {
+		return
+	}
+	b.table.mu.Lock()
+	defer b.table.mu.Unlock()
+	if ctrl, ok := b.table.controllers[b.name]; ok {
+		c := cloneBulkhead(ctrl.bulkhead)
+		c.config.Enabled = enabled
+		b.table.update(b.name, cloneController[*bulkhead](ctrl, c))
+	}
+}
+
+func (b *bulkhead) setBulkhead(config BulkheadConfig) {
+	if b.table == nil || b.IsNil() {
+		return
+	}
+	b.table.mu.Lock()
+	defer b.table.mu.Unlock()
+	if ctrl, ok := b.table.controllers[b.name]; ok {
+		c := cloneBulkhead(ctrl.bulkhead)
+		c.config.MaxConcurrent = config.MaxConcurrent
+		c.config.MaxQueue = config.MaxQueue
+		c.config.QueueTimeout = config.QueueTimeout
+		c.syncSlots()
+		b.table.update(b.name, cloneController[*bulkhead](ctrl, c))
+	}
+}
@@ -0,0 +1,132 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"time"
+)
+
+func Example_newBulkhead() {
+	b := newBulkhead("test-route", newTable(true, false), NewBulkheadConfig(true, 0, 10, 5, time.Millisecond*100))
+	fmt.Printf("test: newBulkhead() -> [name:%v] [max:%v] [queue:%v] [timeout:%v] [statusCode:%v]\n", b.name, b.MaxConcurrent(), b.MaxQueue(), b.QueueTimeout(), b.StatusCode())
+
+	b2 := cloneBulkhead(b)
+	b2.config.MaxConcurrent = 20
+	fmt.Printf("test: cloneBulkhead() -> [prev-max:%v] [curr-max:%v] [shared-slots:%v]\n", b.MaxConcurrent(), b2.MaxConcurrent(), b.slots == b2.slots)
+
+	fmt.Printf("test: validate() -> [%v]\n", newBulkhead("test-route", nil, NewBulkheadConfig(true, 0, 0, 0, 0)).validate())
+
+	//Output:
+	//test: newBulkhead() -> [name:test-route] [max:10] [queue:5] [timeout:100ms] [statusCode:503]
+	//test: cloneBulkhead() -> [prev-max:10] [curr-max:20] [shared-slots:true]
+	//test: validate() -> [invalid configuration: Bulkhead max concurrent is <= 0 [test-route]]
+
+}
+
+func ExampleBulkhead_Acquire() {
+	b := newBulkhead("test-route", nil, NewBulkheadConfig(true, 0, 2, 0, 0))
+
+	fmt.Printf("test: Acquire() -> [first:%v] [second:%v] [third:%v] [in-flight:%v]\n", b.Acquire(nil), b.Acquire(nil), b.Acquire(nil), b.InFlight())
+	b.Release()
+	fmt.Printf("test: Release() -> [in-flight:%v] [acquire:%v]\n", b.InFlight(), b.Acquire(nil))
+
+	//Output:
+	//test: Acquire() -> [first:true] [second:true] [third:false] [in-flight:2]
+	//test: Release() -> [in-flight:1] [acquire:true]
+
+}
+
+func ExampleBulkhead_Queue() {
+	b := newBulkhead("test-route", nil, NewBulkheadConfig(true, 0, 1, 1, time.Millisecond*50))
+	b.Acquire(nil)
+
+	start := time.Now()
+	ok := b.Acquire(nil)
+	fmt.Printf("test: Acquire(queue-timeout) -> [acquired:%v] [waited:%v]\n", ok, time.Since(start) >= time.Millisecond*50)
+
+	go func() {
+		time.Sleep(time.Millisecond * 10)
+		b.Release()
+	}()
+	fmt.Printf("test: Acquire(queue-release) -> [acquired:%v]\n", b.Acquire(nil))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	fmt.Printf("test: Acquire(context-done) -> [acquired:%v]\n", b.Acquire(ctx))
+
+	//Output:
+	//test: Acquire(queue-timeout) -> [acquired:false] [waited:true]
+	//test: Acquire(queue-release) -> [acquired:true]
+	//test: Acquire(context-done) -> [acquired:false]
+
+}
+
+func ExampleBulkhead_Signal() {
+	name := "test-route"
+	t := newTable(true, false)
+	errs := t.AddController(newRoute(name, NewBulkheadConfig(true, 0, 10, 0, 0)))
+	fmt.Printf("test: Add() -> [%v] [count:%v]\n", errs, t.count())
+
+	ctrl := t.LookupByName(name)
+	values := make(url.Values)
+	values.Add(BehaviorKey, BulkheadBehavior)
+	values.Add(ConcurrencyKey, "5")
+	values.Add(QueueKey, "2")
+	values.Add(QueueTimeoutKey, "250ms")
+	err := ctrl.Signal(values)
+	b := t.LookupByName(name).Bulkhead()
+	fmt.Printf("test: Signal(concurrency,queue,queue-timeout) -> [error:%v] [max:%v] [queue:%v] [timeout:%v]\n", err, b.MaxConcurrent(), b.MaxQueue(), b.QueueTimeout())
+
+	err = b.Signal(NewValues(ConcurrencyKey, "0"))
+	fmt.Printf("test: Signal(concurrency=0) -> [error:%v]\n", err)
+
+	b.Signal(enableValues(false))
+	fmt.Printf("test: Disable() -> [enabled:%v]\n", t.LookupByName(name).Bulkhead().IsEnabled())
+
+	err = t.LookupByName(name).Bulkhead().Signal(url.Values{EnabledKey: {TrueValue}, ConcurrencyKey: {"7"}})
+	b = t.LookupByName(name).Bulkhead()
+	fmt.Printf("test: Signal(enabled,concurrency) -> [error:%v] [enabled:%v] [max:%v]\n", err, b.IsEnabled(), b.MaxConcurrent())
+
+	//Output:
+	//test: Add() -> [[]] [count:1]
+	//test: Signal(concurrency,queue,queue-timeout) -> [error:<nil>] [max:5] [queue:2] [timeout:250ms]
+	//test: Signal(concurrency=0) -> [error:invalid argument: concurrency value is <= 0 [0]]
+	//test: Disable() -> [enabled:false]
+	//test: Signal(enabled,concurrency) -> [error:<nil>] [enabled:true] [max:7]
+
+}
+
+func ExampleBulkhead_Resize() {
+	name := "test-route"
+	t := newTable(true, false)
+	t.AddController(newRoute(name, NewBulkheadConfig(true, 0, 2, 1, 0)))
+	b := t.LookupByName(name).Bulkhead()
+	fmt.Printf("test: Acquire() -> [first:%v] [second:%v] [in-flight:%v]\n", b.Acquire(nil), b.Acquire(nil), b.InFlight())
+
+	err := t.LookupByName(name).Signal(url.Values{BehaviorKey: {BulkheadBehavior}, ConcurrencyKey: {"1"}})
+	b1 := t.LookupByName(name).Bulkhead()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	fmt.Printf("test: Signal(concurrency=1) -> [error:%v] [max:%v] [in-flight:%v] [acquire:%v]\n", err, b1.MaxConcurrent(), b1.InFlight(), b1.Acquire(ctx))
+
+	b.Release()
+	fmt.Printf("test: Release() -> [in-flight:%v] [acquire:%v]\n", b1.InFlight(), b1.Acquire(ctx))
+
+	b.Release()
+	fmt.Printf("test: Release() -> [in-flight:%v] [acquire:%v]\n", b1.InFlight(), b1.Acquire(ctx))
+
+	go func() {
+		time.Sleep(time.Millisecond * 10)
+		t.Rollback(name, "test")
+	}()
+	fmt.Printf("test: Acquire(queue-rollback) -> [acquired:%v] [max:%v] [in-flight:%v]\n", b1.Acquire(nil), t.LookupByName(name).Bulkhead().MaxConcurrent(), b1.InFlight())
+
+	//Output:
+	//test: Acquire() -> [first:true] [second:true] [in-flight:2]
+	//test: Signal(concurrency=1) -> [error:<nil>] [max:1] [in-flight:2] [acquire:false]
+	//test: Release() -> [in-flight:1] [acquire:false]
+	//test: Release() -> [in-flight:0] [acquire:true]
+	//test: Acquire(queue-rollback) -> [acquired:true] [max:2] [in-flight:2]
+
+}
@@ -0,0 +1,348 @@
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// https://martinfowler.com/bliki/CircuitBreaker.html
+
+const (
+	CircuitClosed   = "closed"
+	CircuitOpen     = "open"
+	CircuitHalfOpen = "half-open"
+
+	DefaultHalfOpenRequests = 1
+)
+
+// CircuitBreaker - interface for circuit breaking
+type CircuitBreaker interface {
+	State
+	Actuator
+	Allow() bool
+	Record(statusCode int, err error)
+	Release()
+	IsFailure(statusCode int) bool
+	StatusCode() int
+	CircuitState() string
+}
+
+type CircuitBreakerConfig struct {
+	Enabled             bool
+	StatusCode          int
+	FailureRatio        float64       // trip when failures/requests >= ratio, 0 disables the check
+	MinRequests         int           // minimum requests in the interval before the ratio is evaluated
+	ConsecutiveFailures int           // trip after this many consecutive failures, 0 disables the check
+	Interval            time.Duration // closed state counting window, 0 counts until the next trip
+	CoolDown            time.Duration // time spent open before allowing half-open probes
+	HalfOpenRequests    int           // probes allowed, and required to succeed, when half-open
+	StatusCodes         []int         // failure status codes, defaults to 5xx when empty
+}
+
+var nilCircuitBreaker = newCircuitBreaker(NilBehaviorName, nil, NewCircuitBreakerConfig(false, 0, 0, 0, 0, 0, nil))
+
+func NewCircuitBreakerConfig(enabled bool, statusCode int, failureRatio float64, consecutiveFailures int, interval, coolDown time.Duration, failureCodes []int) *CircuitBreakerConfig {
+	c := new(CircuitBreakerConfig)
+	if statusCode <= 0 {
+		statusCode = http.StatusServiceUnavailable
+	}
+	c.Enabled = enabled
+	c.StatusCode = statusCode
+	c.FailureRatio = failureRatio
+	c.ConsecutiveFailures = consecutiveFailures
+	c.Interval = interval
+	c.CoolDown = coolDown
+	c.HalfOpenRequests = DefaultHalfOpenRequests
+	c.StatusCodes = failureCodes
+	return c
+}
+
+// breakerCounts - state shared by all clones of a circuit breaker, so signals do not reset the circuit
+type breakerCounts struct {
+	mu          sync.Mutex
+	state       string
+	expiry      time.Time
+	requests    int
+	failures    int
+	consecutive int
+	probes      int
+	successes   int
+}
+
+type circuitBreaker struct {
+	name   string
+	table  *table
+	config CircuitBreakerConfig
+	counts *breakerCounts
+}
+
+func cloneCircuitBreaker(curr *circuitBreaker) *circuitBreaker {
+	t := new(circuitBreaker)
+	*t = *curr
+	return t
+}
+
+func newCircuitBreaker(name string, table *table, config *CircuitBreakerConfig) *circuitBreaker {
+	t := new(circuitBreaker)
+	t.name = name
+	t.table = table
+	if config != nil {
+		t.config = *config
+	}
+	if t.config.HalfOpenRequests <= 0 {
+		t.config.HalfOpenRequests = DefaultHalfOpenRequests
+	}
+	t.counts = new(breakerCounts)
+	t.transition(CircuitClosed, time.Now())
+	return t
+}
+
+func (c *circuitBreaker) validate() error {
+	if c.config.FailureRatio < 0 || c.config.FailureRatio > 1 {
+		return errors.New(fmt.Sprintf("invalid configuration: CircuitBreaker failure ratio is not in the range 0..1 [%v]", c.name))
+	}
+	if c.config.FailureRatio == 0 && c.config.ConsecutiveFailures <= 0 {
+		return errors.New(fmt.Sprintf("invalid configuration: CircuitBreaker failure ratio and consecutive failures are not configured [%v]", c.name))
+	}
+	if c.config.MinRequests < 0 {
+		return errors.New(fmt.Sprintf("invalid configuration: CircuitBreaker minimum requests is < 0 [%v]", c.name))
+	}
+	if c.config.Interval < 0 {
+		return errors.New(fmt.Sprintf("invalid configuration: CircuitBreaker interval is < 0 [%v]", c.name))
+	}
+	if c.config.CoolDown <= 0 {
+		return errors.New(fmt.Sprintf("invalid configuration: CircuitBreaker cool down is <= 0 [%v]", c.name))
+	}
+	return nil
+}
+
+func (c *circuitBreaker) IsEnabled() bool { return c.config.Enabled }
+
+func (c *circuitBreaker) IsNil() bool { return c.name == NilBehaviorName }
+
+func (c *circuitBreaker) Enable() {
+	if c.IsEnabled() {
+		return
+	}
+	c.enableCircuitBreaker(true)
+}
+
+func (c *circuitBreaker) Disable() {
+	if !c.IsEnabled() {
+		return
+	}
+	c.enableCircuitBreaker(false)
+}
+
+func (c *circuitBreaker) Signal(values url.Values) error {
+	if c.IsNil() {
+		return errors.New("invalid signal: circuit breaker is not configured")
+	}
+	if values == nil {
+		return errors.New("invalid argument: values are nil for circuit breaker signal")
+	}
+	UpdateEnable(c, values)
+	config := c.config
+	if values.Has(RatioKey) {
+		ratio, err := strconv.ParseFloat(values.Get(RatioKey), 64)
+		if err != nil {
+			return err
+		}
+		if ratio < 0 || ratio > 1 {
+			return errors.New(fmt.Sprintf("invalid argument: ratio value is not in the range 0..1 [%v]", ratio))
+		}
+		config.FailureRatio = ratio
+	}
+	if values.Has(FailuresKey) {
+		failures, err := strconv.Atoi(values.Get(FailuresKey))
+		if err != nil {
+			return err
+		}
+		if failures < 0 {
+			return errors.New(fmt.Sprintf("invalid argument: failures value is < 0 [%v]", failures))
+		}
+		config.ConsecutiveFailures = failures
+	}
+	if values.Has(CoolDownKey) {
+		duration, err := ParseDuration(values.Get(CoolDownKey))
+		if err != nil {
+			return err
+		}
+		if duration <= 0 {
+			return errors.New("invalid configuration: cool down duration is <= 0")
+		}
+		config.CoolDown = duration
+	}
+	if config.FailureRatio != c.config.FailureRatio || config.ConsecutiveFailures != c.config.ConsecutiveFailures || config.CoolDown != c.config.CoolDown {
+		if config.FailureRatio == 0 && config.ConsecutiveFailures == 0 {
+			return errors.New("invalid configuration: circuit breaker failure ratio and consecutive failures are both 0")
+		}
+		c.setConfig(config)
+	}
+	if values.Has(StateKey) {
+		switch s := values.Get(StateKey); s {
+		case CircuitClosed, CircuitOpen:
+			c.counts.mu.Lock()
+			c.transition(s, time.Now())
+			c.counts.mu.Unlock()
+		default:
+			return errors.New(fmt.Sprintf("invalid argument: circuit breaker state is invalid [%v]", s))
+		}
+	}
+	return nil
+}
+
+// Allow - determine if a request can proceed, an open circuit short-circuits requests until the cool down
+// has elapsed, after which a limited number of half-open probes are allowed
+func (c *circuitBreaker) Allow() bool {
+	now := time.Now()
+	c.counts.mu.Lock()
+	defer c.counts.mu.Unlock()
+	switch c.counts.state {
+	case CircuitOpen:
+		if now.Before(c.counts.expiry) {
+			return false
+		}
+		c.transition(CircuitHalfOpen, now)
+		fallthrough
+	case CircuitHalfOpen:
+		if c.counts.probes >= c.config.HalfOpenRequests {
+			return false
+		}
+		c.counts.probes++
+		return true
+	default:
+		if c.config.Interval > 0 && !c.counts.expiry.IsZero() && !now.Before(c.counts.expiry) {
+			c.transition(CircuitClosed, now)
+		}
+		return true
+	}
+}
+
+// Record - record the outcome of an allowed request, a non nil error is always a failure
+func (c *circuitBreaker) Record(statusCode int, err error) {
+	failure := err != nil || c.IsFailure(statusCode)
+	now := time.Now()
+	c.counts.mu.Lock()
+	defer c.counts.mu.Unlock()
+	switch c.counts.state {
+	case CircuitHalfOpen:
+		if failure {
+			c.transition(CircuitOpen, now)
+			return
+		}
+		c.counts.successes++
+		if c.counts.successes >= c.config.HalfOpenRequests {
+			c.transition(CircuitClosed, now)
+		}
+	case CircuitClosed:
+		c.counts.requests++
+		if !failure {
+			c.counts.consecutive = 0
+			return
+		}
+		c.counts.failures++
+		c.counts.consecutive++
+		if c.tripped() {
+			c.transition(CircuitOpen, now)
+		}
+	}
+}
+
+// Release - release the half-open probe of an allowed request that was not sent, the outcome is not recorded
+func (c *circuitBreaker) Release() {
+	c.counts.mu.Lock()
+	defer c.counts.mu.Unlock()
+	if c.counts.state == CircuitHalfOpen && c.counts.probes > 0 {
+		c.counts.probes--
+	}
+}
+
+func (c *circuitBreaker) tripped() bool {
+	if c.config.ConsecutiveFailures > 0 && c.counts.consecutive >= c.config.ConsecutiveFailures {
+		return true
+	}
+	if c.config.FailureRatio > 0 && c.counts.requests >= c.config.MinRequests {
+		return float64(c.counts.failures)/float64(c.counts.requests) >= c.config.FailureRatio
+	}
+	return false
+}
+
+// transition - change circuit state and reset counts, caller must hold the counts lock
+func (c *circuitBreaker) transition(state string, now time.Time) {
+	c.counts.state = state
+	c.counts.requests = 0
+	c.counts.failures = 0
+	c.counts.consecutive = 0
+	c.counts.probes = 0
+	c.counts.successes = 0
+	switch state {
+	case CircuitOpen:
+		c.counts.expiry = now.Add(c.config.CoolDown)
+	case CircuitClosed:
+		c.counts.expiry = time.Time{}
+		if c.config.Interval > 0 {
+			c.counts.expiry = now.Add(c.config.Interval)
+		}
+	default:
+		c.counts.expiry = time.Time{}
+	}
+}
+
+func (c *circuitBreaker) IsFailure(statusCode int) bool {
+	if len(c.config.StatusCodes) == 0 {
+		return statusCode >= http.StatusInternalServerError
+	}
+	for _, code := range c.config.StatusCodes {
+		if code == statusCode {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *circuitBreaker) StatusCode() int {
+	return c.config.StatusCode
+}
+
+func (c *circuitBreaker) CircuitState() string {
+	c.counts.mu.Lock()
+	defer c.counts.mu.Unlock()
+	if c.counts.state == CircuitOpen && !time.Now().Before(c.counts.expiry) {
+		return CircuitHalfOpen
+	}
+	return c.counts.state
+}
+
+func (c *circuitBreaker) enableCircuitBreaker(enabled bool) {
+	if c.table == nil || c.IsNil() {
+		return
+	}
+	c.table.mu.Lock()
+	defer c.table.mu.Unlock()
+	if ctrl, ok := c.table.controllers[c.name]; ok {
+		cb := cloneCircuitBreaker(ctrl.circuitBreaker)
+		cb.config.Enabled = enabled
+		c.table.update(c.name, cloneController[*circuitBreaker](ctrl, cb))
+	}
+}
+
+func (c *circuitBreaker) setConfig(config CircuitBreakerConfig) {
+	if c.table == nil || c.IsNil() {
+		return
+	}
+	c.table.mu.Lock()
+	defer c.table.mu.Unlock()
+	if ctrl, ok := c.table.controllers[c.name]; ok {
+		cb := cloneCircuitBreaker(ctrl.circuitBreaker)
+		cb.config.FailureRatio = config.FailureRatio
+		cb.config.ConsecutiveFailures = config.ConsecutiveFailures
+		cb.config.CoolDown = config.CoolDown
+		c.table.update(c.name, cloneController[*circuitBreaker](ctrl, cb))
+	}
+}
@@ -0,0 +1,143 @@
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+)
+
+func Example_newCircuitBreaker() {
+	cb := newCircuitBreaker("test-route", newTable(true, false), NewCircuitBreakerConfig(true, 0, 0.5, 3, 0, time.Second, nil))
+	fmt.Printf("test: newCircuitBreaker() -> [name:%v] [ratio:%v] [failures:%v] [cool-down:%v] [statusCode:%v] [state:%v]\n", cb.name, cb.config.FailureRatio, cb.config.ConsecutiveFailures, cb.config.CoolDown, cb.StatusCode(), cb.CircuitState())
+
+	cb2 := cloneCircuitBreaker(cb)
+	cb2.config.ConsecutiveFailures = 10
+	fmt.Printf("test: cloneCircuitBreaker() -> [prev-failures:%v] [curr-failures:%v] [shared-counts:%v]\n", cb.config.ConsecutiveFailures, cb2.config.ConsecutiveFailures, cb.counts == cb2.counts)
+
+	fmt.Printf("test: validate() -> [%v]\n", newCircuitBreaker("test-route", nil, NewCircuitBreakerConfig(true, 0, 0, 0, 0, time.Second, nil)).validate())
+	fmt.Printf("test: validate() -> [%v]\n", newCircuitBreaker("test-route", nil, NewCircuitBreakerConfig(true, 0, 0.5, 0, 0, 0, nil)).validate())
+
+	//Output:
+	//test: newCircuitBreaker() -> [name:test-route] [ratio:0.5] [failures:3] [cool-down:1s] [statusCode:503] [state:closed]
+	//test: cloneCircuitBreaker() -> [prev-failures:3] [curr-failures:10] [shared-counts:true]
+	//test: validate() -> [invalid configuration: CircuitBreaker failure ratio and consecutive failures are not configured [test-route]]
+	//test: validate() -> [invalid configuration: CircuitBreaker cool down is <= 0 [test-route]]
+
+}
+
+func ExampleCircuitBreaker_ConsecutiveFailures() {
+	cb := newCircuitBreaker("test-route", nil, NewCircuitBreakerConfig(true, 0, 0, 2, 0, time.Millisecond*100, []int{503, 504}))
+
+	cb.Record(503, nil)
+	fmt.Printf("test: Record(503) -> [state:%v] [allow:%v]\n", cb.CircuitState(), cb.Allow())
+
+	cb.Record(500, nil)
+	fmt.Printf("test: Record(500) -> [state:%v] [allow:%v]\n", cb.CircuitState(), cb.Allow())
+
+	cb.Record(0, errors.New("connection refused"))
+	fmt.Printf("test: Record(error) -> [state:%v] [allow:%v]\n", cb.CircuitState(), cb.Allow())
+
+	cb.Record(504, nil)
+	fmt.Printf("test: Record(504) -> [state:%v] [allow:%v]\n", cb.CircuitState(), cb.Allow())
+
+	time.Sleep(time.Millisecond * 150)
+	fmt.Printf("test: CircuitState() -> [state:%v]\n", cb.CircuitState())
+	fmt.Printf("test: Allow() -> [probe:%v] [next:%v]\n", cb.Allow(), cb.Allow())
+
+	cb.Record(504, nil)
+	fmt.Printf("test: Record(504) -> [state:%v] [allow:%v]\n", cb.CircuitState(), cb.Allow())
+
+	time.Sleep(time.Millisecond * 150)
+	fmt.Printf("test: Allow() -> [probe:%v]\n", cb.Allow())
+	cb.Record(200, nil)
+	fmt.Printf("test: Record(200) -> [state:%v] [allow:%v]\n", cb.CircuitState(), cb.Allow())
+
+	//Output:
+	//test: Record(503) -> [state:closed] [allow:true]
+	//test: Record(500) -> [state:closed] [allow:true]
+	//test: Record(error) -> [state:closed] [allow:true]
+	//test: Record(504) -> [state:open] [allow:false]
+	//test: CircuitState() -> [state:half-open]
+	//test: Allow() -> [probe:true] [next:false]
+	//test: Record(504) -> [state:open] [allow:false]
+	//test: Allow() -> [probe:true]
+	//test: Record(200) -> [state:closed] [allow:true]
+
+}
+
+func ExampleCircuitBreaker_Release() {
+	cb := newCircuitBreaker("test-route", nil, NewCircuitBreakerConfig(true, 0, 0, 1, 0, time.Millisecond*50, nil))
+	cb.Record(500, nil)
+	time.Sleep(time.Millisecond * 60)
+	fmt.Printf("test: Allow() -> [probe:%v] [next:%v]\n", cb.Allow(), cb.Allow())
+
+	cb.Release()
+	fmt.Printf("test: Release() -> [state:%v] [probe:%v]\n", cb.CircuitState(), cb.Allow())
+
+	//Output:
+	//test: Allow() -> [probe:true] [next:false]
+	//test: Release() -> [state:half-open] [probe:true]
+
+}
+
+func ExampleCircuitBreaker_FailureRatio() {
+	config := NewCircuitBreakerConfig(true, 0, 0.5, 0, 0, time.Second, nil)
+	config.MinRequests = 4
+	cb := newCircuitBreaker("test-route", nil, config)
+
+	for _, code := range []int{200, 200, 500} {
+		cb.Record(code, nil)
+	}
+	fmt.Printf("test: Record(200,200,500) -> [state:%v]\n", cb.CircuitState())
+
+	cb.Record(500, nil)
+	fmt.Printf("test: Record(500) -> [state:%v]\n", cb.CircuitState())
+
+	//Output:
+	//test: Record(200,200,500) -> [state:closed]
+	//test: Record(500) -> [state:open]
+
+}
+
+func ExampleCircuitBreaker_Signal() {
+	name := "test-route"
+	t := newTable(true, false)
+	errs := t.AddController(newRoute(name, NewCircuitBreakerConfig(true, 0, 0, 5, 0, time.Second, nil)))
+	fmt.Printf("test: Add() -> [%v] [count:%v]\n", errs, t.count())
+
+	ctrl := t.LookupByName(name)
+	values := make(url.Values)
+	values.Add(BehaviorKey, CircuitBreakerBehavior)
+	values.Add(FailuresKey, "10")
+	values.Add(CoolDownKey, "500ms")
+	err := ctrl.Signal(values)
+	cb := t.LookupByName(name).t().circuitBreaker
+	fmt.Printf("test: Signal(failures,cool-down) -> [error:%v] [failures:%v] [cool-down:%v]\n", err, cb.config.ConsecutiveFailures, cb.config.CoolDown)
+
+	err = ctrl.CircuitBreaker().Signal(NewValues(StateKey, CircuitOpen))
+	fmt.Printf("test: Signal(state=open) -> [error:%v] [state:%v] [allow:%v]\n", err, t.LookupByName(name).CircuitBreaker().CircuitState(), t.LookupByName(name).CircuitBreaker().Allow())
+
+	err = ctrl.CircuitBreaker().Signal(NewValues(StateKey, CircuitClosed))
+	fmt.Printf("test: Signal(state=closed) -> [error:%v] [state:%v]\n", err, t.LookupByName(name).CircuitBreaker().CircuitState())
+
+	err = ctrl.CircuitBreaker().Signal(NewValues(RatioKey, "1.5"))
+	fmt.Printf("test: Signal(ratio=1.5) -> [error:%v]\n", err)
+
+	ctrl.CircuitBreaker().Signal(enableValues(false))
+	fmt.Printf("test: Disable() -> [enabled:%v]\n", t.LookupByName(name).CircuitBreaker().IsEnabled())
+
+	err = t.LookupByName(name).CircuitBreaker().Signal(url.Values{EnabledKey: {TrueValue}, FailuresKey: {"7"}})
+	cb = t.LookupByName(name).t().circuitBreaker
+	fmt.Printf("test: Signal(enabled,failures) -> [error:%v] [enabled:%v] [failures:%v]\n", err, cb.IsEnabled(), cb.config.ConsecutiveFailures)
+
+	//Output:
+	//test: Add() -> [[]] [count:1]
+	//test: Signal(failures,cool-down) -> [error:<nil>] [failures:10] [cool-down:500ms]
+	//test: Signal(state=open) -> [error:<nil>] [state:open] [allow:false]
+	//test: Signal(state=closed) -> [error:<nil>] [state:closed]
+	//test: Signal(ratio=1.5) -> [error:invalid argument: ratio value is not in the range 0..1 [1.5]]
+	//test: Disable() -> [enabled:false]
+	//test: Signal(enabled,failures) -> [error:<nil>] [enabled:true] [failures:7]
+
+}
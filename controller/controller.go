@@ -5,8 +5,10 @@ import (
 	"fmt"
 	"github.com/google/uuid"
 	"golang.org/x/time/rate"
+	"google.golang.org/grpc/codes"
 	"net/http"
 	"net/url"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -22,8 +24,18 @@ const (
 
 	RateLimitFlag       = "RL"
 	UpstreamTimeoutFlag = "UT"
+	UpstreamFailureFlag = "UF"
+	UpstreamResetFlag   = "UC"
 	RetryFlag           = "RT"
 	RetryRateLimitFlag  = "RT-RL"
+	CircuitBreakerFlag  = "CB"
+	BulkheadFlag        = "BH"
+	RetryCancelledFlag  = "RT-CX"
+	RetryReplayFlag     = "RT-NR"
+	HedgeFlag           = "HG"
+	HedgeCancelledFlag  = "HG-CX"
+	MirrorFlag          = "MR"
+	MirrorNotSentFlag   = "MR-NS"
 )
 
 // State - defines enabled state
@@ -38,28 +50,45 @@ type State interface {
 type Controller interface {
 	Actuator
 	Name() string
+	Priority() string
 	Timeout() Timeout
 	RateLimiter() RateLimiter
 	Retry() Retry
 	Proxy() Proxy
+	CircuitBreaker() CircuitBreaker
+	Bulkhead() Bulkhead
+	Hedge() Hedge
+	Mirror() Mirror
+	Pool() Pool
+	HealthCheck() HealthCheck
+	Snapshot() RouteConfig
 	UpdateHeaders(req *http.Request)
 	LogHttpIngress(start time.Time, duration time.Duration, req *http.Request, statusCode int, written int64, statusFlags string)
-	LogHttpEgress(start time.Time, duration time.Duration, req *http.Request, resp *http.Response, retry bool, statusFlags string)
+	LogGRPCIngress(start time.Time, duration time.Duration, req *http.Request, code codes.Code, statusFlags string)
+	LogHttpEgress(start time.Time, duration time.Duration, req *http.Request, resp *http.Response, attempt int, statusFlags string)
 	LogEgress(start time.Time, duration time.Duration, statusCode int, uri, requestId, method, statusFlags string)
 	t() *controller
 }
 
 type controller struct {
-	name        string
-	ping        bool
-	tbl         *table
-	timeout     *timeout
-	rateLimiter *rateLimiter
-	retry       *retry
-	proxy       *proxy
+	name           string
+	ping           bool
+	priority       string
+	route          Route
+	tbl            *table
+	timeout        *timeout
+	rateLimiter    *rateLimiter
+	retry          *retry
+	proxy          *proxy
+	circuitBreaker *circuitBreaker
+	bulkhead       *bulkhead
+	hedge          *hedge
+	mirror         *mirror
+	pool           *pool
+	healthCheck    *healthCheck
 }
 
-func cloneController[T *timeout | *rateLimiter | *retry | *proxy](curr *controller, item T) *controller {
+func cloneController[T *timeout | *rateLimiter | *retry | *proxy | *circuitBreaker | *bulkhead | *hedge | *mirror | *pool | *healthCheck](curr *controller, item T) *controller {
 	newC := new(controller)
 	*newC = *curr
 	switch i := any(item).(type) {
@@ -71,6 +100,18 @@ func cloneController[T *timeout | *rateLimiter | *retry | *proxy](curr *controll
 		newC.proxy = i
 	case *retry:
 		newC.retry = i
+	case *circuitBreaker:
+		newC.circuitBreaker = i
+	case *bulkhead:
+		newC.bulkhead = i
+	case *hedge:
+		newC.hedge = i
+	case *mirror:
+		newC.mirror = i
+	case *pool:
+		newC.pool = i
+	case *healthCheck:
+		newC.healthCheck = i
 	default:
 	}
 	return newC
@@ -81,7 +122,12 @@ func newController(route Route, t *table) (*controller, []error) {
 	var err error
 	ctrl := newDefaultController(route.Name)
 	ctrl.ping = route.Ping
+	ctrl.priority = route.Priority
+	ctrl.route = route
 	ctrl.tbl = t
+	if route.Priority != "" && !IsPriority(route.Priority) {
+		errs = append(errs, errors.New(fmt.Sprintf("invalid configuration: priority is invalid [%v] [%v]", route.Priority, route.Name)))
+	}
 	if route.Timeout != nil {
 		ctrl.timeout = newTimeout(route.Name, t, route.Timeout)
 		err = ctrl.timeout.validate()
@@ -110,6 +156,48 @@ func newController(route Route, t *table) (*controller, []error) {
 			errs = append(errs, err)
 		}
 	}
+	if route.CircuitBreaker != nil {
+		ctrl.circuitBreaker = newCircuitBreaker(route.Name, t, route.CircuitBreaker)
+		err = ctrl.circuitBreaker.validate()
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if route.Bulkhead != nil {
+		ctrl.bulkhead = newBulkhead(route.Name, t, route.Bulkhead)
+		err = ctrl.bulkhead.validate()
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if route.Hedge != nil {
+		ctrl.hedge = newHedge(route.Name, t, route.Hedge)
+		err = ctrl.hedge.validate()
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if route.Mirror != nil {
+		ctrl.mirror = newMirror(route.Name, t, route.Mirror)
+		err = ctrl.mirror.validate()
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if route.Pool != nil {
+		ctrl.pool = newPool(route.Name, t, route.Pool)
+		err = ctrl.pool.validate()
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if route.HealthCheck != nil {
+		ctrl.healthCheck = newHealthCheck(route.Name, t, route.HealthCheck)
+		err = ctrl.healthCheck.validate()
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
 	return ctrl, errs
 }
 
@@ -120,14 +208,39 @@ func newDefaultController(name string) *controller {
 	ctrl.proxy = nilProxy
 	ctrl.rateLimiter = nilRateLimiter
 	ctrl.retry = nilRetry
+	ctrl.circuitBreaker = nilCircuitBreaker
+	ctrl.bulkhead = nilBulkhead
+	ctrl.hedge = nilHedge
+	ctrl.mirror = nilMirror
+	ctrl.pool = nilPool
+	ctrl.healthCheck = nilHealthCheck
 	return ctrl
 }
 
 func (c *controller) validate(egress bool) error {
+	// An adaptive limit requires requests to Acquire and Release a permit, which only the ingress host controller does
+	if egress && c.rateLimiter.IsAdaptive() {
+		return errors.New("invalid configuration: RateLimiter adaptive limits are not valid for egress traffic")
+	}
 	if !egress {
 		if c.retry.IsEnabled() {
 			return errors.New("invalid configuration: Retry is not valid for ingress traffic")
 		}
+		if c.circuitBreaker.IsEnabled() {
+			return errors.New("invalid configuration: CircuitBreaker is not valid for ingress traffic")
+		}
+		if c.hedge.IsEnabled() {
+			return errors.New("invalid configuration: Hedge is not valid for ingress traffic")
+		}
+		if c.mirror.IsEnabled() {
+			return errors.New("invalid configuration: Mirror is not valid for ingress traffic")
+		}
+		if c.pool.IsEnabled() {
+			return errors.New("invalid configuration: Pool is not valid for ingress traffic")
+		}
+		if c.healthCheck.IsEnabled() {
+			return errors.New("invalid configuration: HealthCheck is not valid for ingress traffic")
+		}
 		if c.name == HostControllerName {
 			if c.timeout.IsEnabled() {
 				return errors.New("invalid configuration: Timeout is not valid for host controller")
@@ -161,10 +274,51 @@ func (c *controller) Proxy() Proxy {
 	return c.proxy
 }
 
+func (c *controller) CircuitBreaker() CircuitBreaker {
+	return c.circuitBreaker
+}
+
+func (c *controller) Bulkhead() Bulkhead {
+	return c.bulkhead
+}
+
+func (c *controller) Hedge() Hedge {
+	return c.hedge
+}
+
+func (c *controller) Mirror() Mirror {
+	return c.mirror
+}
+
+func (c *controller) Pool() Pool {
+	return c.pool
+}
+
+func (c *controller) HealthCheck() HealthCheck {
+	return c.healthCheck
+}
+
+// Signal - signal a behavior, changes to a route controller are recorded in the table history, and changes with a
+// ttl are reverted when the ttl expires
 func (c *controller) Signal(values url.Values) error {
 	if values == nil {
 		return nil
 	}
+	ttl, err := ParseTTL(values)
+	if err != nil {
+		return err
+	}
+	if c.tbl != nil {
+		c.tbl.signalMu.Lock()
+		defer c.tbl.signalMu.Unlock()
+		if prev := c.tbl.current(c.name); prev != nil {
+			defer c.tbl.record(prev, values.Get(BehaviorKey), values.Get(CallerKey), ttl)
+		}
+	}
+	return c.signal(values)
+}
+
+func (c *controller) signal(values url.Values) error {
 	switch values.Get(BehaviorKey) {
 	case TimeoutBehavior:
 		return c.Timeout().Signal(values)
@@ -178,6 +332,18 @@ func (c *controller) Signal(values url.Values) error {
 	case ProxyBehavior:
 		return c.Proxy().Signal(values)
 		break
+	case CircuitBreakerBehavior:
+		return c.CircuitBreaker().Signal(values)
+	case BulkheadBehavior:
+		return c.Bulkhead().Signal(values)
+	case HedgeBehavior:
+		return c.Hedge().Signal(values)
+	case MirrorBehavior:
+		return c.Mirror().Signal(values)
+	case PoolBehavior:
+		return c.Pool().Signal(values)
+	case HealthCheckBehavior:
+		return c.HealthCheck().Signal(values)
 	}
 	return errors.New(fmt.Sprintf("invalid argument: behavior [%s] is not supported", values.Get(BehaviorKey)))
 }
@@ -197,25 +363,31 @@ func (c *controller) UpdateHeaders(req *http.Request) {
 }
 
 func (c *controller) LogHttpIngress(start time.Time, duration time.Duration, req *http.Request, statusCode int, written int64, statusFlags string) {
-	if c.name == NilControllerName {
-		return
-	}
 	resp := new(http.Response)
 	resp.StatusCode = statusCode
 	resp.ContentLength = written
+	c.logIngress(start, duration, req, resp, statusFlags)
+}
+
+func (c *controller) logIngress(start time.Time, duration time.Duration, req *http.Request, resp *http.Response, statusFlags string) {
+	if c.name == NilControllerName {
+		return
+	}
 	traffic := IngressTraffic
 	if c.ping {
 		traffic = PingTraffic
 	}
+	priority := c.requestPriority(req)
+	key := rateLimiterKey(c.rateLimiter, req, statusFlags)
 	limit, burst, threshold := rateLimiterState(c.rateLimiter)
-	proxyValid, proxyThreshold := proxyState(c.proxy)
+	proxyValid, proxyThreshold := proxyState(c.proxy, req)
 	if defaultExtractFn != nil {
-		defaultExtractFn(traffic, start, duration, req, resp, c.Name(), timeoutState(c.timeout), limit, burst, threshold, "", proxyValid, proxyThreshold, statusFlags)
+		defaultExtractFn(traffic, start, duration, req, resp, c.Name(), priority, timeoutState(c.timeout), limit, burst, threshold, key, "", -1, proxyValid, proxyThreshold, statusFlags)
 	}
-	defaultLogFn(traffic, start, duration, req, resp, c.Name(), timeoutState(c.timeout), limit, burst, threshold, "", proxyValid, proxyThreshold, statusFlags)
+	defaultLogFn(traffic, start, duration, req, resp, c.Name(), priority, timeoutState(c.timeout), limit, burst, threshold, key, "", -1, proxyValid, proxyThreshold, statusFlags)
 }
 
-func (c *controller) LogHttpEgress(start time.Time, duration time.Duration, req *http.Request, resp *http.Response, retry bool, statusFlags string) {
+func (c *controller) LogHttpEgress(start time.Time, duration time.Duration, req *http.Request, resp *http.Response, attempt int, statusFlags string) {
 	if c.name == NilControllerName {
 		return
 	}
@@ -223,6 +395,9 @@ func (c *controller) LogHttpEgress(start time.Time, duration time.Duration, req
 	var burst int
 	var threshold string
 	var retryStr = ""
+	var retry = attempt > 1
+	var priority = ""
+	var key = rateLimiterKey(c.rateLimiter, req, statusFlags)
 
 	if c.retry.IsEnabled() {
 		if retry {
@@ -236,23 +411,28 @@ func (c *controller) LogHttpEgress(start time.Time, duration time.Duration, req
 	} else {
 		limit, burst, threshold = rateLimiterState(c.rateLimiter)
 	}
-	proxyValid, proxyThreshold := proxyState(c.proxy)
+	proxyValid, proxyThreshold := proxyState(c.proxy, req)
 	if defaultExtractFn != nil {
-		defaultExtractFn(EgressTraffic, start, duration, req, resp, c.Name(), timeoutState(c.timeout), limit, burst, threshold, retryStr, proxyValid, proxyThreshold, statusFlags)
+		defaultExtractFn(EgressTraffic, start, duration, req, resp, c.Name(), priority, timeoutState(c.timeout), limit, burst, threshold, key, retryStr, attempt, proxyValid, proxyThreshold, statusFlags)
 	}
-	defaultLogFn(EgressTraffic, start, duration, req, resp, c.Name(), timeoutState(c.timeout), limit, burst, threshold, retryStr, proxyValid, proxyThreshold, statusFlags)
+	defaultLogFn(EgressTraffic, start, duration, req, resp, c.Name(), priority, timeoutState(c.timeout), limit, burst, threshold, key, retryStr, attempt, proxyValid, proxyThreshold, statusFlags)
 }
 
 func (c *controller) LogEgress(start time.Time, duration time.Duration, statusCode int, uri, requestId, method, statusFlags string) {
 	req, _ := http.NewRequest(method, uri, nil)
 	req.Header.Add(RequestIdHeaderName, requestId)
 
+	// Apply status codes are gRPC status codes
 	resp := new(http.Response)
 	resp.StatusCode = statusCode
+	resp.Trailer = make(http.Header)
+	resp.Trailer.Set(GRPCStatusHeaderName, strconv.Itoa(statusCode))
+	priority := ""
+	key := rateLimiterKey(c.rateLimiter, req, statusFlags)
 	limit, burst, threshold := rateLimiterState(c.rateLimiter)
-	proxyValid, proxyThreshold := proxyState(c.proxy)
+	proxyValid, proxyThreshold := proxyState(c.proxy, req)
 	if defaultExtractFn != nil {
-		defaultExtractFn(EgressTraffic, start, duration, req, resp, c.Name(), timeoutState(c.timeout), limit, burst, threshold, "", proxyValid, proxyThreshold, statusFlags)
+		defaultExtractFn(EgressTraffic, start, duration, req, resp, c.Name(), priority, timeoutState(c.timeout), limit, burst, threshold, key, "", -1, proxyValid, proxyThreshold, statusFlags)
 	}
-	defaultLogFn(EgressTraffic, start, duration, req, resp, c.Name(), timeoutState(c.timeout), limit, burst, threshold, "", proxyValid, proxyThreshold, statusFlags)
+	defaultLogFn(EgressTraffic, start, duration, req, resp, c.Name(), priority, timeoutState(c.timeout), limit, burst, threshold, key, "", -1, proxyValid, proxyThreshold, statusFlags)
 }
@@ -0,0 +1,119 @@
+package controller
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// https://grpc.github.io/grpc/core/md_doc__p_r_o_t_o_c_o_l-_h_t_t_p2.html
+
+const (
+	RequestTimeoutHeaderName = "X-Request-Timeout" // remaining budget in milliseconds
+	GrpcTimeoutHeaderName    = "Grpc-Timeout"      // remaining budget as 1 to 8 digits and a unit : H M S m u n
+
+	DeadlineExceededFlag = "DX"
+)
+
+// RequestTimeout - the remaining budget of a request, from the request timeout header, or the gRPC timeout
+// header. A budget <= 0 has expired
+func RequestTimeout(h http.Header) (time.Duration, bool) {
+	if h == nil {
+		return 0, false
+	}
+	if v := h.Get(RequestTimeoutHeaderName); v != "" {
+		ms, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return 0, false
+		}
+		return time.Duration(ms) * time.Millisecond, true
+	}
+	if v := h.Get(GrpcTimeoutHeaderName); v != "" {
+		return parseGrpcTimeout(v)
+	}
+	return 0, false
+}
+
+func parseGrpcTimeout(v string) (time.Duration, bool) {
+	if len(v) < 2 || len(v) > 9 {
+		return 0, false
+	}
+	val, err := strconv.ParseInt(v[:len(v)-1], 10, 64)
+	if err != nil || val < 0 {
+		return 0, false
+	}
+	var unit time.Duration
+	switch v[len(v)-1] {
+	case 'H':
+		unit = time.Hour
+	case 'M':
+		unit = time.Minute
+	case 'S':
+		unit = time.Second
+	case 'm':
+		unit = time.Millisecond
+	case 'u':
+		unit = time.Microsecond
+	case 'n':
+		unit = time.Nanosecond
+	default:
+		return 0, false
+	}
+	return time.Duration(val) * unit, true
+}
+
+// SetRequestTimeout - write the remaining budget to the request timeout header, the gRPC timeout header
+// is also updated if present
+func SetRequestTimeout(h http.Header, budget time.Duration) {
+	if h == nil {
+		return
+	}
+	if budget < 0 {
+		budget = 0
+	}
+	h.Set(RequestTimeoutHeaderName, strconv.FormatInt(budget.Milliseconds(), 10))
+	if h.Get(GrpcTimeoutHeaderName) != "" {
+		h.Set(GrpcTimeoutHeaderName, formatGrpcTimeout(budget))
+	}
+}
+
+// formatGrpcTimeout - format a budget in the smallest unit, starting with milliseconds, that fits the 8 digits
+// allowed by the gRPC timeout header. Budgets larger than the maximum hours are capped
+func formatGrpcTimeout(budget time.Duration) string {
+	const maxValue = 99999999
+	for _, u := range []struct {
+		unit   time.Duration
+		suffix string
+	}{{time.Millisecond, "m"}, {time.Second, "S"}, {time.Minute, "M"}, {time.Hour, "H"}} {
+		if v := budget / u.unit; v <= maxValue {
+			return strconv.FormatInt(int64(v), 10) + u.suffix
+		}
+	}
+	return strconv.FormatInt(maxValue, 10) + "H"
+}
+
+// RemainingBudget - the time remaining until the context deadline
+func RemainingBudget(ctx context.Context) (time.Duration, bool) {
+	if ctx == nil {
+		return 0, false
+	}
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return 0, false
+	}
+	return time.Until(deadline), true
+}
+
+// EffectiveTimeout - the smaller of the route timeout duration and the remaining budget of the context,
+// 0 if neither is set
+func EffectiveTimeout(ctx context.Context, duration time.Duration) (time.Duration, bool) {
+	budget, ok := RemainingBudget(ctx)
+	if !ok {
+		return duration, false
+	}
+	if duration <= 0 || budget < duration {
+		return budget, true
+	}
+	return duration, true
+}
@@ -0,0 +1,68 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+func ExampleRequestTimeout() {
+	h := make(http.Header)
+	d, ok := RequestTimeout(h)
+	fmt.Printf("test: RequestTimeout(empty) -> [budget:%v] [ok:%v]\n", d, ok)
+
+	h.Set(GrpcTimeoutHeaderName, "1500m")
+	d, ok = RequestTimeout(h)
+	fmt.Printf("test: RequestTimeout(grpc-timeout) -> [budget:%v] [ok:%v]\n", d, ok)
+
+	h.Set(RequestTimeoutHeaderName, "250")
+	d, ok = RequestTimeout(h)
+	fmt.Printf("test: RequestTimeout(x-request-timeout) -> [budget:%v] [ok:%v]\n", d, ok)
+
+	SetRequestTimeout(h, time.Millisecond*100)
+	fmt.Printf("test: SetRequestTimeout() -> [%v:%v] [%v:%v]\n", RequestTimeoutHeaderName, h.Get(RequestTimeoutHeaderName), GrpcTimeoutHeaderName, h.Get(GrpcTimeoutHeaderName))
+
+	SetRequestTimeout(h, time.Hour*30)
+	d, ok = parseGrpcTimeout(h.Get(GrpcTimeoutHeaderName))
+	fmt.Printf("test: SetRequestTimeout(hours) -> [%v:%v] [budget:%v] [ok:%v]\n", GrpcTimeoutHeaderName, h.Get(GrpcTimeoutHeaderName), d, ok)
+
+	h = make(http.Header)
+	h.Set(GrpcTimeoutHeaderName, "5X")
+	d, ok = RequestTimeout(h)
+	fmt.Printf("test: RequestTimeout(invalid) -> [budget:%v] [ok:%v]\n", d, ok)
+
+	//Output:
+	//test: RequestTimeout(empty) -> [budget:0s] [ok:false]
+	//test: RequestTimeout(grpc-timeout) -> [budget:1.5s] [ok:true]
+	//test: RequestTimeout(x-request-timeout) -> [budget:250ms] [ok:true]
+	//test: SetRequestTimeout() -> [X-Request-Timeout:100] [Grpc-Timeout:100m]
+	//test: SetRequestTimeout(hours) -> [Grpc-Timeout:108000S] [budget:30h0m0s] [ok:true]
+	//test: RequestTimeout(invalid) -> [budget:0s] [ok:false]
+
+}
+
+func ExampleEffectiveTimeout() {
+	d, ok := EffectiveTimeout(context.Background(), time.Second)
+	fmt.Printf("test: EffectiveTimeout(no-deadline) -> [timeout:%v] [propagate:%v]\n", d, ok)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*500)
+	defer cancel()
+	d, ok = EffectiveTimeout(ctx, time.Second)
+	fmt.Printf("test: EffectiveTimeout(budget) -> [budget-used:%v] [propagate:%v]\n", d <= time.Millisecond*500 && d > time.Millisecond*400, ok)
+
+	d, ok = EffectiveTimeout(ctx, time.Millisecond*100)
+	fmt.Printf("test: EffectiveTimeout(route) -> [timeout:%v] [propagate:%v]\n", d, ok)
+
+	ctx2, cancel2 := context.WithTimeout(context.Background(), 0)
+	defer cancel2()
+	d, ok = EffectiveTimeout(ctx2, time.Second)
+	fmt.Printf("test: EffectiveTimeout(expired) -> [expired:%v] [propagate:%v]\n", d <= 0, ok)
+
+	//Output:
+	//test: EffectiveTimeout(no-deadline) -> [timeout:1s] [propagate:false]
+	//test: EffectiveTimeout(budget) -> [budget-used:true] [propagate:true]
+	//test: EffectiveTimeout(route) -> [timeout:100ms] [propagate:true]
+	//test: EffectiveTimeout(expired) -> [expired:true] [propagate:true]
+
+}
@@ -0,0 +1,209 @@
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	FailoverTarget = "failover"
+
+	DefaultProbeInterval = time.Second * 5
+)
+
+// failover - automatic failover state, shared by all clones of a proxy. Primary failures are counted until the
+// threshold is reached, then requests are sent to the proxy pattern. After the probe interval, a single request is
+// sent to the primary as a recovery probe, and a successful probe switches back to the primary.
+type failover struct {
+	mu       sync.Mutex
+	limit    int
+	interval time.Duration
+	probe    time.Duration
+	failures []time.Time
+	active   bool
+	probing  bool
+	next     time.Time
+}
+
+// ParseThreshold - parse a failover threshold, either a count of consecutive failures, "5", or a count of failures
+// within an interval, "5/10s"
+func ParseThreshold(s string) (count int, interval time.Duration, err error) {
+	tokens := strings.Split(Trim(s), "/")
+	count, err = strconv.Atoi(tokens[0])
+	if err != nil {
+		return 0, 0, err
+	}
+	if count <= 0 {
+		return 0, 0, errors.New(fmt.Sprintf("invalid argument: threshold count is <= 0 [%v]", s))
+	}
+	if len(tokens) == 2 {
+		interval, err = ParseDuration(tokens[1])
+		if err != nil {
+			return 0, 0, err
+		}
+		if interval <= 0 {
+			return 0, 0, errors.New(fmt.Sprintf("invalid argument: threshold interval is <= 0 [%v]", s))
+		}
+	}
+	return count, interval, nil
+}
+
+func newFailover(config *ProxyConfig) *failover {
+	f := new(failover)
+	f.limit, f.interval, _ = ParseThreshold(config.Threshold)
+	f.probe = config.ProbeInterval
+	if f.probe <= 0 {
+		f.probe = DefaultProbeInterval
+	}
+	return f
+}
+
+// state - returns true if requests are sent to the failover target, and true if this request is a recovery probe
+func (f *failover) state() (active, probe bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if !f.active {
+		return false, false
+	}
+	if !f.probing && !time.Now().Before(f.next) {
+		f.probing = true
+		return true, true
+	}
+	return true, false
+}
+
+// record - record the outcome of a primary request, returns the new state on a transition
+func (f *failover) record(failure, probe bool) (transition string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	now := time.Now()
+	if probe {
+		f.probing = false
+		if failure {
+			f.next = now.Add(f.probe)
+			return ""
+		}
+		f.active = false
+		f.failures = nil
+		return PrimaryTarget
+	}
+	if f.active {
+		return ""
+	}
+	if !failure {
+		if f.interval == 0 {
+			f.failures = nil
+		}
+		return ""
+	}
+	f.failures = append(f.failures, now)
+	if f.interval > 0 {
+		i := 0
+		for i < len(f.failures) && now.Sub(f.failures[i]) > f.interval {
+			i++
+		}
+		f.failures = f.failures[i:]
+	}
+	if len(f.failures) < f.limit {
+		return ""
+	}
+	f.active = true
+	f.failures = nil
+	f.next = now.Add(f.probe)
+	return FailoverTarget
+}
+
+// health - apply an active health check result for the primary, returns the new state on a transition
+func (f *failover) health(healthy bool) (transition string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.failures = nil
+	if !healthy && !f.active {
+		f.active = true
+		f.probing = false
+		f.next = time.Now().Add(f.probe)
+		return FailoverTarget
+	}
+	if healthy && f.active {
+		f.active = false
+		f.probing = false
+		return PrimaryTarget
+	}
+	return ""
+}
+
+func (p *proxy) IsFailover() bool {
+	return p.failover != nil
+}
+
+// FailoverState - returns true if requests are sent to the proxy pattern, and true if this request should be sent to
+// the primary as a recovery probe
+func (p *proxy) FailoverState() (active, probe bool) {
+	if p.failover == nil {
+		return false, false
+	}
+	return p.failover.state()
+}
+
+// IsFailure - determine if a primary status code is a failure, the configured codes or 5xx by default
+func (p *proxy) IsFailure(statusCode int) bool {
+	if len(p.config.StatusCodes) == 0 {
+		return statusCode >= 500
+	}
+	for _, code := range p.config.StatusCodes {
+		if code == statusCode {
+			return true
+		}
+	}
+	return false
+}
+
+// Record - record the outcome of a request sent to the primary, the Action is signalled and the transition logged
+// when the failover state changes
+func (p *proxy) Record(statusCode int, err error, probe bool) {
+	if p.failover == nil {
+		return
+	}
+	p.transition(p.failover.record(err != nil || p.IsFailure(statusCode), probe))
+}
+
+// setHealth - apply an active health check result for the primary
+func (p *proxy) setHealth(healthy bool) {
+	if p.failover == nil {
+		return
+	}
+	p.transition(p.failover.health(healthy))
+}
+
+func (p *proxy) transition(transition string) {
+	if transition == "" {
+		return
+	}
+	if p.config.Action != nil {
+		values := url.Values{StateKey: {transition}}
+		values.Set(PatternKey, p.config.Pattern)
+		p.config.Action.Signal(values)
+	}
+	defaultFailoverFn(p.name, transition)
+}
+
+func (p *proxy) validateFailover() error {
+	if !p.config.Failover {
+		return nil
+	}
+	if p.config.Threshold == "" {
+		return errors.New(fmt.Sprintf("invalid configuration: proxy failover threshold is empty [%v]", p.name))
+	}
+	if _, _, err := ParseThreshold(p.config.Threshold); err != nil {
+		return errors.New(fmt.Sprintf("invalid configuration: proxy failover threshold is invalid [%v] [%v]", p.config.Threshold, p.name))
+	}
+	if p.config.ProbeInterval < 0 {
+		return errors.New(fmt.Sprintf("invalid configuration: proxy probe interval is < 0 [%v]", p.name))
+	}
+	return p.validatePattern(p.config.Pattern)
+}
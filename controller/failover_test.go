@@ -0,0 +1,82 @@
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+)
+
+type failoverAction struct{}
+
+func (failoverAction) Signal(values url.Values) error {
+	fmt.Printf("test: Signal() -> [state:%v] [pattern:%v]\n", values.Get(StateKey), values.Get(PatternKey))
+	return nil
+}
+
+func ExampleParseThreshold() {
+	count, interval, err := ParseThreshold("5")
+	fmt.Printf("test: ParseThreshold(\"5\") -> [count:%v] [interval:%v] [err:%v]\n", count, interval, err)
+
+	count, interval, err = ParseThreshold("3/10s")
+	fmt.Printf("test: ParseThreshold(\"3/10s\") -> [count:%v] [interval:%v] [err:%v]\n", count, interval, err)
+
+	_, _, err = ParseThreshold("0")
+	fmt.Printf("test: ParseThreshold(\"0\") -> [err:%v]\n", err)
+
+	//Output:
+	//test: ParseThreshold("5") -> [count:5] [interval:0s] [err:<nil>]
+	//test: ParseThreshold("3/10s") -> [count:3] [interval:10s] [err:<nil>]
+	//test: ParseThreshold("0") -> [err:invalid argument: threshold count is <= 0 [0]]
+
+}
+
+func ExampleProxy_Failover() {
+	t := newTable(true, false)
+	config := NewProxyConfig(false, "http://localhost:8080", nil, failoverAction{}, "2")
+	config.Failover = true
+	config.ProbeInterval = time.Millisecond * 50
+	p := newProxy("failover-route", t, config)
+	fmt.Printf("test: validate() -> [failover:%v] [err:%v]\n", p.IsFailover(), p.validate())
+
+	p.Record(503, nil, false)
+	p.Record(200, nil, false)
+	p.Record(503, nil, false)
+	active, probe := p.FailoverState()
+	fmt.Printf("test: Record(503,200,503) -> [active:%v] [probe:%v]\n", active, probe)
+
+	p.Record(0, errors.New("dial error"), false)
+	active, probe = p.FailoverState()
+	fmt.Printf("test: Record(error) -> [active:%v] [probe:%v]\n", active, probe)
+
+	time.Sleep(time.Millisecond * 100)
+	active, probe = p.FailoverState()
+	fmt.Printf("test: FailoverState() -> [active:%v] [probe:%v]\n", active, probe)
+	p.Record(500, nil, probe)
+	active, probe = p.FailoverState()
+	fmt.Printf("test: Record(probe-500) -> [active:%v] [probe:%v]\n", active, probe)
+
+	time.Sleep(time.Millisecond * 100)
+	active, probe = p.FailoverState()
+	p.Record(200, nil, probe)
+	active, probe = p.FailoverState()
+	fmt.Printf("test: Record(probe-200) -> [active:%v] [probe:%v]\n", active, probe)
+
+	config = NewProxyConfig(false, "http://localhost:8080", nil, nil, "")
+	config.Failover = true
+	fmt.Printf("test: validate() -> [err:%v]\n", newProxy("failover-route", t, config).validate())
+
+	//Output:
+	//test: validate() -> [failover:true] [err:<nil>]
+	//test: Record(503,200,503) -> [active:false] [probe:false]
+	//test: Signal() -> [state:failover] [pattern:http://localhost:8080]
+	//{"route":"failover-route", "proxy":"failover", "transition":"failover state changed"}
+	//test: Record(error) -> [active:true] [probe:false]
+	//test: FailoverState() -> [active:true] [probe:true]
+	//test: Record(probe-500) -> [active:true] [probe:false]
+	//test: Signal() -> [state:primary] [pattern:http://localhost:8080]
+	//{"route":"failover-route", "proxy":"primary", "transition":"failover state changed"}
+	//test: Record(probe-200) -> [active:false] [probe:false]
+	//test: validate() -> [err:invalid configuration: proxy failover threshold is empty [failover-route]]
+
+}
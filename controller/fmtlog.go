@@ -8,12 +8,13 @@ import (
 	"time"
 )
 
-func FmtLog(traffic string, start time.Time, duration time.Duration, req *http.Request, resp *http.Response, routeName string, timeout int, rateLimit rate.Limit, rateBurst int, rateThreshold, retry, proxy, proxyThreshold, statusFlags string) string {
+func FmtLog(traffic string, start time.Time, duration time.Duration, req *http.Request, resp *http.Response, routeName, priority string, timeout int, rateLimit rate.Limit, rateBurst int, rateThreshold, rateKey, retry string, attempt int, proxy, proxyThreshold, statusFlags string) string {
 	d := int(duration / time.Duration(1e6))
 	s := fmt.Sprintf("start:%v ,"+
 		"duration:%v ,"+
 		"traffic:%v, "+
 		"route:%v, "+
+		"priority:%v, "+
 		"request-id:%v, "+
 		"protocol:%v, "+
 		"method:%v, "+
@@ -25,7 +26,9 @@ func FmtLog(traffic string, start time.Time, duration time.Duration, req *http.R
 		"rate-limit:%v, "+
 		"rate-burst:%v, "+
 		"rate-threshold:%v, "+
+		"rate-key:%v, "+
 		"retry:%v, "+
+		"attempt:%v, "+
 		"proxy:%v, "+
 		"proxy-threshold:%v, "+
 		"status-flags:%v",
@@ -33,6 +36,7 @@ func FmtLog(traffic string, start time.Time, duration time.Duration, req *http.R
 		strconv.Itoa(d),     //l.Value(DurationOperator),
 		traffic,             //l.Value(TrafficOperator),
 		routeName,           //l.Value(RouteNameOperator),
+		priority,
 
 		req.Header.Get(RequestIdHeaderName), //l.Value(RequestIdOperator),
 		req.Proto,                           //l.Value(RequestProtocolOperator),
@@ -48,8 +52,10 @@ func FmtLog(traffic string, start time.Time, duration time.Duration, req *http.R
 		rateLimit, //l.Value(RateLimitOperator),
 		rateBurst, //l.Value(RateBurstOperator),
 		rateThreshold,
+		rateKey,
 
 		retry,
+		attempt,
 		proxy,
 		proxyThreshold,
 		statusFlags, //l.Value(StatusFlagsOperator),
@@ -0,0 +1,86 @@
+package controller
+
+import (
+	"google.golang.org/grpc/codes"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// https://github.com/grpc/grpc/blob/master/doc/http-grpc-status-mapping.md
+
+const (
+	GRPCStatusHeaderName = "Grpc-Status"
+)
+
+// HttpStatusFromCode - the HTTP status code for a gRPC status code
+func HttpStatusFromCode(code codes.Code) int {
+	switch code {
+	case codes.OK:
+		return http.StatusOK
+	case codes.Canceled:
+		return 499
+	case codes.InvalidArgument, codes.FailedPrecondition, codes.OutOfRange:
+		return http.StatusBadRequest
+	case codes.DeadlineExceeded:
+		return http.StatusGatewayTimeout
+	case codes.NotFound:
+		return http.StatusNotFound
+	case codes.AlreadyExists, codes.Aborted:
+		return http.StatusConflict
+	case codes.PermissionDenied:
+		return http.StatusForbidden
+	case codes.Unauthenticated:
+		return http.StatusUnauthorized
+	case codes.ResourceExhausted:
+		return http.StatusTooManyRequests
+	case codes.Unimplemented:
+		return http.StatusNotImplemented
+	case codes.Unavailable:
+		return http.StatusServiceUnavailable
+	}
+	return http.StatusInternalServerError
+}
+
+// CodeFromHttpStatus - the gRPC status code for an HTTP status code, used to map the configured behavior status codes
+func CodeFromHttpStatus(statusCode int) codes.Code {
+	switch statusCode {
+	case http.StatusOK:
+		return codes.OK
+	case http.StatusBadRequest:
+		return codes.InvalidArgument
+	case http.StatusUnauthorized:
+		return codes.Unauthenticated
+	case http.StatusForbidden:
+		return codes.PermissionDenied
+	case http.StatusNotFound:
+		return codes.NotFound
+	case http.StatusConflict:
+		return codes.Aborted
+	case http.StatusTooManyRequests:
+		return codes.ResourceExhausted
+	case http.StatusNotImplemented:
+		return codes.Unimplemented
+	case http.StatusBadGateway, http.StatusServiceUnavailable:
+		return codes.Unavailable
+	case http.StatusGatewayTimeout:
+		return codes.DeadlineExceeded
+	}
+	if statusCode >= 200 && statusCode < 300 {
+		return codes.OK
+	}
+	return codes.Unknown
+}
+
+// NewGRPCResponse - response for logging a gRPC call, the status code is mapped to HTTP and the gRPC status
+// is set in the trailer
+func NewGRPCResponse(req *http.Request, code codes.Code) *http.Response {
+	resp := &http.Response{Request: req, StatusCode: HttpStatusFromCode(code), ContentLength: -1}
+	resp.Trailer = make(http.Header)
+	resp.Trailer.Set(GRPCStatusHeaderName, strconv.Itoa(int(code)))
+	return resp
+}
+
+func (c *controller) LogGRPCIngress(start time.Time, duration time.Duration, req *http.Request, code codes.Code, statusFlags string) {
+	c.logIngress(start, duration, req, NewGRPCResponse(req, code), statusFlags)
+}
@@ -0,0 +1,35 @@
+package controller
+
+import (
+	"fmt"
+	"google.golang.org/grpc/codes"
+)
+
+func ExampleHttpStatusFromCode() {
+	fmt.Printf("test: HttpStatusFromCode() -> [ok:%v] [unavailable:%v] [deadline:%v] [internal:%v]\n", HttpStatusFromCode(codes.OK), HttpStatusFromCode(codes.Unavailable), HttpStatusFromCode(codes.DeadlineExceeded), HttpStatusFromCode(codes.Internal))
+	fmt.Printf("test: CodeFromHttpStatus() -> [200:%v] [429:%v] [503:%v] [500:%v]\n", CodeFromHttpStatus(200), CodeFromHttpStatus(429), CodeFromHttpStatus(503), CodeFromHttpStatus(500))
+
+	resp := NewGRPCResponse(nil, codes.NotFound)
+	fmt.Printf("test: NewGRPCResponse() -> [status-code:%v] [grpc-status:%v]\n", resp.StatusCode, resp.Trailer.Get(GRPCStatusHeaderName))
+
+	//Output:
+	//test: HttpStatusFromCode() -> [ok:200] [unavailable:503] [deadline:504] [internal:500]
+	//test: CodeFromHttpStatus() -> [200:OK] [429:ResourceExhausted] [503:Unavailable] [500:Unknown]
+	//test: NewGRPCResponse() -> [status-code:404] [grpc-status:5]
+
+}
+
+func ExampleRetry_IsRetryableCode() {
+	r := newRetry("test-route", nil, NewRetryConfig(true, 100, 10, 0, []int{503, 504}))
+	fmt.Printf("test: IsRetryableCode() -> [unavailable:%v] [deadline:%v] [not-found:%v] [ok:%v]\n", r.IsRetryableCode(codes.Unavailable), r.IsRetryableCode(codes.DeadlineExceeded), r.IsRetryableCode(codes.NotFound), r.IsRetryableCode(codes.OK))
+
+	c := NewRetryConfig(true, 100, 10, 0, nil)
+	c.GRPCCodes = []int{int(codes.Aborted)}
+	r = newRetry("test-route", nil, c)
+	fmt.Printf("test: IsRetryableCode(grpc-codes) -> [aborted:%v] [unavailable:%v]\n", r.IsRetryableCode(codes.Aborted), r.IsRetryableCode(codes.Unavailable))
+
+	//Output:
+	//test: IsRetryableCode() -> [unavailable:true] [deadline:true] [not-found:false] [ok:false]
+	//test: IsRetryableCode(grpc-codes) -> [aborted:true] [unavailable:false]
+
+}
@@ -0,0 +1,389 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultHealthCheckPath     = "/health"
+	DefaultHealthCheckInterval = time.Second * 10
+	DefaultHealthCheckTimeout  = time.Second * 2
+	DefaultHealthyThreshold    = 2
+	DefaultUnhealthyThreshold  = 3
+	DefaultHealthCheckScheme   = "http"
+)
+
+var healthCheckTick = time.Millisecond * 100
+
+// HealthCheck - interface for active health checks of the upstreams of an egress route. The primary host and the
+// endpoints of a pool are probed, and the results are fed into the proxy failover and the pool endpoint state.
+type HealthCheck interface {
+	State
+	Actuator
+	Path() string
+	Interval() time.Duration
+	Targets() []Health
+}
+
+type HealthCheckConfig struct {
+	Enabled            bool
+	Scheme             string        // probe scheme, defaults to http
+	Host               string        // primary upstream host, pool endpoints are always probed
+	Path               string        // probe path, defaults to /health
+	Interval           time.Duration // wait between probes
+	Timeout            time.Duration // probe timeout
+	HealthyThreshold   int           // consecutive successful probes before an unhealthy target is healthy
+	UnhealthyThreshold int           // consecutive failed probes before a healthy target is unhealthy
+}
+
+// Health - the health of a probed target
+type Health struct {
+	Route     string
+	Target    string
+	Healthy   bool
+	Successes int // consecutive successful probes
+	Failures  int // consecutive failed probes
+	LastCheck time.Time
+	LastError string
+}
+
+var nilHealthCheck = newHealthCheck(NilBehaviorName, nil, NewHealthCheckConfig(false, "", "", 0, 0))
+
+func NewHealthCheckConfig(enabled bool, host, path string, interval, timeout time.Duration) *HealthCheckConfig {
+	c := new(HealthCheckConfig)
+	c.Enabled = enabled
+	c.Host = host
+	c.Path = path
+	c.Interval = interval
+	c.Timeout = timeout
+	c.HealthyThreshold = DefaultHealthyThreshold
+	c.UnhealthyThreshold = DefaultUnhealthyThreshold
+	return c
+}
+
+// healthTargets - probe state, shared by all clones of a health check
+type healthTargets struct {
+	mu      sync.Mutex
+	next    time.Time
+	probing bool
+	targets map[string]*Health
+}
+
+type healthCheck struct {
+	name   string
+	table  *table
+	config HealthCheckConfig
+	state  *healthTargets
+}
+
+func cloneHealthCheck(curr *healthCheck) *healthCheck {
+	t := new(healthCheck)
+	*t = *curr
+	return t
+}
+
+func newHealthCheck(name string, table *table, config *HealthCheckConfig) *healthCheck {
+	t := new(healthCheck)
+	t.name = name
+	t.table = table
+	if config != nil {
+		t.config = *config
+	}
+	if t.config.Scheme == "" {
+		t.config.Scheme = DefaultHealthCheckScheme
+	}
+	if t.config.Path == "" {
+		t.config.Path = DefaultHealthCheckPath
+	}
+	if t.config.Interval <= 0 {
+		t.config.Interval = DefaultHealthCheckInterval
+	}
+	if t.config.Timeout <= 0 {
+		t.config.Timeout = DefaultHealthCheckTimeout
+	}
+	if t.config.HealthyThreshold <= 0 {
+		t.config.HealthyThreshold = DefaultHealthyThreshold
+	}
+	if t.config.UnhealthyThreshold <= 0 {
+		t.config.UnhealthyThreshold = DefaultUnhealthyThreshold
+	}
+	t.state = &healthTargets{targets: make(map[string]*Health)}
+	return t
+}
+
+func (h *healthCheck) validate() error {
+	if h.config.Scheme != "http" && h.config.Scheme != "https" {
+		return errors.New(fmt.Sprintf("invalid configuration: HealthCheck scheme is invalid [%v] [%v]", h.config.Scheme, h.name))
+	}
+	if !strings.HasPrefix(h.config.Path, "/") {
+		return errors.New(fmt.Sprintf("invalid configuration: HealthCheck path is invalid [%v] [%v]", h.config.Path, h.name))
+	}
+	if h.config.Timeout > h.config.Interval {
+		return errors.New(fmt.Sprintf("invalid configuration: HealthCheck timeout is > interval [%v]", h.name))
+	}
+	return nil
+}
+
+func (h *healthCheck) IsEnabled() bool { return h.config.Enabled }
+
+func (h *healthCheck) IsNil() bool { return h.name == NilBehaviorName }
+
+func (h *healthCheck) Enable() {
+	if h.IsEnabled() {
+		return
+	}
+	h.enableHealthCheck(true)
+}
+
+func (h *healthCheck) Disable() {
+	if !h.IsEnabled() {
+		return
+	}
+	h.enableHealthCheck(false)
+}
+
+func (h *healthCheck) Signal(values url.Values) error {
+	if h.IsNil() {
+		return errors.New("invalid signal: health check is not configured")
+	}
+	if values == nil {
+		return errors.New("invalid argument: values are nil for health check signal")
+	}
+	UpdateEnable(h, values)
+	if values.Has(IntervalKey) {
+		duration, err := ParseDuration(values.Get(IntervalKey))
+		if err != nil {
+			return err
+		}
+		if duration < h.config.Timeout {
+			return errors.New(fmt.Sprintf("invalid argument: interval is < health check timeout [%v]", values.Get(IntervalKey)))
+		}
+		if duration != h.config.Interval {
+			h.setInterval(duration)
+		}
+	}
+	return nil
+}
+
+func (h *healthCheck) Path() string {
+	return h.config.Path
+}
+
+func (h *healthCheck) Interval() time.Duration {
+	return h.config.Interval
+}
+
+// Targets - the health of the probed targets, ordered by target
+func (h *healthCheck) Targets() []Health {
+	h.state.mu.Lock()
+	defer h.state.mu.Unlock()
+	var targets []Health
+	for _, health := range h.state.targets {
+		targets = append(targets, *health)
+	}
+	sort.Slice(targets, func(i, j int) bool { return targets[i].Target < targets[j].Target })
+	return targets
+}
+
+// due - determine if a probe is due, only one probe of a route is in progress at a time
+func (h *healthCheck) due(now time.Time) bool {
+	h.state.mu.Lock()
+	defer h.state.mu.Unlock()
+	if h.state.probing || now.Before(h.state.next) {
+		return false
+	}
+	h.state.probing = true
+	h.state.next = now.Add(h.config.Interval)
+	return true
+}
+
+// record - record a probe result, returns true if the health of the target changed
+func (h *healthCheck) record(target string, err error) (healthy, changed bool) {
+	h.state.mu.Lock()
+	defer h.state.mu.Unlock()
+	health, ok := h.state.targets[target]
+	if !ok {
+		health = &Health{Route: h.name, Target: target, Healthy: true}
+		h.state.targets[target] = health
+	}
+	health.LastCheck = time.Now().UTC()
+	if err == nil {
+		health.LastError = ""
+		health.Successes++
+		health.Failures = 0
+		if !health.Healthy && health.Successes >= h.config.HealthyThreshold {
+			health.Healthy = true
+			return true, true
+		}
+		return health.Healthy, false
+	}
+	health.LastError = err.Error()
+	health.Failures++
+	health.Successes = 0
+	if health.Healthy && health.Failures >= h.config.UnhealthyThreshold {
+		health.Healthy = false
+		return false, true
+	}
+	return health.Healthy, false
+}
+
+// probe - send a health check request to a target, a 2xx status code is healthy
+func (h *healthCheck) probe(client *http.Client, target string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), h.config.Timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, h.config.Scheme+"://"+target+h.config.Path, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return errors.New(fmt.Sprintf("health check status code is not 2xx [%v]", resp.StatusCode))
+	}
+	return nil
+}
+
+func (h *healthCheck) enableHealthCheck(enabled bool) {
+	if h.table == nil || h.IsNil() {
+		return
+	}
+	h.table.mu.Lock()
+	defer h.table.mu.Unlock()
+	if ctrl, ok := h.table.controllers[h.name]; ok {
+		c := cloneHealthCheck(ctrl.healthCheck)
+		c.config.Enabled = enabled
+		h.table.update(h.name, cloneController[*healthCheck](ctrl, c))
+	}
+}
+
+func (h *healthCheck) setInterval(interval time.Duration) {
+	if h.table == nil || h.IsNil() {
+		return
+	}
+	h.table.mu.Lock()
+	defer h.table.mu.Unlock()
+	if ctrl, ok := h.table.controllers[h.name]; ok {
+		c := cloneHealthCheck(ctrl.healthCheck)
+		c.config.Interval = interval
+		h.table.update(h.name, cloneController[*healthCheck](ctrl, c))
+	}
+}
+
+// StartHealthChecks - start probing the upstreams of routes with an enabled health check. Routes are rescanned on
+// every tick, so routes that are added or reloaded are included without a restart. The default client uses the
+// default transport, so probes are not sent through a controller wrapper installed on http.DefaultClient.
+func (t *table) StartHealthChecks(client *http.Client) (stop func()) {
+	if client == nil {
+		client = &http.Client{Transport: http.DefaultTransport}
+	}
+	done := make(chan struct{})
+	ticker := time.NewTicker(healthCheckTick)
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case now := <-ticker.C:
+				for _, ctrl := range t.healthChecks() {
+					if ctrl.healthCheck.due(now) {
+						wg.Add(1)
+						go func(ctrl *controller) {
+							defer wg.Done()
+							t.check(client, ctrl)
+						}(ctrl)
+					}
+				}
+			}
+		}
+	}()
+	var once sync.Once
+	// Stopping waits for probes in progress, so no results are applied after the stop returns
+	return func() {
+		once.Do(func() {
+			close(done)
+			wg.Wait()
+		})
+	}
+}
+
+func (t *table) healthChecks() []*controller {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	var ctrls []*controller
+	for _, ctrl := range t.controllers {
+		if ctrl.healthCheck.IsEnabled() {
+			ctrls = append(ctrls, ctrl)
+		}
+	}
+	return ctrls
+}
+
+// check - probe the primary host and the pool endpoints of a route in parallel, so a slow target does not delay
+// the others
+func (t *table) check(client *http.Client, ctrl *controller) {
+	hc := ctrl.healthCheck
+	defer func() {
+		hc.state.mu.Lock()
+		hc.state.probing = false
+		hc.state.mu.Unlock()
+	}()
+	var wg sync.WaitGroup
+	if hc.config.Host != "" {
+		wg.Add(1)
+		go func(host string) {
+			defer wg.Done()
+			if healthy, changed := hc.record(host, hc.probe(client, host)); changed {
+				defaultHealthFn(ctrl.name, host, healthy)
+				if curr := t.current(ctrl.name); curr != nil {
+					curr.proxy.setHealth(healthy)
+				}
+			}
+		}(hc.config.Host)
+	}
+	for _, host := range ctrl.pool.config.Endpoints {
+		wg.Add(1)
+		go func(host string) {
+			defer wg.Done()
+			if healthy, changed := hc.record(host, hc.probe(client, host)); changed {
+				defaultHealthFn(ctrl.name, host, healthy)
+				if curr := t.current(ctrl.name); curr != nil {
+					curr.pool.setHealth(host, healthy)
+				}
+			}
+		}(host)
+	}
+	wg.Wait()
+}
+
+// Health - the health of the probed targets of a route, or all routes if the name is empty, ordered by route and target
+func (t *table) Health(name string) []Health {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	names := make([]string, 0, len(t.controllers))
+	for n := range t.controllers {
+		if name == "" || n == name {
+			names = append(names, n)
+		}
+	}
+	sort.Strings(names)
+	var health []Health
+	for _, n := range names {
+		health = append(health, t.controllers[n].healthCheck.Targets()...)
+	}
+	return health
+}
@@ -0,0 +1,168 @@
+package controller
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+func Example_newHealthCheck() {
+	h := newHealthCheck("test-route", newTable(true, false), NewHealthCheckConfig(true, "localhost:8080", "", 0, 0))
+	fmt.Printf("test: newHealthCheck() -> [name:%v] [scheme:%v] [path:%v] [interval:%v] [timeout:%v] [healthy:%v] [unhealthy:%v]\n", h.name, h.config.Scheme, h.Path(), h.Interval(), h.config.Timeout,
+		h.config.HealthyThreshold, h.config.UnhealthyThreshold)
+
+	fmt.Printf("test: validate() -> [%v]\n", newHealthCheck("test-route", nil, NewHealthCheckConfig(true, "localhost:8080", "health", 0, 0)).validate())
+	fmt.Printf("test: validate() -> [%v]\n", newHealthCheck("test-route", nil, NewHealthCheckConfig(true, "localhost:8080", "", time.Second, time.Second*2)).validate())
+
+	h.config.UnhealthyThreshold = 2
+	h.config.HealthyThreshold = 1
+	healthy, changed := h.record("localhost:8080", fmt.Errorf("connection refused"))
+	fmt.Printf("test: record(error) -> [healthy:%v] [changed:%v]\n", healthy, changed)
+	healthy, changed = h.record("localhost:8080", fmt.Errorf("connection refused"))
+	fmt.Printf("test: record(error) -> [healthy:%v] [changed:%v] [targets:%v]\n", healthy, changed, len(h.Targets()))
+	healthy, changed = h.record("localhost:8080", nil)
+	fmt.Printf("test: record(ok) -> [healthy:%v] [changed:%v]\n", healthy, changed)
+
+	//Output:
+	//test: newHealthCheck() -> [name:test-route] [scheme:http] [path:/health] [interval:10s] [timeout:2s] [healthy:2] [unhealthy:3]
+	//test: validate() -> [invalid configuration: HealthCheck path is invalid [health] [test-route]]
+	//test: validate() -> [invalid configuration: HealthCheck timeout is > interval [test-route]]
+	//test: record(error) -> [healthy:true] [changed:false]
+	//test: record(error) -> [healthy:false] [changed:true] [targets:1]
+	//test: record(ok) -> [healthy:true] [changed:true]
+
+}
+
+func ExampleHealthCheck_Signal() {
+	name := "test-route"
+	t := newTable(true, false)
+	errs := t.AddController(newRoute(name, NewHealthCheckConfig(true, "localhost:8081", "", time.Second, time.Millisecond*100)))
+	fmt.Printf("test: Add() -> [%v] [count:%v]\n", errs, t.count())
+
+	err := t.LookupByName(name).HealthCheck().Signal(NewValues(IntervalKey, "50ms"))
+	fmt.Printf("test: Signal(interval=50ms) -> [error:%v]\n", err)
+
+	t.LookupByName(name).HealthCheck().Signal(enableValues(false))
+	fmt.Printf("test: Disable() -> [enabled:%v]\n", t.LookupByName(name).HealthCheck().IsEnabled())
+
+	err = t.LookupByName(name).HealthCheck().Signal(url.Values{EnabledKey: {TrueValue}, IntervalKey: {"2s"}})
+	hc := t.LookupByName(name).HealthCheck()
+	fmt.Printf("test: Signal(enabled,interval) -> [error:%v] [enabled:%v] [interval:%v]\n", err, hc.IsEnabled(), hc.Interval())
+
+	//Output:
+	//test: Add() -> [[]] [count:1]
+	//test: Signal(interval=50ms) -> [error:invalid argument: interval is < health check timeout [50ms]]
+	//test: Disable() -> [enabled:false]
+	//test: Signal(enabled,interval) -> [error:<nil>] [enabled:true] [interval:2s]
+
+}
+
+func ExampleTable_StartHealthChecks() {
+	failing := int32(1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/health" || atomic.LoadInt32(&failing) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+	primary := strings.TrimPrefix(server.URL, "http://")
+
+	name := "health-route"
+	t := newTable(true, false)
+	proxy := NewProxyConfig(false, "http://localhost:8081", nil, nil, "3")
+	proxy.Failover = true
+	hc := NewHealthCheckConfig(true, primary, "", time.Millisecond*100, time.Millisecond*50)
+	hc.HealthyThreshold = 1
+	hc.UnhealthyThreshold = 1
+	errs := t.AddController(newRoute(name, proxy, hc, NewPoolConfig(true, "", []string{primary, "localhost:1"})))
+	fmt.Printf("test: AddController() -> %v\n", errs)
+
+	SetHealthFn(func(route, target string, healthy bool) {})
+	defer SetHealthFn(func(route, target string, healthy bool) {
+		fmt.Printf("{\"route\":\"%v\", \"target\":\"%v\", \"healthy\":%v}\n", route, target, healthy)
+	})
+	stop := t.StartHealthChecks(nil)
+	defer stop()
+	time.Sleep(time.Millisecond * 300)
+	active, _ := t.LookupByName(name).Proxy().FailoverState()
+	health := t.Health(name)
+	fmt.Printf("test: Health() -> [targets:%v] [healthy:%v,%v] [failover:%v] [select:%v]\n", len(health), health[0].Healthy, health[1].Healthy, active,
+		t.LookupByName(name).Pool().Select() != "")
+
+	atomic.StoreInt32(&failing, 0)
+	time.Sleep(time.Millisecond * 300)
+	active, _ = t.LookupByName(name).Proxy().FailoverState()
+	var healthy bool
+	for _, h := range t.LookupByName(name).HealthCheck().Targets() {
+		if h.Target == primary {
+			healthy = h.Healthy
+		}
+	}
+	fmt.Printf("test: Health() -> [primary-healthy:%v] [failover:%v] [select:%v]\n", healthy, active, t.LookupByName(name).Pool().Select() == primary)
+
+	err := t.LookupByName(name).Signal(url.Values{BehaviorKey: {HealthCheckBehavior}, IntervalKey: {"10ms"}})
+	fmt.Printf("test: Signal(interval=10ms) -> [err:%v]\n", err)
+
+	//Output:
+	//test: AddController() -> []
+	//{"route":"health-route", "proxy":"failover", "transition":"failover state changed"}
+	//test: Health() -> [targets:2] [healthy:false,false] [failover:true] [select:true]
+	//{"route":"health-route", "proxy":"primary", "transition":"failover state changed"}
+	//test: Health() -> [primary-healthy:true] [failover:false] [select:true]
+	//test: Signal(interval=10ms) -> [err:invalid argument: interval is < health check timeout [10ms]]
+
+}
+
+type countTripper struct {
+	calls int32
+}
+
+func (c *countTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	atomic.AddInt32(&c.calls, 1)
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func Example_check_Parallel() {
+	var hosts []string
+	for i := 0; i < 3; i++ {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			time.Sleep(time.Millisecond * 50)
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer server.Close()
+		hosts = append(hosts, strings.TrimPrefix(server.URL, "http://"))
+	}
+
+	name := "health-parallel-route"
+	t := newTable(true, false)
+	hc := NewHealthCheckConfig(true, hosts[0], "", time.Millisecond*200, time.Millisecond*100)
+	errs := t.AddController(newRoute(name, hc, NewPoolConfig(true, "", hosts[1:])))
+	fmt.Printf("test: AddController() -> %v\n", errs)
+
+	// The targets are probed in parallel, so the check takes about as long as the slowest target
+	start := time.Now()
+	t.check(&http.Client{Transport: http.DefaultTransport}, t.LookupByName(name).(*controller))
+	fmt.Printf("test: check() -> [parallel:%v]\n", time.Since(start) < time.Millisecond*150)
+
+	// The default client is not sent through a transport installed on http.DefaultClient
+	tripper := &countTripper{}
+	prev := http.DefaultClient.Transport
+	http.DefaultClient.Transport = tripper
+	stop := t.StartHealthChecks(nil)
+	time.Sleep(time.Millisecond * 300)
+	stop()
+	http.DefaultClient.Transport = prev
+	fmt.Printf("test: StartHealthChecks(nil) -> [default-client:%v]\n", atomic.LoadInt32(&tripper.calls))
+
+	//Output:
+	//test: AddController() -> []
+	//test: check() -> [parallel:true]
+	//test: StartHealthChecks(nil) -> [default-client:0]
+
+}
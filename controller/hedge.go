@@ -0,0 +1,185 @@
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// https://research.google/pubs/pub40801/ - The Tail at Scale
+
+const (
+	DefaultMaxHedges = 1
+)
+
+// Hedge - interface for hedged requests, additional copies of a request are sent when the previous copy
+// has not answered within the delay
+type Hedge interface {
+	State
+	Actuator
+	Delay() time.Duration
+	MaxHedges() int
+	IsHedgeable(method string) bool
+}
+
+type HedgeConfig struct {
+	Enabled   bool
+	Delay     time.Duration // wait before sending the next copy of a request
+	MaxHedges int           // copies sent in addition to the original request
+	Methods   []string      // hedgeable methods, defaults to GET and HEAD when empty
+}
+
+var nilHedge = newHedge(NilBehaviorName, nil, NewHedgeConfig(false, 0, 0, nil))
+
+func NewHedgeConfig(enabled bool, delay time.Duration, maxHedges int, methods []string) *HedgeConfig {
+	c := new(HedgeConfig)
+	if maxHedges <= 0 {
+		maxHedges = DefaultMaxHedges
+	}
+	c.Enabled = enabled
+	c.Delay = delay
+	c.MaxHedges = maxHedges
+	c.Methods = methods
+	return c
+}
+
+type hedge struct {
+	name   string
+	table  *table
+	config HedgeConfig
+}
+
+func cloneHedge(curr *hedge) *hedge {
+	t := new(hedge)
+	*t = *curr
+	return t
+}
+
+func newHedge(name string, table *table, config *HedgeConfig) *hedge {
+	t := new(hedge)
+	t.name = name
+	t.table = table
+	if config != nil {
+		t.config = *config
+	}
+	if t.config.MaxHedges <= 0 {
+		t.config.MaxHedges = DefaultMaxHedges
+	}
+	return t
+}
+
+func (h *hedge) validate() error {
+	if h.config.Delay <= 0 {
+		return errors.New(fmt.Sprintf("invalid configuration: Hedge delay is <= 0 [%v]", h.name))
+	}
+	return nil
+}
+
+func (h *hedge) IsEnabled() bool { return h.config.Enabled }
+
+func (h *hedge) IsNil() bool { return h.name == NilBehaviorName }
+
+func (h *hedge) Enable() {
+	if h.IsEnabled() {
+		return
+	}
+	h.enableHedge(true)
+}
+
+func (h *hedge) Disable() {
+	if !h.IsEnabled() {
+		return
+	}
+	h.enableHedge(false)
+}
+
+func (h *hedge) Signal(values url.Values) error {
+	if h.IsNil() {
+		return errors.New("invalid signal: hedge is not configured")
+	}
+	if values == nil {
+		return errors.New("invalid argument: values are nil for hedge signal")
+	}
+	UpdateEnable(h, values)
+	config := h.config
+	if values.Has(DelayKey) {
+		duration, err := ParseDuration(values.Get(DelayKey))
+		if err != nil {
+			return err
+		}
+		if duration <= 0 {
+			return errors.New("invalid configuration: delay duration is <= 0")
+		}
+		config.Delay = duration
+	}
+	if values.Has(HedgesKey) {
+		hedges, err := strconv.Atoi(values.Get(HedgesKey))
+		if err != nil {
+			return err
+		}
+		if hedges <= 0 {
+			return errors.New(fmt.Sprintf("invalid argument: hedges value is <= 0 [%v]", hedges))
+		}
+		config.MaxHedges = hedges
+	}
+	if config.Delay != h.config.Delay || config.MaxHedges != h.config.MaxHedges {
+		h.setHedge(config)
+	}
+	return nil
+}
+
+func (h *hedge) Delay() time.Duration {
+	return h.config.Delay
+}
+
+func (h *hedge) MaxHedges() int {
+	return h.config.MaxHedges
+}
+
+// IsHedgeable - only read-only methods are hedged unless other methods are configured, as the upstream
+// may receive every copy of the request
+func (h *hedge) IsHedgeable(method string) bool {
+	if method == "" {
+		method = http.MethodGet
+	}
+	if len(h.config.Methods) == 0 {
+		return method == http.MethodGet || method == http.MethodHead
+	}
+	for _, m := range h.config.Methods {
+		if strings.EqualFold(m, method) {
+			return true
+		}
+	}
+	return false
+}
+
+func (h *hedge) enableHedge(enabled bool) {
+	if h.table == nil || h.IsNil() {
+		return
+	}
+	h.table.mu.Lock()
+	defer h.table.mu.Unlock()
+	if ctrl, ok := h.table.controllers[h.name]; ok {
+		c := cloneHedge(ctrl.hedge)
+		c.config.Enabled = enabled
+		h.table.update(h.name, cloneController[*hedge](ctrl, c))
+	}
+}
+
+func (h *hedge) setHedge(config HedgeConfig) {
+	if h.table == nil || h.IsNil() {
+		return
+	}
+	h.table.mu.Lock()
+	defer h.table.mu.Unlock()
+	if ctrl, ok := h.table.controllers[h.name]; ok {
+		c := cloneHedge(ctrl.hedge)
+		c.config.Delay = config.Delay
+		c.config.MaxHedges = config.MaxHedges
+		h.table.update(h.name, cloneController[*hedge](ctrl, c))
+	}
+}
@@ -0,0 +1,63 @@
+package controller
+
+import (
+	"fmt"
+	"net/url"
+	"time"
+)
+
+func Example_newHedge() {
+	h := newHedge("test-route", newTable(true, false), NewHedgeConfig(true, time.Millisecond*50, 0, []string{"get", "POST"}))
+	fmt.Printf("test: newHedge() -> [name:%v] [delay:%v] [hedges:%v]\n", h.name, h.Delay(), h.MaxHedges())
+	fmt.Printf("test: IsHedgeable() -> [GET:%v] [POST:%v] [HEAD:%v]\n", h.IsHedgeable("GET"), h.IsHedgeable("POST"), h.IsHedgeable("HEAD"))
+
+	h2 := cloneHedge(h)
+	h2.config.MaxHedges = 3
+	fmt.Printf("test: cloneHedge() -> [prev-hedges:%v] [curr-hedges:%v]\n", h.MaxHedges(), h2.MaxHedges())
+
+	fmt.Printf("test: validate() -> [%v]\n", newHedge("test-route", nil, NewHedgeConfig(true, 0, 1, nil)).validate())
+
+	//Output:
+	//test: newHedge() -> [name:test-route] [delay:50ms] [hedges:1]
+	//test: IsHedgeable() -> [GET:true] [POST:true] [HEAD:false]
+	//test: cloneHedge() -> [prev-hedges:1] [curr-hedges:3]
+	//test: validate() -> [invalid configuration: Hedge delay is <= 0 [test-route]]
+
+}
+
+func ExampleHedge_Signal() {
+	name := "test-route"
+	t := newTable(true, false)
+	errs := t.AddController(newRoute(name, NewHedgeConfig(true, time.Millisecond*50, 1, nil)))
+	fmt.Printf("test: Add() -> [%v] [count:%v]\n", errs, t.count())
+
+	values := make(url.Values)
+	values.Add(BehaviorKey, HedgeBehavior)
+	values.Add(DelayKey, "10ms")
+	values.Add(HedgesKey, "2")
+	err := t.LookupByName(name).Signal(values)
+	h := t.LookupByName(name).Hedge()
+	fmt.Printf("test: Signal(delay,hedges) -> [error:%v] [delay:%v] [hedges:%v]\n", err, h.Delay(), h.MaxHedges())
+
+	err = h.Signal(NewValues(HedgesKey, "0"))
+	fmt.Printf("test: Signal(hedges=0) -> [error:%v]\n", err)
+
+	h.Signal(enableValues(false))
+	fmt.Printf("test: Disable() -> [enabled:%v]\n", t.LookupByName(name).Hedge().IsEnabled())
+
+	err = t.LookupByName(name).Hedge().Signal(url.Values{EnabledKey: {TrueValue}, HedgesKey: {"3"}})
+	h = t.LookupByName(name).Hedge()
+	fmt.Printf("test: Signal(enabled,hedges) -> [error:%v] [enabled:%v] [hedges:%v]\n", err, h.IsEnabled(), h.MaxHedges())
+
+	errs = newTable(false, false).AddController(newRoute("ingress-route", NewHedgeConfig(true, time.Millisecond*50, 1, nil)))
+	fmt.Printf("test: Add(ingress) -> %v\n", errs)
+
+	//Output:
+	//test: Add() -> [[]] [count:1]
+	//test: Signal(delay,hedges) -> [error:<nil>] [delay:10ms] [hedges:2]
+	//test: Signal(hedges=0) -> [error:invalid argument: hedges value is <= 0 [0]]
+	//test: Disable() -> [enabled:false]
+	//test: Signal(enabled,hedges) -> [error:<nil>] [enabled:true] [hedges:3]
+	//test: Add(ingress) -> [invalid configuration: Hedge is not valid for ingress traffic]
+
+}
@@ -0,0 +1,153 @@
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+const (
+	DefaultHistorySize = 100
+)
+
+// Change - a configuration change made by an actuator signal or a rollback. The old and new values are the
+// behavior configurations before and after the change.
+type Change struct {
+	Time     time.Time
+	Route    string
+	Behavior string
+	Caller   string
+	Old      any
+	New      any
+	Rollback bool // the change is a rollback of a previous change
+	Expired  bool // the change reverts an expired override
+
+	rolledBack bool
+	prev       *controller
+}
+
+// history - bounded configuration change history, caller must synchronize access
+type history struct {
+	size    int
+	changes []Change
+}
+
+func newHistory(size int) *history {
+	if size <= 0 {
+		size = DefaultHistorySize
+	}
+	return &history{size: size}
+}
+
+func (h *history) add(change Change) {
+	if len(h.changes) == h.size {
+		h.changes = append(h.changes[:0], h.changes[1:]...)
+	}
+	h.changes = append(h.changes, change)
+}
+
+func (h *history) remove(name string) {
+	changes := h.changes[:0]
+	for _, c := range h.changes {
+		if c.Route != name {
+			changes = append(changes, c)
+		}
+	}
+	h.changes = changes
+}
+
+func (h *history) list(name string) []Change {
+	var changes []Change
+	for _, c := range h.changes {
+		if name == "" || c.Route == name {
+			changes = append(changes, c)
+		}
+	}
+	return changes
+}
+
+// last - the most recent change to the route that has not been rolled back
+func (h *history) last(name string) int {
+	for i := len(h.changes) - 1; i >= 0; i-- {
+		if c := h.changes[i]; c.Route == name && c.prev != nil && !c.rolledBack {
+			return i
+		}
+	}
+	return -1
+}
+
+func behaviorSnapshot(ctrl *controller, behavior string) any {
+	config, err := ctrl.Snapshot().Behavior(behavior)
+	if err != nil {
+		return nil
+	}
+	return config
+}
+
+// record - add a change if the route controller was replaced since the previous controller, and schedule a revert
+// if the change has a ttl
+func (t *table) record(prev *controller, behavior, caller string, ttl time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	curr, ok := t.controllers[prev.name]
+	if !ok || curr == prev {
+		return
+	}
+	t.history.add(Change{Time: time.Now().UTC(), Route: prev.name, Behavior: behavior, Caller: caller, Old: behaviorSnapshot(prev, behavior),
+		New: behaviorSnapshot(curr, behavior), prev: prev})
+	if ttl > 0 {
+		t.override(prev, behavior, caller, ttl)
+	} else {
+		t.cancelOverride(prev.name, behavior)
+	}
+}
+
+func (t *table) current(name string) *controller {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.controllers[name]
+}
+
+// History - the configuration changes for a route, or all routes if the name is empty, oldest first
+func (t *table) History(name string) []Change {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.history.list(name)
+}
+
+// LastChange - the most recent change to a route that has not been rolled back, which is the change a rollback restores
+func (t *table) LastChange(name string) (Change, bool) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	i := t.history.last(name)
+	if i < 0 {
+		return Change{}, false
+	}
+	return t.history.changes[i], true
+}
+
+// Rollback - restore the route controller that was replaced by the most recent change, successive rollbacks
+// restore earlier controllers
+func (t *table) Rollback(name, caller string) error {
+	t.signalMu.Lock()
+	defer t.signalMu.Unlock()
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	curr, ok := t.controllers[name]
+	if !ok {
+		return errors.New(fmt.Sprintf("invalid argument: route [%v] not found", name))
+	}
+	i := t.history.last(name)
+	if i < 0 {
+		return errors.New(fmt.Sprintf("invalid argument: route has no changes to rollback [%v]", name))
+	}
+	change := &t.history.changes[i]
+	change.rolledBack = true
+	t.update(name, change.prev)
+	change.prev.rateLimiter.syncKeys()
+	change.prev.bulkhead.syncSlots()
+	t.cancelOverride(name, change.Behavior)
+	t.history.add(Change{Time: time.Now().UTC(), Route: name, Behavior: change.Behavior, Caller: caller, Old: behaviorSnapshot(curr, change.Behavior),
+		New: behaviorSnapshot(change.prev, change.Behavior), Rollback: true})
+	return nil
+}
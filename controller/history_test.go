@@ -0,0 +1,96 @@
+package controller
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"strconv"
+	"sync"
+	"time"
+)
+
+func ExampleTable_Rollback() {
+	t := newTable(true, false)
+	t.AddController(NewRoute("timeout-route", EgressTraffic, "", false, NewTimeoutConfig(true, 504, time.Millisecond*500)))
+	ctrl := t.LookupByName("timeout-route")
+
+	values := url.Values{BehaviorKey: {TimeoutBehavior}, CallerKey: {"admin"}, DurationKey: {"2s"}}
+	err := ctrl.Signal(values)
+	values = url.Values{BehaviorKey: {TimeoutBehavior}, CallerKey: {"admin"}, EnabledKey: {FalseValue}}
+	err1 := t.LookupByName("timeout-route").Signal(values)
+	for _, c := range t.History("timeout-route") {
+		fmt.Printf("test: Signal() -> [err:%v] [err1:%v] [route:%v] [behavior:%v] [caller:%v] [old:%v] [new:%v]\n", err, err1, c.Route, c.Behavior, c.Caller, c.Old, c.New)
+	}
+
+	err = t.Rollback("timeout-route", "operator")
+	timeout := t.LookupByName("timeout-route").Timeout()
+	fmt.Printf("test: Rollback() -> [err:%v] [enabled:%v] [duration:%v]\n", err, timeout.IsEnabled(), timeout.Duration())
+
+	err = t.Rollback("timeout-route", "operator")
+	timeout = t.LookupByName("timeout-route").Timeout()
+	fmt.Printf("test: Rollback() -> [err:%v] [enabled:%v] [duration:%v]\n", err, timeout.IsEnabled(), timeout.Duration())
+
+	err = t.Rollback("timeout-route", "operator")
+	changes := t.History("")
+	fmt.Printf("test: Rollback(none) -> [err:%v] [changes:%v] [rollback:%v] [caller:%v]\n", err, len(changes), changes[len(changes)-1].Rollback, changes[len(changes)-1].Caller)
+
+	//Output:
+	//test: Signal() -> [err:<nil>] [err1:<nil>] [route:timeout-route] [behavior:timeout] [caller:admin] [old:&{true 504 500ms false}] [new:&{true 504 2s false}]
+	//test: Signal() -> [err:<nil>] [err1:<nil>] [route:timeout-route] [behavior:timeout] [caller:admin] [old:&{true 504 2s false}] [new:&{false 504 2s false}]
+	//test: Rollback() -> [err:<nil>] [enabled:true] [duration:2s]
+	//test: Rollback() -> [err:<nil>] [enabled:true] [duration:500ms]
+	//test: Rollback(none) -> [err:invalid argument: route has no changes to rollback [timeout-route]] [changes:4] [rollback:true] [caller:operator]
+
+}
+
+func Example_history() {
+	h := newHistory(2)
+	h.add(Change{Route: "route-1"})
+	h.add(Change{Route: "route-2"})
+	h.add(Change{Route: "route-1", Behavior: TimeoutBehavior, prev: newDefaultController("route-1")})
+	fmt.Printf("test: add() -> [changes:%v] [route-1:%v] [last:%v]\n", len(h.list("")), len(h.list("route-1")), h.last("route-1"))
+
+	h.remove("route-1")
+	fmt.Printf("test: remove() -> [changes:%v] [last:%v]\n", len(h.list("")), h.last("route-1"))
+
+	//Output:
+	//test: add() -> [changes:2] [route-1:1] [last:1]
+	//test: remove() -> [changes:1] [last:-1]
+
+}
+
+func ExampleTable_History_Concurrent() {
+	t := newTable(true, false)
+	t.AddController(NewRoute("timeout-route", EgressTraffic, "", false, NewTimeoutConfig(true, 504, time.Millisecond*500)))
+	ctrl := t.LookupByName("timeout-route")
+
+	var wg sync.WaitGroup
+	start := make(chan struct{})
+	for i := 1; i <= 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			<-start
+			ctrl.Signal(url.Values{BehaviorKey: {TimeoutBehavior}, CallerKey: {strconv.Itoa(i)}, DurationKey: {strconv.Itoa(i) + "s"}})
+		}(i)
+	}
+	close(start)
+	wg.Wait()
+
+	// Each change starts from the previous change, and the caller made the new value
+	changes := t.History("timeout-route")
+	consistent := true
+	for i, c := range changes {
+		if i > 0 && !reflect.DeepEqual(c.Old, changes[i-1].New) {
+			consistent = false
+		}
+		if config, ok := c.New.(*TimeoutConfigJson); !ok || config.Duration != c.Caller+"s" {
+			consistent = false
+		}
+	}
+	fmt.Printf("test: Signal() -> [changes:%v] [consistent:%v]\n", len(changes), consistent)
+
+	//Output:
+	//test: Signal() -> [changes:50] [consistent:true]
+
+}
@@ -0,0 +1,201 @@
+package controller
+
+import (
+	"container/list"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"golang.org/x/time/rate"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	ForwardedForHeaderName = "X-Forwarded-For"
+
+	DefaultMaxKeys        = 10000
+	DefaultKeyIdleTimeout = time.Minute * 10
+)
+
+type keyEntry struct {
+	key      string
+	limiter  *rate.Limiter
+	lastUsed time.Time
+}
+
+// keyLimiters - a bounded set of per key rate limiters, the least recently used key is evicted when the set
+// is full, and keys that have been idle are expired
+type keyLimiters struct {
+	mu      sync.Mutex
+	limit   rate.Limit
+	burst   int
+	maxKeys int
+	idle    time.Duration
+	order   *list.List
+	keys    map[string]*list.Element
+}
+
+func newKeyLimiters(limit rate.Limit, burst, maxKeys int, idle time.Duration) *keyLimiters {
+	k := new(keyLimiters)
+	if maxKeys <= 0 {
+		maxKeys = DefaultMaxKeys
+	}
+	if idle <= 0 {
+		idle = DefaultKeyIdleTimeout
+	}
+	k.limit = limit
+	k.burst = burst
+	k.maxKeys = maxKeys
+	k.idle = idle
+	k.order = list.New()
+	k.keys = make(map[string]*list.Element, 100)
+	return k
+}
+
+// get - return the limiter for a key, creating it if needed
+func (k *keyLimiters) get(key string) *rate.Limiter {
+	now := time.Now()
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	k.expire(now)
+	if e, ok := k.keys[key]; ok {
+		entry := e.Value.(*keyEntry)
+		entry.lastUsed = now
+		k.order.MoveToFront(e)
+		return entry.limiter
+	}
+	if k.order.Len() >= k.maxKeys {
+		k.remove(k.order.Back())
+	}
+	entry := &keyEntry{key: key, limiter: rate.NewLimiter(k.limit, k.burst), lastUsed: now}
+	k.keys[key] = k.order.PushFront(entry)
+	return entry.limiter
+}
+
+// expire - remove idle keys, caller must hold the lock
+func (k *keyLimiters) expire(now time.Time) {
+	for e := k.order.Back(); e != nil; e = k.order.Back() {
+		if now.Sub(e.Value.(*keyEntry).lastUsed) < k.idle {
+			return
+		}
+		k.remove(e)
+	}
+}
+
+func (k *keyLimiters) remove(e *list.Element) {
+	if e == nil {
+		return
+	}
+	k.order.Remove(e)
+	delete(k.keys, e.Value.(*keyEntry).key)
+}
+
+// set - update the limit and burst of the existing keys in place, and of new keys
+func (k *keyLimiters) set(limit rate.Limit, burst int) {
+	if k == nil {
+		return
+	}
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if k.limit == limit && k.burst == burst {
+		return
+	}
+	k.limit = limit
+	k.burst = burst
+	for e := k.order.Front(); e != nil; e = e.Next() {
+		entry := e.Value.(*keyEntry)
+		entry.limiter.SetLimit(limit)
+		entry.limiter.SetBurst(burst)
+	}
+}
+
+func (k *keyLimiters) count() int {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	return k.order.Len()
+}
+
+// ClientIP - the client IP address of a request. The remote address is used unless it is a trusted proxy, in which
+// case the X-Forwarded-For header is read from the right, and the first address that is not a trusted proxy is used.
+// Addresses to the left of that are set by the client, and are not used
+func ClientIP(req *http.Request, trustedProxies []*net.IPNet) string {
+	if req == nil {
+		return ""
+	}
+	ip, _, err := net.SplitHostPort(req.RemoteAddr)
+	if err != nil {
+		ip = req.RemoteAddr
+	}
+	if !isTrustedProxy(ip, trustedProxies) {
+		return ip
+	}
+	if fwd := req.Header.Get(ForwardedForHeaderName); fwd != "" {
+		hops := strings.Split(fwd, ",")
+		for i := len(hops) - 1; i >= 0; i-- {
+			ip = strings.TrimSpace(hops[i])
+			if !isTrustedProxy(ip, trustedProxies) {
+				break
+			}
+		}
+	}
+	return ip
+}
+
+func isTrustedProxy(addr string, trustedProxies []*net.IPNet) bool {
+	ip := net.ParseIP(addr)
+	if ip == nil {
+		return false
+	}
+	for _, n := range trustedProxies {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseTrustedProxies - parse proxy addresses and CIDR ranges
+func parseTrustedProxies(proxies []string) ([]*net.IPNet, error) {
+	var nets []*net.IPNet
+	for _, p := range proxies {
+		if !strings.Contains(p, "/") {
+			if ip := net.ParseIP(p); ip != nil && ip.To4() != nil {
+				p += "/32"
+			} else {
+				p += "/128"
+			}
+		}
+		_, n, err := net.ParseCIDR(p)
+		if err != nil {
+			return nets, errors.New(fmt.Sprintf("invalid argument: trusted proxy is invalid [%v]", p))
+		}
+		nets = append(nets, n)
+	}
+	return nets, nil
+}
+
+// hashKeySalt - a random salt for each process, so a hashed key can not be recovered by hashing candidate keys such
+// as the IPv4 address space. Hashes are only comparable within a process
+var hashKeySalt = newHashKeySalt()
+
+func newHashKeySalt() []byte {
+	salt := make([]byte, 32)
+	rand.Read(salt)
+	return salt
+}
+
+// HashKey - a short salted hash of a rate limiter key, so keys such as client addresses are not logged
+func HashKey(key string) string {
+	if key == "" {
+		return ""
+	}
+	mac := hmac.New(sha256.New, hashKeySalt)
+	mac.Write([]byte(key))
+	return hex.EncodeToString(mac.Sum(nil)[:8])
+}
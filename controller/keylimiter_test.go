@@ -0,0 +1,120 @@
+package controller
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+func Example_keyLimiters() {
+	k := newKeyLimiters(1, 1, 2, time.Millisecond*50)
+	a := k.get("a")
+	k.get("b")
+	fmt.Printf("test: get() -> [count:%v] [same:%v]\n", k.count(), a == k.get("a"))
+
+	k.get("c")
+	fmt.Printf("test: get(evict) -> [count:%v] [b-evicted:%v]\n", k.count(), k.keys["b"] == nil)
+
+	time.Sleep(time.Millisecond * 60)
+	k.get("d")
+	fmt.Printf("test: get(expire) -> [count:%v]\n", k.count())
+
+	//Output:
+	//test: get() -> [count:2] [same:true]
+	//test: get(evict) -> [count:2] [b-evicted:true]
+	//test: get(expire) -> [count:1]
+
+}
+
+func ExampleRateLimiter_AllowKey() {
+	name := "test-route"
+	t := newTable(true, false)
+	config := NewRateLimiterConfig(true, 429, 1, 1, "")
+	config.KeyHeader = "X-Tenant"
+	errs := t.AddController(newRoute(name, config))
+	rl := t.LookupByName(name).RateLimiter()
+
+	req, _ := http.NewRequest("GET", "http://localhost:8080/search", nil)
+	req.Header.Set("X-Tenant", "tenant-1")
+	req2, _ := http.NewRequest("GET", "http://localhost:8080/search", nil)
+	req2.Header.Set("X-Tenant", "tenant-2")
+	fmt.Printf("test: AllowKey() -> [errs:%v] [keyed:%v] [tenant-1:%v] [tenant-1:%v] [tenant-2:%v]\n", errs, rl.IsKeyed(), rl.AllowKey(rl.Key(req), ""), rl.AllowKey(rl.Key(req), ""), rl.AllowKey(rl.Key(req2), ""))
+
+	ctrl := t.LookupByName(name).t()
+	fmt.Printf("test: rateLimiterKey() -> [limited:%v] [allowed:%v]\n", rateLimiterKey(ctrl.rateLimiter, req, RateLimitFlag), rateLimiterKey(ctrl.rateLimiter, req, ""))
+
+	// The existing keys are updated in place, so tenant-1 is still limited until a token is available at the new limit
+	rl.Signal(rateLimiterSetValues(100, 10))
+	rl = t.LookupByName(name).RateLimiter()
+	fmt.Printf("test: Signal() -> [limit:%v] [burst:%v] [keys:%v] [tenant-1:%v]\n", rl.Limit(), rl.Burst(), t.LookupByName(name).t().rateLimiter.keys.count(), rl.AllowKey(rl.Key(req), ""))
+	time.Sleep(time.Millisecond * 30)
+	fmt.Printf("test: Signal() -> [tenant-1:%v] [tenant-1:%v]\n", rl.AllowKey(rl.Key(req), ""), rl.AllowKey(rl.Key(req), ""))
+
+	config = NewRateLimiterConfig(true, 429, 1, 1, "")
+	config.KeyHeader = ForwardedForHeaderName
+	config.HashKey = true
+	r := newRateLimiter(name, nil, config)
+	req.RemoteAddr = "10.3.3.3:443"
+	req.Header.Set(ForwardedForHeaderName, "10.0.0.1, 10.1.1.1, 10.2.2.2")
+	fmt.Printf("test: Key(X-Forwarded-For) -> [key:%v]\n", r.Key(req))
+
+	config.TrustedProxies = []string{"10.3.3.0/24", "10.2.2.2"}
+	r = newRateLimiter(name, nil, config)
+	fmt.Printf("test: Key(X-Forwarded-For) -> [key:%v] [hash:%v]\n", r.Key(req), rateLimiterKey(r, req, RateLimitFlag) == HashKey("10.1.1.1"))
+
+	config.TrustedProxies = []string{"10.3.3"}
+	fmt.Printf("test: validate() -> [%v]\n", newRateLimiter(name, nil, config).validate())
+
+	//Output:
+	//test: AllowKey() -> [errs:[]] [keyed:true] [tenant-1:true] [tenant-1:false] [tenant-2:true]
+	//test: rateLimiterKey() -> [limited:tenant-1] [allowed:]
+	//test: Signal() -> [limit:100] [burst:10] [keys:2] [tenant-1:false]
+	//test: Signal() -> [tenant-1:true] [tenant-1:true]
+	//test: Key(X-Forwarded-For) -> [key:10.3.3.3]
+	//test: Key(X-Forwarded-For) -> [key:10.1.1.1] [hash:true]
+	//test: validate() -> [invalid configuration: RateLimiter invalid argument: trusted proxy is invalid [10.3.3/128] [test-route]]
+
+}
+
+func Example_keyLimiters_set() {
+	k := newKeyLimiters(1, 1, 10, time.Minute)
+	a := k.get("a")
+	a.Allow()
+	k.set(10, 5)
+	b := k.get("b")
+	fmt.Printf("test: set() -> [count:%v] [same:%v] [a:%v,%v] [b:%v,%v]\n", k.count(), a == k.get("a"), a.Limit(), a.Burst(), b.Limit(), b.Burst())
+
+	var nilKeys *keyLimiters
+	nilKeys.set(10, 5)
+
+	//Output:
+	//test: set() -> [count:2] [same:true] [a:10,5] [b:10,5]
+
+}
+
+func ExampleRateLimiter_AllowKey_Override() {
+	name := "keyed-route"
+	t := newTable(true, false)
+	config := NewRateLimiterConfig(true, 429, 1, 1, "")
+	config.KeyHeader = "X-Tenant"
+	t.AddController(newRoute(name, config))
+	keys := t.LookupByName(name).t().rateLimiter.keys
+	keys.get("tenant-1")
+
+	// The override and the revert update the existing keys in place
+	err := t.LookupByName(name).Signal(url.Values{BehaviorKey: {RateLimitBehavior}, RateLimitKey: {"100"}, RateBurstKey: {"10"}, TTLKey: {"50ms"}})
+	limiter := keys.get("tenant-1")
+	fmt.Printf("test: Signal(ttl) -> [err:%v] [same:%v] [keys:%v] [tenant-1:%v,%v]\n", err, t.LookupByName(name).t().rateLimiter.keys == keys, keys.count(),
+		limiter.Limit(), limiter.Burst())
+
+	time.Sleep(time.Millisecond * 100)
+	fmt.Printf("test: Overrides(expired) -> [same:%v] [keys:%v] [tenant-1:%v,%v]\n", t.LookupByName(name).t().rateLimiter.keys == keys, keys.count(),
+		limiter.Limit(), limiter.Burst())
+
+	//Output:
+	//test: Signal(ttl) -> [err:<nil>] [same:true] [keys:1] [tenant-1:100,10]
+	//{"route":"keyed-route", "behavior":"rate-limit", "caller":"", "revert":"override expired"}
+	//test: Overrides(expired) -> [same:true] [keys:1] [tenant-1:1,1]
+
+}
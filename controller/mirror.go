@@ -0,0 +1,231 @@
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+const (
+	DefaultMirrorBodySize = int64(64 * 1024)
+	DefaultMirrorTimeout  = time.Second * 5
+	DefaultMirrorInFlight = 100
+	MaxMirrorPercentage   = float64(100)
+)
+
+// Mirror - interface for request mirroring, a fire-and-forget copy of a sampled percentage of requests is sent
+// to the mirror pattern, and the mirror responses are discarded
+type Mirror interface {
+	State
+	Actuator
+	Pattern() string
+	Percentage() float64
+	Sample() bool
+	MaxBodySize() int64
+	Timeout() time.Duration
+	MaxInFlight() int
+	Acquire() bool
+	Release()
+	BuildUrl(uri *url.URL) *url.URL
+}
+
+type MirrorConfig struct {
+	Enabled     bool
+	Pattern     string
+	Percentage  float64       // percentage of requests mirrored, 0 - 100
+	MaxBodySize int64         // maximum request body buffered for the mirror, larger requests are not mirrored
+	Timeout     time.Duration // timeout of the mirror request, which is not bounded by the original request
+	MaxInFlight int           // maximum concurrent mirror requests, requests over the limit are not mirrored
+}
+
+var nilMirror = newMirror(NilBehaviorName, nil, NewMirrorConfig(false, "", 0))
+
+func NewMirrorConfig(enabled bool, pattern string, percentage float64) *MirrorConfig {
+	c := new(MirrorConfig)
+	c.Enabled = enabled
+	c.Pattern = pattern
+	c.Percentage = percentage
+	c.MaxBodySize = DefaultMirrorBodySize
+	c.Timeout = DefaultMirrorTimeout
+	c.MaxInFlight = DefaultMirrorInFlight
+	return c
+}
+
+type mirror struct {
+	name     string
+	table    *table
+	config   MirrorConfig
+	inFlight chan struct{} // shared by clones, so the limit applies across actuator changes
+}
+
+func cloneMirror(curr *mirror) *mirror {
+	t := new(mirror)
+	*t = *curr
+	return t
+}
+
+func newMirror(name string, table *table, config *MirrorConfig) *mirror {
+	t := new(mirror)
+	t.name = name
+	t.table = table
+	if config != nil {
+		t.config = *config
+	}
+	if t.config.MaxBodySize <= 0 {
+		t.config.MaxBodySize = DefaultMirrorBodySize
+	}
+	if t.config.Timeout <= 0 {
+		t.config.Timeout = DefaultMirrorTimeout
+	}
+	if t.config.MaxInFlight <= 0 {
+		t.config.MaxInFlight = DefaultMirrorInFlight
+	}
+	t.inFlight = make(chan struct{}, t.config.MaxInFlight)
+	return t
+}
+
+func (m *mirror) validate() error {
+	if len(m.config.Pattern) == 0 {
+		return errors.New(fmt.Sprintf("invalid configuration: Mirror pattern is empty [%v]", m.name))
+	}
+	if _, err := url.Parse(m.config.Pattern); err != nil {
+		return errors.New(fmt.Sprintf("invalid configuration: Mirror pattern is invalid [%v] [%v]", m.config.Pattern, m.name))
+	}
+	if m.config.Percentage <= 0 || m.config.Percentage > MaxMirrorPercentage {
+		return errors.New(fmt.Sprintf("invalid configuration: Mirror percentage is not in the range 0 - 100 [%v]", m.name))
+	}
+	return nil
+}
+
+func (m *mirror) IsEnabled() bool { return m.config.Enabled }
+
+func (m *mirror) IsNil() bool { return m.name == NilBehaviorName }
+
+func (m *mirror) Enable() {
+	if m.IsEnabled() {
+		return
+	}
+	m.enableMirror(true)
+}
+
+func (m *mirror) Disable() {
+	if !m.IsEnabled() {
+		return
+	}
+	m.enableMirror(false)
+}
+
+func (m *mirror) Signal(values url.Values) error {
+	if m.IsNil() {
+		return errors.New("invalid signal: mirror is not configured")
+	}
+	if values == nil {
+		return errors.New("invalid argument: values are nil for mirror signal")
+	}
+	UpdateEnable(m, values)
+	config := m.config
+	if values.Has(PatternKey) {
+		pattern := values.Get(PatternKey)
+		if len(pattern) == 0 {
+			return errors.New("invalid argument: mirror pattern is empty")
+		}
+		if _, err := url.Parse(pattern); err != nil {
+			return err
+		}
+		config.Pattern = pattern
+	}
+	if values.Has(SampleKey) {
+		pct, err := strconv.ParseFloat(values.Get(SampleKey), 64)
+		if err != nil {
+			return err
+		}
+		if pct <= 0 || pct > MaxMirrorPercentage {
+			return errors.New(fmt.Sprintf("invalid argument: sample percentage is not in the range 0 - 100 [%v]", values.Get(SampleKey)))
+		}
+		config.Percentage = pct
+	}
+	if config.Pattern != m.config.Pattern || config.Percentage != m.config.Percentage {
+		m.setMirror(config)
+	}
+	return nil
+}
+
+func (m *mirror) Pattern() string {
+	return m.config.Pattern
+}
+
+func (m *mirror) Percentage() float64 {
+	return m.config.Percentage
+}
+
+// Sample - determine if a request is mirrored
+func (m *mirror) Sample() bool {
+	if m.config.Percentage >= MaxMirrorPercentage {
+		return true
+	}
+	return rand.Float64()*MaxMirrorPercentage < m.config.Percentage
+}
+
+func (m *mirror) MaxBodySize() int64 {
+	return m.config.MaxBodySize
+}
+
+func (m *mirror) Timeout() time.Duration {
+	return m.config.Timeout
+}
+
+func (m *mirror) MaxInFlight() int {
+	return m.config.MaxInFlight
+}
+
+// Acquire - acquire an in flight mirror request without waiting, returns false if the maximum in flight mirror
+// requests are being sent. A successful Acquire must be paired with a call to Release
+func (m *mirror) Acquire() bool {
+	select {
+	case m.inFlight <- struct{}{}:
+		return true
+	default:
+		return false
+	}
+}
+
+func (m *mirror) Release() {
+	select {
+	case <-m.inFlight:
+	default:
+	}
+}
+
+func (m *mirror) BuildUrl(uri *url.URL) *url.URL {
+	return buildUrl(uri, m.config.Pattern)
+}
+
+func (m *mirror) enableMirror(enabled bool) {
+	if m.table == nil || m.IsNil() {
+		return
+	}
+	m.table.mu.Lock()
+	defer m.table.mu.Unlock()
+	if ctrl, ok := m.table.controllers[m.name]; ok {
+		c := cloneMirror(ctrl.mirror)
+		c.config.Enabled = enabled
+		m.table.update(m.name, cloneController[*mirror](ctrl, c))
+	}
+}
+
+func (m *mirror) setMirror(config MirrorConfig) {
+	if m.table == nil || m.IsNil() {
+		return
+	}
+	m.table.mu.Lock()
+	defer m.table.mu.Unlock()
+	if ctrl, ok := m.table.controllers[m.name]; ok {
+		c := cloneMirror(ctrl.mirror)
+		c.config.Pattern = config.Pattern
+		c.config.Percentage = config.Percentage
+		m.table.update(m.name, cloneController[*mirror](ctrl, c))
+	}
+}
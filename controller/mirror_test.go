@@ -0,0 +1,66 @@
+package controller
+
+import (
+	"fmt"
+	"net/url"
+)
+
+func Example_newMirror() {
+	m := newMirror("test-route", newTable(true, false), NewMirrorConfig(true, "http://localhost:8081", 100))
+	fmt.Printf("test: newMirror() -> [name:%v] [pattern:%v] [pct:%v] [max-body:%v] [sample:%v]\n", m.name, m.Pattern(), m.Percentage(), m.MaxBodySize(), m.Sample())
+
+	uri, _ := url.Parse("https://www.google.com/search?q=golang")
+	fmt.Printf("test: BuildUrl() -> [%v]\n", m.BuildUrl(uri))
+
+	m2 := cloneMirror(m)
+	m2.config.Percentage = 10
+	fmt.Printf("test: cloneMirror() -> [prev-pct:%v] [curr-pct:%v]\n", m.Percentage(), m2.Percentage())
+
+	fmt.Printf("test: validate() -> [%v]\n", newMirror("test-route", nil, NewMirrorConfig(true, "", 10)).validate())
+	fmt.Printf("test: validate() -> [%v]\n", newMirror("test-route", nil, NewMirrorConfig(true, "http://localhost:8081", 0)).validate())
+
+	//Output:
+	//test: newMirror() -> [name:test-route] [pattern:http://localhost:8081] [pct:100] [max-body:65536] [sample:true]
+	//test: BuildUrl() -> [http://localhost:8081/search?q=golang]
+	//test: cloneMirror() -> [prev-pct:100] [curr-pct:10]
+	//test: validate() -> [invalid configuration: Mirror pattern is empty [test-route]]
+	//test: validate() -> [invalid configuration: Mirror percentage is not in the range 0 - 100 [test-route]]
+
+}
+
+func ExampleMirror_Signal() {
+	name := "test-route"
+	t := newTable(true, false)
+	errs := t.AddController(newRoute(name, NewMirrorConfig(true, "http://localhost:8081", 5)))
+	fmt.Printf("test: Add() -> [%v] [count:%v]\n", errs, t.count())
+
+	values := make(url.Values)
+	values.Add(BehaviorKey, MirrorBehavior)
+	values.Add(PatternKey, "http://localhost:8082")
+	values.Add(SampleKey, "25")
+	err := t.LookupByName(name).Signal(values)
+	m := t.LookupByName(name).Mirror()
+	fmt.Printf("test: Signal(pattern,sample) -> [error:%v] [pattern:%v] [pct:%v]\n", err, m.Pattern(), m.Percentage())
+
+	err = m.Signal(NewValues(SampleKey, "101"))
+	fmt.Printf("test: Signal(sample=101) -> [error:%v]\n", err)
+
+	m.Signal(enableValues(false))
+	fmt.Printf("test: Disable() -> [enabled:%v]\n", t.LookupByName(name).Mirror().IsEnabled())
+
+	err = t.LookupByName(name).Mirror().Signal(url.Values{EnabledKey: {TrueValue}, SampleKey: {"50"}})
+	m = t.LookupByName(name).Mirror()
+	fmt.Printf("test: Signal(enabled,sample) -> [error:%v] [enabled:%v] [pct:%v]\n", err, m.IsEnabled(), m.Percentage())
+
+	errs = newTable(false, false).AddController(newRoute("ingress-route", NewMirrorConfig(true, "http://localhost:8081", 5)))
+	fmt.Printf("test: Add(ingress) -> %v\n", errs)
+
+	//Output:
+	//test: Add() -> [[]] [count:1]
+	//test: Signal(pattern,sample) -> [error:<nil>] [pattern:http://localhost:8082] [pct:25]
+	//test: Signal(sample=101) -> [error:invalid argument: sample percentage is not in the range 0 - 100 [101]]
+	//test: Disable() -> [enabled:false]
+	//test: Signal(enabled,sample) -> [error:<nil>] [enabled:true] [pct:50]
+	//test: Add(ingress) -> [invalid configuration: Mirror is not valid for ingress traffic]
+
+}
@@ -13,8 +13,14 @@ type HttpMatcher func(req *http.Request) (routeName string, ok bool)
 // UriMatcher - type for Ingress/Egress table lookups by uri
 type UriMatcher func(uri string, method string) (routeName string, ok bool)
 
+// KeyExtractor - type for extracting a rate limiter key from a request
+type KeyExtractor func(req *http.Request) string
+
+// PriorityMatcher - type for Ingress request priority classification
+type PriorityMatcher func(req *http.Request) (priority string, ok bool)
+
 // OutputHandler - type for output handling
-type OutputHandler func(traffic string, start time.Time, duration time.Duration, req *http.Request, resp *http.Response, routeName string, timeout int, rateLimit rate.Limit, rateBurst int, rateThreshold, retry, proxy, proxyThreshold, statusFlags string)
+type OutputHandler func(traffic string, start time.Time, duration time.Duration, req *http.Request, resp *http.Response, routeName, priority string, timeout int, rateLimit rate.Limit, rateBurst int, rateThreshold, rateKey, retry string, attempt int, proxy, proxyThreshold, statusFlags string)
 
 // SetLogFn - configuration for logging function
 func SetLogFn(fn OutputHandler) {
@@ -23,11 +29,55 @@ func SetLogFn(fn OutputHandler) {
 	}
 }
 
-var defaultLogFn = func(traffic string, start time.Time, duration time.Duration, req *http.Request, resp *http.Response, routeName string, timeout int, rateLimit rate.Limit, rateBurst int, rateThreshold, retry, proxy, proxyThreshold, statusFlags string) {
-	s := FmtLog(traffic, start, duration, req, resp, routeName, timeout, rateLimit, rateBurst, rateThreshold, retry, proxy, proxyThreshold, statusFlags)
+var defaultLogFn = func(traffic string, start time.Time, duration time.Duration, req *http.Request, resp *http.Response, routeName, priority string, timeout int, rateLimit rate.Limit, rateBurst int, rateThreshold, rateKey, retry string, attempt int, proxy, proxyThreshold, statusFlags string) {
+	s := FmtLog(traffic, start, duration, req, resp, routeName, priority, timeout, rateLimit, rateBurst, rateThreshold, rateKey, retry, attempt, proxy, proxyThreshold, statusFlags)
 	fmt.Printf("{%v}\n", s)
 }
 
+// SetRevertFn - configuration for logging reverts of expired actuator overrides
+func SetRevertFn(fn func(change Change)) {
+	if fn != nil {
+		defaultRevertFn = fn
+	}
+}
+
+var defaultRevertFn = func(change Change) {
+	fmt.Printf("{\"route\":\"%v\", \"behavior\":\"%v\", \"caller\":\"%v\", \"revert\":\"override expired\"}\n", change.Route, change.Behavior, change.Caller)
+}
+
+// SetHealthFn - configuration for logging health check transitions
+func SetHealthFn(fn func(route, target string, healthy bool)) {
+	if fn != nil {
+		defaultHealthFn = fn
+	}
+}
+
+var defaultHealthFn = func(route, target string, healthy bool) {
+	fmt.Printf("{\"route\":\"%v\", \"target\":\"%v\", \"healthy\":%v}\n", route, target, healthy)
+}
+
+// SetEjectFn - configuration for logging pool endpoint ejections
+func SetEjectFn(fn func(route, host string, duration time.Duration)) {
+	if fn != nil {
+		defaultEjectFn = fn
+	}
+}
+
+var defaultEjectFn = func(route, host string, duration time.Duration) {
+	fmt.Printf("{\"route\":\"%v\", \"endpoint\":\"%v\", \"ejection\":\"%v\"}\n", route, host, duration)
+}
+
+// SetFailoverFn - configuration for logging proxy failover transitions
+func SetFailoverFn(fn func(route, state string)) {
+	if fn != nil {
+		defaultFailoverFn = fn
+	}
+}
+
+var defaultFailoverFn = func(route, state string) {
+	fmt.Printf("{\"route\":\"%v\", \"proxy\":\"%v\", \"transition\":\"failover state changed\"}\n", route, state)
+}
+
 // SetExtractFn - configuration for connector function
 func SetExtractFn(fn OutputHandler) {
 	if fn != nil {
@@ -0,0 +1,150 @@
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"time"
+)
+
+// Override - a time-boxed behavior change, the previous behavior configuration is restored when the override expires
+type Override struct {
+	Route     string
+	Behavior  string
+	Caller    string
+	Expires   time.Time
+	Remaining time.Duration
+
+	prev  *controller
+	timer *time.Timer
+}
+
+// ParseTTL - parse the override time to live, returns 0 if there is no ttl
+func ParseTTL(values url.Values) (time.Duration, error) {
+	if values == nil || !values.Has(TTLKey) {
+		return 0, nil
+	}
+	ttl, err := ParseDuration(values.Get(TTLKey))
+	if err != nil {
+		return 0, err
+	}
+	if ttl <= 0 {
+		return 0, errors.New(fmt.Sprintf("invalid argument: ttl value is <= 0 [%v]", values.Get(TTLKey)))
+	}
+	return ttl, nil
+}
+
+func overrideKey(name, behavior string) string {
+	return name + "/" + behavior
+}
+
+// override - schedule a revert of the behavior, caller must hold the table lock. A later override of the same
+// behavior extends the expiry, but still reverts to the configuration before the first override.
+func (t *table) override(prev *controller, behavior, caller string, ttl time.Duration) {
+	key := overrideKey(prev.name, behavior)
+	o := &Override{Route: prev.name, Behavior: behavior, Caller: caller, Expires: time.Now().Add(ttl), prev: prev}
+	if curr, ok := t.overrides[key]; ok {
+		curr.timer.Stop()
+		o.prev = curr.prev
+	}
+	o.timer = time.AfterFunc(ttl, func() { t.revert(key, o) })
+	t.overrides[key] = o
+}
+
+// cancelOverride - a change without a ttl makes the behavior change permanent, caller must hold the table lock
+func (t *table) cancelOverride(name, behavior string) {
+	key := overrideKey(name, behavior)
+	if o, ok := t.overrides[key]; ok {
+		o.timer.Stop()
+		delete(t.overrides, key)
+	}
+}
+
+// removeOverrides - cancel all overrides for a route, caller must hold the table lock
+func (t *table) removeOverrides(name string) {
+	for key, o := range t.overrides {
+		if o.Route == name {
+			o.timer.Stop()
+			delete(t.overrides, key)
+		}
+	}
+}
+
+func (t *table) revert(key string, o *Override) {
+	if change, ok := t.revertOverride(key, o); ok {
+		defaultRevertFn(change)
+	}
+}
+
+// revertOverride - restore the behavior of an expired override, and record the change
+func (t *table) revertOverride(key string, o *Override) (Change, bool) {
+	t.signalMu.Lock()
+	defer t.signalMu.Unlock()
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.overrides[key] != o {
+		return Change{}, false
+	}
+	delete(t.overrides, key)
+	curr, ok := t.controllers[o.Route]
+	if !ok {
+		return Change{}, false
+	}
+	ctrl := restoreBehavior(curr, o.prev, o.Behavior)
+	t.update(o.Route, ctrl)
+	change := Change{Time: time.Now().UTC(), Route: o.Route, Behavior: o.Behavior, Caller: o.Caller, Old: behaviorSnapshot(curr, o.Behavior),
+		New: behaviorSnapshot(ctrl, o.Behavior), Expired: true}
+	t.history.add(change)
+	return change, true
+}
+
+// restoreBehavior - replace the current behavior with the behavior from the previous controller
+func restoreBehavior(curr, prev *controller, behavior string) *controller {
+	switch behavior {
+	case TimeoutBehavior:
+		return cloneController[*timeout](curr, prev.timeout)
+	case RateLimitBehavior:
+		prev.rateLimiter.syncKeys()
+		return cloneController[*rateLimiter](curr, prev.rateLimiter)
+	case RetryBehavior:
+		return cloneController[*retry](curr, prev.retry)
+	case ProxyBehavior:
+		return cloneController[*proxy](curr, prev.proxy)
+	case CircuitBreakerBehavior:
+		return cloneController[*circuitBreaker](curr, prev.circuitBreaker)
+	case BulkheadBehavior:
+		prev.bulkhead.syncSlots()
+		return cloneController[*bulkhead](curr, prev.bulkhead)
+	case HedgeBehavior:
+		return cloneController[*hedge](curr, prev.hedge)
+	case MirrorBehavior:
+		return cloneController[*mirror](curr, prev.mirror)
+	case PoolBehavior:
+		return cloneController[*pool](curr, prev.pool)
+	case HealthCheckBehavior:
+		return cloneController[*healthCheck](curr, prev.healthCheck)
+	}
+	return curr
+}
+
+// Overrides - the active overrides for a route, or all routes if the name is empty, ordered by expiry
+func (t *table) Overrides(name string) []Override {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	var overrides []Override
+	now := time.Now()
+	for _, o := range t.overrides {
+		if name == "" || o.Route == name {
+			o1 := *o
+			o1.Remaining = o.Expires.Sub(now)
+			if o1.Remaining < 0 {
+				o1.Remaining = 0
+			}
+			o1.prev, o1.timer = nil, nil
+			overrides = append(overrides, o1)
+		}
+	}
+	sort.Slice(overrides, func(i, j int) bool { return overrides[i].Expires.Before(overrides[j].Expires) })
+	return overrides
+}
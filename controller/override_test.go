@@ -0,0 +1,60 @@
+package controller
+
+import (
+	"fmt"
+	"net/url"
+	"time"
+)
+
+func ExampleParseTTL() {
+	ttl, err := ParseTTL(url.Values{})
+	fmt.Printf("test: ParseTTL(none) -> [ttl:%v] [err:%v]\n", ttl, err)
+
+	ttl, err = ParseTTL(url.Values{TTLKey: {"15m"}})
+	fmt.Printf("test: ParseTTL(15m) -> [ttl:%v] [err:%v]\n", ttl, err)
+
+	ttl, err = ParseTTL(url.Values{TTLKey: {"0"}})
+	fmt.Printf("test: ParseTTL(0) -> [ttl:%v] [err:%v]\n", ttl, err)
+
+	//Output:
+	//test: ParseTTL(none) -> [ttl:0s] [err:<nil>]
+	//test: ParseTTL(15m) -> [ttl:15m0s] [err:<nil>]
+	//test: ParseTTL(0) -> [ttl:0s] [err:invalid argument: ttl value is <= 0 [0]]
+
+}
+
+func ExampleTable_Overrides() {
+	t := newTable(true, false)
+	t.AddController(NewRoute("limit-route", EgressTraffic, "", false, NewRateLimiterConfig(true, 503, 100, 10, ""),
+		NewTimeoutConfig(true, 504, time.Second)))
+
+	values := url.Values{BehaviorKey: {RateLimitBehavior}, CallerKey: {"on-call"}, RateLimitKey: {"10"}, TTLKey: {"50ms"}}
+	err := t.LookupByName("limit-route").Signal(values)
+	values = url.Values{BehaviorKey: {TimeoutBehavior}, CallerKey: {"on-call"}, DurationKey: {"5s"}}
+	err1 := t.LookupByName("limit-route").Signal(values)
+	values = url.Values{BehaviorKey: {RateLimitBehavior}, CallerKey: {"on-call"}, RateLimitKey: {"5"}, TTLKey: {"100ms"}}
+	err2 := t.LookupByName("limit-route").Signal(values)
+
+	overrides := t.Overrides("")
+	ctrl := t.LookupByName("limit-route")
+	fmt.Printf("test: Signal(ttl) -> [err:%v] [err1:%v] [err2:%v] [overrides:%v] [behavior:%v] [remaining:%v] [limit:%v] [timeout:%v]\n", err, err1, err2, len(overrides),
+		overrides[0].Behavior, overrides[0].Remaining > time.Millisecond*50, ctrl.RateLimiter().Limit(), ctrl.Timeout().Duration())
+
+	time.Sleep(time.Millisecond * 150)
+	ctrl = t.LookupByName("limit-route")
+	changes := t.History("limit-route")
+	fmt.Printf("test: Overrides(expired) -> [overrides:%v] [limit:%v] [timeout:%v] [expired:%v]\n", len(t.Overrides("")), ctrl.RateLimiter().Limit(), ctrl.Timeout().Duration(), changes[len(changes)-1].Expired)
+
+	values = url.Values{BehaviorKey: {RateLimitBehavior}, RateLimitKey: {"10"}, TTLKey: {"1s"}}
+	t.LookupByName("limit-route").Signal(values)
+	values = url.Values{BehaviorKey: {RateLimitBehavior}, RateLimitKey: {"20"}}
+	t.LookupByName("limit-route").Signal(values)
+	fmt.Printf("test: Overrides(permanent) -> [overrides:%v] [limit:%v]\n", len(t.Overrides("")), t.LookupByName("limit-route").RateLimiter().Limit())
+
+	//Output:
+	//test: Signal(ttl) -> [err:<nil>] [err1:<nil>] [err2:<nil>] [overrides:1] [behavior:rate-limit] [remaining:true] [limit:5] [timeout:5s]
+	//{"route":"limit-route", "behavior":"rate-limit", "caller":"on-call", "revert":"override expired"}
+	//test: Overrides(expired) -> [overrides:0] [limit:100] [timeout:5s] [expired:true]
+	//test: Overrides(permanent) -> [overrides:0] [limit:20]
+
+}
@@ -0,0 +1,256 @@
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+)
+
+// Route patterns are matched by a trie of path segments per host. A pattern has the form :
+//
+//	[METHOD[,METHOD...] ][host][/path]
+//
+// An empty or "*" host matches any host, a "*." host prefix matches any subdomain, the path is matched as a prefix
+// on segment boundaries, and a "*" segment matches any single segment. For example
+// "GET,HEAD api.example.com/v1/users/*/orders" or "*.example.com/v1".
+// The most specific pattern wins : an exact host over a subdomain wildcard, a longer subdomain wildcard over a
+// shorter one, and any host last, then a longer path over a shorter path, and a literal segment over a wildcard.
+//
+// Route patterns are matched by default, so a Route.Pattern that was ignored before pattern matching was added
+// now routes traffic. Clear the pattern of routes that should only be selected by name, or set a custom matcher,
+// which overrides pattern matching.
+
+const (
+	WildcardSegment = "*"
+
+	wildcardHost = "*."
+)
+
+type patternRoute struct {
+	name    string
+	methods []string
+}
+
+type patternNode struct {
+	children map[string]*patternNode
+	wildcard *patternNode
+	routes   []patternRoute
+}
+
+func newPatternNode() *patternNode {
+	return &patternNode{children: make(map[string]*patternNode)}
+}
+
+// patternTrie - route pattern matcher, caller must synchronize access
+type patternTrie struct {
+	hosts map[string]*patternNode
+}
+
+func newPatternTrie() *patternTrie {
+	return &patternTrie{hosts: make(map[string]*patternNode)}
+}
+
+// ParsePattern - parse a route pattern into its methods, host and path segments
+func ParsePattern(pattern string) (methods []string, host string, segments []string, err error) {
+	pattern = strings.TrimSpace(pattern)
+	if pattern == "" {
+		return nil, "", nil, errors.New("invalid argument: pattern is empty")
+	}
+	if i := strings.IndexByte(pattern, ' '); i > 0 {
+		for _, m := range strings.Split(pattern[:i], ",") {
+			if m = strings.ToUpper(strings.TrimSpace(m)); m != "" {
+				methods = append(methods, m)
+			}
+		}
+		pattern = strings.TrimSpace(pattern[i+1:])
+	}
+	path := ""
+	if i := strings.IndexByte(pattern, '/'); i >= 0 {
+		host, path = pattern[:i], pattern[i:]
+	} else {
+		host = pattern
+	}
+	host = strings.ToLower(host)
+	if host == WildcardSegment {
+		host = ""
+	}
+	if strings.Contains(strings.TrimPrefix(host, wildcardHost), "*") || host == wildcardHost {
+		return nil, "", nil, errors.New(fmt.Sprintf("invalid argument: pattern host is invalid [%v]", host))
+	}
+	return methods, host, splitPath(path), nil
+}
+
+func splitPath(path string) []string {
+	var segments []string
+	for _, s := range strings.Split(path, "/") {
+		if s != "" {
+			segments = append(segments, s)
+		}
+	}
+	return segments
+}
+
+func (p *patternTrie) add(name, pattern string) error {
+	methods, host, segments, err := ParsePattern(pattern)
+	if err != nil {
+		return err
+	}
+	node, ok := p.hosts[host]
+	if !ok {
+		node = newPatternNode()
+		p.hosts[host] = node
+	}
+	for _, s := range segments {
+		if s == WildcardSegment {
+			if node.wildcard == nil {
+				node.wildcard = newPatternNode()
+			}
+			node = node.wildcard
+			continue
+		}
+		child, ok := node.children[s]
+		if !ok {
+			child = newPatternNode()
+			node.children[s] = child
+		}
+		node = child
+	}
+	for _, r := range node.routes {
+		if overlaps(r.methods, methods) {
+			return errors.New(fmt.Sprintf("invalid argument: route pattern is a duplicate of route [%v] [%v]", r.name, pattern))
+		}
+	}
+	node.routes = append(node.routes, patternRoute{name: name, methods: methods})
+	return nil
+}
+
+func (p *patternTrie) remove(name string) {
+	for _, node := range p.hosts {
+		node.remove(name)
+	}
+}
+
+func (n *patternNode) remove(name string) {
+	for i, r := range n.routes {
+		if r.name == name {
+			n.routes = append(n.routes[:i], n.routes[i+1:]...)
+			break
+		}
+	}
+	for _, child := range n.children {
+		child.remove(name)
+	}
+	if n.wildcard != nil {
+		n.wildcard.remove(name)
+	}
+}
+
+// match - return the name of the most specific route matching the host, path and method
+func (p *patternTrie) match(host, path, method string) (string, bool) {
+	if len(p.hosts) == 0 {
+		return "", false
+	}
+	segments := splitPath(path)
+	method = strings.ToUpper(method)
+	host = strings.ToLower(host)
+	candidates := []string{host}
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		candidates = append(candidates, h)
+		host = h
+	}
+	// Subdomain wildcards, from the longest to the shortest domain
+	for i := strings.IndexByte(host, '.'); i >= 0; {
+		candidates = append(candidates, wildcardHost+host[i+1:])
+		j := strings.IndexByte(host[i+1:], '.')
+		if j < 0 {
+			break
+		}
+		i += j + 1
+	}
+	candidates = append(candidates, "")
+	for _, h := range candidates {
+		if node, ok := p.hosts[h]; ok {
+			if name, depth := node.match(segments, method, 0); depth >= 0 {
+				return name, true
+			}
+		}
+	}
+	return "", false
+}
+
+// match - depth first search, literal segments are searched before wildcards, returns the deepest match
+func (n *patternNode) match(segments []string, method string, depth int) (string, int) {
+	name, matched := "", -1
+	if len(segments) > 0 {
+		if child, ok := n.children[segments[0]]; ok {
+			name, matched = child.match(segments[1:], method, depth+1)
+		}
+		if n.wildcard != nil {
+			if wname, wmatched := n.wildcard.match(segments[1:], method, depth+1); wmatched > matched {
+				name, matched = wname, wmatched
+			}
+		}
+	}
+	if matched >= 0 {
+		return name, matched
+	}
+	for _, r := range n.routes {
+		if allowsMethod(r.methods, method) {
+			return r.name, depth
+		}
+	}
+	return "", -1
+}
+
+func allowsMethod(methods []string, method string) bool {
+	if len(methods) == 0 {
+		return true
+	}
+	if method == "" {
+		method = http.MethodGet
+	}
+	for _, m := range methods {
+		if m == method {
+			return true
+		}
+	}
+	return false
+}
+
+func overlaps(m1, m2 []string) bool {
+	if len(m1) == 0 || len(m2) == 0 {
+		return true
+	}
+	for _, m := range m1 {
+		if allowsMethod(m2, m) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchHttp - the default HttpMatcher, matches a request against the route patterns
+func (t *table) matchHttp(req *http.Request) (string, bool) {
+	if req == nil || req.URL == nil {
+		return "", true
+	}
+	host := req.Host
+	if host == "" {
+		host = req.URL.Host
+	}
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	name, _ := t.patterns.match(host, req.URL.Path, req.Method)
+	return name, true
+}
+
+// matchUri - the default UriMatcher, matches a uri against the route patterns
+func (t *table) matchUri(uri, method string) (string, bool) {
+	_, host, path := ParseUri(uri)
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	name, _ := t.patterns.match(host, path, method)
+	return name, true
+}
@@ -0,0 +1,124 @@
+package controller
+
+import (
+	"fmt"
+	"net/http"
+)
+
+func addPatternRoute(t *table, name, pattern string) []error {
+	route := NewRoute(name, EgressTraffic, "", false)
+	route.Pattern = pattern
+	return t.AddController(route)
+}
+
+func ExampleParsePattern() {
+	methods, host, segments, err := ParsePattern("get,Head Api.Example.com/v1/users/*")
+	fmt.Printf("test: ParsePattern() -> [methods:%v] [host:%v] [segments:%v] [err:%v]\n", methods, host, segments, err)
+
+	methods, host, segments, err = ParsePattern("*/v1")
+	fmt.Printf("test: ParsePattern(any-host) -> [methods:%v] [host:%v] [segments:%v] [err:%v]\n", methods, host, segments, err)
+
+	methods, host, segments, err = ParsePattern("*.Example.com/v1")
+	fmt.Printf("test: ParsePattern(subdomain) -> [methods:%v] [host:%v] [segments:%v] [err:%v]\n", methods, host, segments, err)
+
+	_, _, _, err = ParsePattern("api.*.com/v1")
+	fmt.Printf("test: ParsePattern(invalid) -> [err:%v]\n", err)
+
+	//Output:
+	//test: ParsePattern() -> [methods:[GET HEAD]] [host:api.example.com] [segments:[v1 users *]] [err:<nil>]
+	//test: ParsePattern(any-host) -> [methods:[]] [host:] [segments:[v1]] [err:<nil>]
+	//test: ParsePattern(subdomain) -> [methods:[]] [host:*.example.com] [segments:[v1]] [err:<nil>]
+	//test: ParsePattern(invalid) -> [err:invalid argument: pattern host is invalid [api.*.com]]
+
+}
+
+func ExampleTable_LookupHttp_Pattern() {
+	t := newTable(true, false)
+	addPatternRoute(t, "api", "api.example.com/v1")
+	addPatternRoute(t, "users", "api.example.com/v1/users/*")
+	addPatternRoute(t, "user-orders", "POST api.example.com/v1/users/*/orders")
+	addPatternRoute(t, "admin", "api.example.com/v1/users/admin")
+	addPatternRoute(t, "any-host", "/health")
+	errs := addPatternRoute(t, "duplicate", "api.example.com/v1/")
+
+	for _, s := range []string{
+		"GET http://api.example.com/v1/accounts",
+		"GET http://api.example.com:8080/v1/users/123",
+		"GET http://api.example.com/v1/users/admin",
+		"POST http://api.example.com/v1/users/123/orders",
+		"GET http://api.example.com/v1/users/123/orders",
+		"GET http://www.example.com/health",
+		"GET http://www.example.com/v1",
+	} {
+		var method, uri string
+		fmt.Sscanf(s, "%s %s", &method, &uri)
+		req, _ := http.NewRequest(method, uri, nil)
+		fmt.Printf("test: LookupHttp(%v) -> %v\n", s, t.LookupHttp(req).Name())
+	}
+	fmt.Printf("test: AddController(duplicate) -> %v\n", errs)
+
+	t.remove("users")
+	req, _ := http.NewRequest("GET", "http://api.example.com/v1/users/123", nil)
+	fmt.Printf("test: LookupHttp(removed) -> %v\n", t.LookupHttp(req).Name())
+
+	t.SetHttpMatcher(func(req *http.Request) (string, bool) {
+		return "admin", true
+	})
+	fmt.Printf("test: LookupHttp(custom) -> %v\n", t.LookupHttp(req).Name())
+
+	//Output:
+	//test: LookupHttp(GET http://api.example.com/v1/accounts) -> api
+	//test: LookupHttp(GET http://api.example.com:8080/v1/users/123) -> users
+	//test: LookupHttp(GET http://api.example.com/v1/users/admin) -> admin
+	//test: LookupHttp(POST http://api.example.com/v1/users/123/orders) -> user-orders
+	//test: LookupHttp(GET http://api.example.com/v1/users/123/orders) -> users
+	//test: LookupHttp(GET http://www.example.com/health) -> any-host
+	//test: LookupHttp(GET http://www.example.com/v1) -> *
+	//test: AddController(duplicate) -> [invalid argument: route pattern is a duplicate of route [api] [api.example.com/v1/]]
+	//test: LookupHttp(removed) -> api
+	//test: LookupHttp(custom) -> admin
+
+}
+
+func ExampleTable_LookupHttp_WildcardHost() {
+	t := newTable(true, false)
+	addPatternRoute(t, "example", "*.example.com")
+	addPatternRoute(t, "api", "*.api.example.com")
+	addPatternRoute(t, "www", "www.example.com")
+	errs := addPatternRoute(t, "invalid", "*.")
+
+	for _, uri := range []string{
+		"http://www.example.com/v1",
+		"http://shop.example.com/v1",
+		"http://eu.api.example.com:8080/v1",
+		"http://example.com/v1",
+	} {
+		req, _ := http.NewRequest("GET", uri, nil)
+		fmt.Printf("test: LookupHttp(%v) -> %v\n", uri, t.LookupHttp(req).Name())
+	}
+	fmt.Printf("test: AddController(invalid) -> %v\n", errs)
+
+	//Output:
+	//test: LookupHttp(http://www.example.com/v1) -> www
+	//test: LookupHttp(http://shop.example.com/v1) -> example
+	//test: LookupHttp(http://eu.api.example.com:8080/v1) -> api
+	//test: LookupHttp(http://example.com/v1) -> *
+	//test: AddController(invalid) -> [invalid argument: pattern host is invalid [*.]]
+
+}
+
+func ExampleTable_LookupUri_Pattern() {
+	t := newTable(true, false)
+	addPatternRoute(t, "google-search", "GET www.google.com/search")
+	addPatternRoute(t, "urn-service", "service-host/resource")
+
+	fmt.Printf("test: LookupUri(http) -> %v\n", t.LookupUri("https://www.google.com/search?q=golang", "GET").Name())
+	fmt.Printf("test: LookupUri(method) -> %v\n", t.LookupUri("https://www.google.com/search?q=golang", "PUT").Name())
+	fmt.Printf("test: LookupUri(urn) -> %v\n", t.LookupUri("urn:service-host:resource", "").Name())
+
+	//Output:
+	//test: LookupUri(http) -> google-search
+	//test: LookupUri(method) -> *
+	//test: LookupUri(urn) -> urn-service
+
+}
@@ -0,0 +1,328 @@
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	RoundRobinPolicy   = "round-robin"
+	LeastRequestPolicy = "least-request"
+	PowerOfTwoPolicy   = "power-of-two"
+
+	DefaultConsecutiveErrors = 5
+	DefaultBaseEjection      = time.Second * 30
+	DefaultMaxEjection       = time.Second * 300
+)
+
+// Pool - interface for load balancing across a pool of upstream endpoints, with passive outlier detection. Endpoints
+// are ejected after consecutive 5xx responses, transport errors or timeouts, for a period that increases with each
+// ejection.
+type Pool interface {
+	State
+	Actuator
+	Policy() string
+	Endpoints() []string
+	Ejected() []string
+	Select() string
+	Done(host string, statusCode int, err error, timeout bool)
+}
+
+type PoolConfig struct {
+	Enabled           bool
+	Policy            string   // round-robin, least-request or power-of-two, defaults to round-robin
+	Endpoints         []string // upstream hosts, host:port
+	ConsecutiveErrors int      // consecutive failures before an endpoint is ejected, outlier detection is disabled if < 0
+	BaseEjection      time.Duration
+	MaxEjection       time.Duration
+}
+
+var nilPool = newPool(NilBehaviorName, nil, NewPoolConfig(false, "", nil))
+
+func NewPoolConfig(enabled bool, policy string, endpoints []string) *PoolConfig {
+	c := new(PoolConfig)
+	c.Enabled = enabled
+	c.Policy = policy
+	c.Endpoints = endpoints
+	c.ConsecutiveErrors = DefaultConsecutiveErrors
+	c.BaseEjection = DefaultBaseEjection
+	c.MaxEjection = DefaultMaxEjection
+	return c
+}
+
+type endpoint struct {
+	host        string
+	outstanding int
+	failures    int
+	ejections   int
+	ejected     time.Time // end of the current ejection period
+	unhealthy   bool      // set by active health checks
+}
+
+// endpoints - endpoint state, shared by all clones of a pool
+type endpoints struct {
+	mu    sync.Mutex
+	next  int
+	items []*endpoint
+}
+
+type pool struct {
+	name   string
+	table  *table
+	config PoolConfig
+	state  *endpoints
+}
+
+func clonePool(curr *pool) *pool {
+	t := new(pool)
+	*t = *curr
+	return t
+}
+
+func newPool(name string, table *table, config *PoolConfig) *pool {
+	t := new(pool)
+	t.name = name
+	t.table = table
+	if config != nil {
+		t.config = *config
+	}
+	if t.config.Policy == "" {
+		t.config.Policy = RoundRobinPolicy
+	}
+	if t.config.ConsecutiveErrors == 0 {
+		t.config.ConsecutiveErrors = DefaultConsecutiveErrors
+	}
+	if t.config.BaseEjection <= 0 {
+		t.config.BaseEjection = DefaultBaseEjection
+	}
+	if t.config.MaxEjection <= 0 {
+		t.config.MaxEjection = DefaultMaxEjection
+	}
+	t.state = new(endpoints)
+	for _, host := range t.config.Endpoints {
+		t.state.items = append(t.state.items, &endpoint{host: host})
+	}
+	return t
+}
+
+func (p *pool) validate() error {
+	if len(p.config.Endpoints) == 0 {
+		return errors.New(fmt.Sprintf("invalid configuration: Pool endpoints are empty [%v]", p.name))
+	}
+	if !isPolicy(p.config.Policy) {
+		return errors.New(fmt.Sprintf("invalid configuration: Pool policy is invalid [%v] [%v]", p.config.Policy, p.name))
+	}
+	dup := make(map[string]bool)
+	for _, host := range p.config.Endpoints {
+		if host == "" || strings.Contains(host, "/") {
+			return errors.New(fmt.Sprintf("invalid configuration: Pool endpoint is invalid [%v] [%v]", host, p.name))
+		}
+		if dup[host] {
+			return errors.New(fmt.Sprintf("invalid configuration: Pool endpoint is a duplicate [%v] [%v]", host, p.name))
+		}
+		dup[host] = true
+	}
+	if p.config.MaxEjection < p.config.BaseEjection {
+		return errors.New(fmt.Sprintf("invalid configuration: Pool max ejection is < base ejection [%v]", p.name))
+	}
+	return nil
+}
+
+func isPolicy(policy string) bool {
+	return policy == RoundRobinPolicy || policy == LeastRequestPolicy || policy == PowerOfTwoPolicy
+}
+
+func (p *pool) IsEnabled() bool { return p.config.Enabled }
+
+func (p *pool) IsNil() bool { return p.name == NilBehaviorName }
+
+func (p *pool) Enable() {
+	if p.IsEnabled() {
+		return
+	}
+	p.enablePool(true)
+}
+
+func (p *pool) Disable() {
+	if !p.IsEnabled() {
+		return
+	}
+	p.enablePool(false)
+}
+
+func (p *pool) Signal(values url.Values) error {
+	if p.IsNil() {
+		return errors.New("invalid signal: pool is not configured")
+	}
+	if values == nil {
+		return errors.New("invalid argument: values are nil for pool signal")
+	}
+	UpdateEnable(p, values)
+	if values.Has(PolicyKey) {
+		policy := values.Get(PolicyKey)
+		if !isPolicy(policy) {
+			return errors.New(fmt.Sprintf("invalid argument: pool policy is invalid [%v]", policy))
+		}
+		if policy != p.config.Policy {
+			p.setPolicy(policy)
+		}
+	}
+	return nil
+}
+
+func (p *pool) Policy() string {
+	return p.config.Policy
+}
+
+func (p *pool) Endpoints() []string {
+	return p.config.Endpoints
+}
+
+// Ejected - the endpoints that are currently ejected
+func (p *pool) Ejected() []string {
+	p.state.mu.Lock()
+	defer p.state.mu.Unlock()
+	var hosts []string
+	now := time.Now()
+	for _, e := range p.state.items {
+		if now.Before(e.ejected) {
+			hosts = append(hosts, e.host)
+		}
+	}
+	return hosts
+}
+
+// Select - select an endpoint according to the policy, ejected and unhealthy endpoints are only selected when all
+// endpoints are ejected or unhealthy. Every selection must be followed by a call to Done.
+func (p *pool) Select() string {
+	p.state.mu.Lock()
+	defer p.state.mu.Unlock()
+	if len(p.state.items) == 0 {
+		return ""
+	}
+	now := time.Now()
+	var available []*endpoint
+	for _, e := range p.state.items {
+		if !e.unhealthy && !now.Before(e.ejected) {
+			available = append(available, e)
+		}
+	}
+	if len(available) == 0 {
+		available = p.state.items
+	}
+	var e *endpoint
+	switch p.config.Policy {
+	case LeastRequestPolicy:
+		// Ties are broken in round-robin order
+		start := p.state.next
+		p.state.next++
+		for i := range available {
+			curr := available[(start+i)%len(available)]
+			if e == nil || curr.outstanding < e.outstanding {
+				e = curr
+			}
+		}
+	case PowerOfTwoPolicy:
+		e = available[rand.Intn(len(available))]
+		if len(available) > 1 {
+			i := rand.Intn(len(available) - 1)
+			if available[i] == e {
+				i = len(available) - 1
+			}
+			if available[i].outstanding < e.outstanding {
+				e = available[i]
+			}
+		}
+	default:
+		e = available[p.state.next%len(available)]
+		p.state.next++
+	}
+	e.outstanding++
+	return e.host
+}
+
+// Done - record the outcome of a request sent to an endpoint, a 5xx status code, a transport error or a timeout is
+// a failure
+func (p *pool) Done(host string, statusCode int, err error, timeout bool) {
+	p.state.mu.Lock()
+	defer p.state.mu.Unlock()
+	var e *endpoint
+	for _, curr := range p.state.items {
+		if curr.host == host {
+			e = curr
+			break
+		}
+	}
+	if e == nil {
+		return
+	}
+	if e.outstanding > 0 {
+		e.outstanding--
+	}
+	now := time.Now()
+	if err == nil && !timeout && statusCode < 500 {
+		e.failures = 0
+		// The ejection count is reset once an endpoint has been healthy for the max ejection period
+		if e.ejections > 0 && now.Sub(e.ejected) > p.config.MaxEjection {
+			e.ejections = 0
+		}
+		return
+	}
+	if p.config.ConsecutiveErrors < 0 || now.Before(e.ejected) {
+		return
+	}
+	e.failures++
+	if e.failures < p.config.ConsecutiveErrors {
+		return
+	}
+	e.failures = 0
+	e.ejections++
+	d := p.config.BaseEjection * time.Duration(e.ejections)
+	if d > p.config.MaxEjection {
+		d = p.config.MaxEjection
+	}
+	e.ejected = now.Add(d)
+	defaultEjectFn(p.name, e.host, d)
+}
+
+// setHealth - apply an active health check result for an endpoint, unhealthy endpoints are not selected
+func (p *pool) setHealth(host string, healthy bool) {
+	p.state.mu.Lock()
+	defer p.state.mu.Unlock()
+	for _, e := range p.state.items {
+		if e.host == host {
+			e.unhealthy = !healthy
+		}
+	}
+}
+
+func (p *pool) enablePool(enabled bool) {
+	if p.table == nil || p.IsNil() {
+		return
+	}
+	p.table.mu.Lock()
+	defer p.table.mu.Unlock()
+	if ctrl, ok := p.table.controllers[p.name]; ok {
+		c := clonePool(ctrl.pool)
+		c.config.Enabled = enabled
+		p.table.update(p.name, cloneController[*pool](ctrl, c))
+	}
+}
+
+func (p *pool) setPolicy(policy string) {
+	if p.table == nil || p.IsNil() {
+		return
+	}
+	p.table.mu.Lock()
+	defer p.table.mu.Unlock()
+	if ctrl, ok := p.table.controllers[p.name]; ok {
+		c := clonePool(ctrl.pool)
+		c.config.Policy = policy
+		p.table.update(p.name, cloneController[*pool](ctrl, c))
+	}
+}
@@ -0,0 +1,132 @@
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+)
+
+func Example_newPool() {
+	p := newPool("test-route", newTable(true, false), NewPoolConfig(true, "", []string{"host-1:8080", "host-2:8080", "host-3:8080"}))
+	fmt.Printf("test: newPool() -> [name:%v] [policy:%v] [endpoints:%v] [errors:%v] [base:%v] [max:%v]\n", p.name, p.Policy(), p.Endpoints(), p.config.ConsecutiveErrors, p.config.BaseEjection, p.config.MaxEjection)
+
+	p2 := clonePool(p)
+	p2.config.Policy = LeastRequestPolicy
+	fmt.Printf("test: clonePool() -> [prev-policy:%v] [curr-policy:%v] [shared-state:%v]\n", p.Policy(), p2.Policy(), p.state == p2.state)
+
+	fmt.Printf("test: validate() -> [%v]\n", newPool("test-route", nil, NewPoolConfig(true, "", nil)).validate())
+	fmt.Printf("test: validate() -> [%v]\n", newPool("test-route", nil, NewPoolConfig(true, "random", []string{"host-1"})).validate())
+	fmt.Printf("test: validate() -> [%v]\n", newPool("test-route", nil, NewPoolConfig(true, "", []string{"host-1", "host-1"})).validate())
+
+	//Output:
+	//test: newPool() -> [name:test-route] [policy:round-robin] [endpoints:[host-1:8080 host-2:8080 host-3:8080]] [errors:5] [base:30s] [max:5m0s]
+	//test: clonePool() -> [prev-policy:round-robin] [curr-policy:least-request] [shared-state:true]
+	//test: validate() -> [invalid configuration: Pool endpoints are empty [test-route]]
+	//test: validate() -> [invalid configuration: Pool policy is invalid [random] [test-route]]
+	//test: validate() -> [invalid configuration: Pool endpoint is a duplicate [host-1] [test-route]]
+
+}
+
+func ExamplePool_Select() {
+	hosts := []string{"host-1", "host-2", "host-3"}
+	p := newPool("test-route", nil, NewPoolConfig(true, RoundRobinPolicy, hosts))
+	var selected []string
+	for i := 0; i < 4; i++ {
+		host := p.Select()
+		selected = append(selected, host)
+		p.Done(host, 200, nil, false)
+	}
+	fmt.Printf("test: Select(round-robin) -> %v\n", selected)
+
+	p = newPool("test-route", nil, NewPoolConfig(true, LeastRequestPolicy, hosts))
+	selected = nil
+	for i := 0; i < 4; i++ {
+		selected = append(selected, p.Select())
+	}
+	p.Done("host-2", 200, nil, false)
+	selected = append(selected, p.Select())
+	fmt.Printf("test: Select(least-request) -> %v\n", selected)
+
+	p = newPool("test-route", nil, NewPoolConfig(true, PowerOfTwoPolicy, hosts))
+	host := p.Select()
+	fmt.Printf("test: Select(power-of-two) -> [valid:%v]\n", host == "host-1" || host == "host-2" || host == "host-3")
+
+	//Output:
+	//test: Select(round-robin) -> [host-1 host-2 host-3 host-1]
+	//test: Select(least-request) -> [host-1 host-2 host-3 host-1 host-2]
+	//test: Select(power-of-two) -> [valid:true]
+
+}
+
+func ExamplePool_Done() {
+	config := NewPoolConfig(true, RoundRobinPolicy, []string{"host-1", "host-2"})
+	config.ConsecutiveErrors = 2
+	config.BaseEjection = time.Millisecond * 50
+	config.MaxEjection = time.Millisecond * 80
+	p := newPool("test-route", nil, config)
+
+	p.Done("host-1", 503, nil, false)
+	p.Done("host-1", 200, nil, false)
+	p.Done("host-1", 503, nil, false)
+	fmt.Printf("test: Done(503,200,503) -> [ejected:%v]\n", p.Ejected())
+
+	p.Done("host-1", 0, errors.New("dial error"), false)
+	fmt.Printf("test: Done(error) -> [ejected:%v] [select:%v,%v]\n", p.Ejected(), p.Select(), p.Select())
+
+	time.Sleep(time.Millisecond * 60)
+	fmt.Printf("test: Ejected() -> [ejected:%v]\n", p.Ejected())
+
+	p.Done("host-1", 200, nil, true)
+	p.Done("host-1", 504, nil, false)
+	fmt.Printf("test: Done(timeout,504) -> [ejected:%v]\n", p.Ejected())
+
+	p.Done("host-2", 500, nil, false)
+	p.Done("host-2", 500, nil, false)
+	fmt.Printf("test: Done(all-ejected) -> [ejected:%v] [select:%v]\n", p.Ejected(), p.Select() != "")
+
+	//Output:
+	//test: Done(503,200,503) -> [ejected:[]]
+	//{"route":"test-route", "endpoint":"host-1", "ejection":"50ms"}
+	//test: Done(error) -> [ejected:[host-1]] [select:host-2,host-2]
+	//test: Ejected() -> [ejected:[]]
+	//{"route":"test-route", "endpoint":"host-1", "ejection":"80ms"}
+	//test: Done(timeout,504) -> [ejected:[host-1]]
+	//{"route":"test-route", "endpoint":"host-2", "ejection":"50ms"}
+	//test: Done(all-ejected) -> [ejected:[host-1 host-2]] [select:true]
+
+}
+
+func ExamplePool_Signal() {
+	name := "test-route"
+	t := newTable(true, false)
+	errs := t.AddController(newRoute(name, NewPoolConfig(true, "", []string{"host-1", "host-2"})))
+	fmt.Printf("test: Add() -> [%v] [count:%v]\n", errs, t.count())
+
+	values := make(url.Values)
+	values.Add(BehaviorKey, PoolBehavior)
+	values.Add(PolicyKey, PowerOfTwoPolicy)
+	err := t.LookupByName(name).Signal(values)
+	fmt.Printf("test: Signal(policy) -> [error:%v] [policy:%v]\n", err, t.LookupByName(name).Pool().Policy())
+
+	err = t.LookupByName(name).Pool().Signal(NewValues(PolicyKey, "random"))
+	fmt.Printf("test: Signal(policy=random) -> [error:%v]\n", err)
+
+	t.LookupByName(name).Pool().Signal(enableValues(false))
+	fmt.Printf("test: Disable() -> [enabled:%v]\n", t.LookupByName(name).Pool().IsEnabled())
+
+	err = t.LookupByName(name).Pool().Signal(url.Values{EnabledKey: {TrueValue}, PolicyKey: {LeastRequestPolicy}})
+	fmt.Printf("test: Signal(enabled,policy) -> [error:%v] [enabled:%v] [policy:%v]\n", err, t.LookupByName(name).Pool().IsEnabled(), t.LookupByName(name).Pool().Policy())
+
+	errs = newTable(false, false).AddController(newRoute("ingress-route", NewPoolConfig(true, "", []string{"host-1"})))
+	fmt.Printf("test: Add(ingress) -> %v\n", errs)
+
+	//Output:
+	//test: Add() -> [[]] [count:1]
+	//test: Signal(policy) -> [error:<nil>] [policy:power-of-two]
+	//test: Signal(policy=random) -> [error:invalid argument: pool policy is invalid [random]]
+	//test: Disable() -> [enabled:false]
+	//test: Signal(enabled,policy) -> [error:<nil>] [enabled:true] [policy:least-request]
+	//test: Add(ingress) -> [invalid configuration: Pool is not valid for ingress traffic]
+
+}
@@ -0,0 +1,83 @@
+package controller
+
+import (
+	"net/http"
+	"strings"
+)
+
+// Request priority classes, when an ingress rate limiter is limiting, lower priorities are shed first
+const (
+	PriorityCritical = "critical" // never shed, the default for Ping routes
+	PriorityHigh     = "high"
+	PriorityNormal   = "normal"
+	PriorityLow      = "low"
+
+	PriorityHeaderName = "X-Priority"
+)
+
+// priorityReserve - the share of rate limiter capacity that a priority can not use, as it is reserved for
+// higher priorities. Unclassified requests are not shed before the limit is reached
+var priorityReserve = map[string]float64{
+	PriorityLow:    0.5,
+	PriorityNormal: 0.25,
+	PriorityHigh:   0,
+}
+
+func IsPriority(priority string) bool {
+	if priority == PriorityCritical {
+		return true
+	}
+	_, ok := priorityReserve[priority]
+	return ok
+}
+
+// Priority - determine the priority of a request. The priority matcher is used first, then the priority
+// request header, and then the priority of the route that the request matches. The request header is set by the
+// caller, so it is capped at high, and a critical priority that is not shed requires the matcher or the route
+func (t *table) Priority(req *http.Request) string {
+	if req == nil {
+		return ""
+	}
+	t.mu.RLock()
+	fn := t.priorityMatch
+	t.mu.RUnlock()
+	if fn != nil {
+		if priority, ok := fn(req); ok && IsPriority(priority) {
+			return priority
+		}
+	}
+	if priority := strings.ToLower(req.Header.Get(PriorityHeaderName)); IsPriority(priority) {
+		if priority == PriorityCritical {
+			return PriorityHigh
+		}
+		return priority
+	}
+	return t.LookupHttp(req).Priority()
+}
+
+func (t *table) SetPriorityMatcher(fn PriorityMatcher) {
+	if fn == nil {
+		return
+	}
+	t.mu.Lock()
+	t.priorityMatch = fn
+	t.mu.Unlock()
+}
+
+// Priority - the configured route priority, Ping routes are critical unless configured otherwise
+func (c *controller) Priority() string {
+	if c.priority != "" {
+		return c.priority
+	}
+	if c.ping {
+		return PriorityCritical
+	}
+	return ""
+}
+
+func (c *controller) requestPriority(req *http.Request) string {
+	if c.tbl == nil || req == nil {
+		return c.Priority()
+	}
+	return c.tbl.Priority(req)
+}
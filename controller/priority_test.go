@@ -0,0 +1,61 @@
+package controller
+
+import (
+	"fmt"
+	"net/http"
+)
+
+func ExampleTable_Priority() {
+	t := newTable(false, true)
+	t.AddController(NewRoute("health-route", IngressTraffic, "", true))
+	t.AddController(NewRoute("batch-route", IngressTraffic, "", false))
+	t.AddController(NewRoute("search-route", IngressTraffic, "", false))
+	errs := t.AddController(NewRoute("invalid-route", IngressTraffic, "", false))
+	t.SetHttpMatcher(func(req *http.Request) (string, bool) {
+		return req.URL.Path[1:], true
+	})
+
+	health, _ := http.NewRequest("GET", "http://localhost:8080/health-route", nil)
+	search, _ := http.NewRequest("GET", "http://localhost:8080/search-route", nil)
+	fmt.Printf("test: Priority() -> [health:%v] [search:%v] [errs:%v]\n", t.Priority(health), t.Priority(search), errs)
+
+	search.Header.Set(PriorityHeaderName, "HIGH")
+	fmt.Printf("test: Priority(header) -> [search:%v]\n", t.Priority(search))
+
+	search.Header.Set(PriorityHeaderName, PriorityCritical)
+	fmt.Printf("test: Priority(header) -> [search:%v]\n", t.Priority(search))
+
+	t.SetPriorityMatcher(func(req *http.Request) (string, bool) {
+		return PriorityLow, req.URL.Path == "/search-route"
+	})
+	fmt.Printf("test: Priority(matcher) -> [health:%v] [search:%v]\n", t.Priority(health), t.Priority(search))
+
+	route := NewRoute("low-route", IngressTraffic, "", false)
+	route.Priority = "urgent"
+	fmt.Printf("test: AddController(urgent) -> %v\n", t.AddController(route))
+
+	//Output:
+	//test: Priority() -> [health:critical] [search:] [errs:[]]
+	//test: Priority(header) -> [search:high]
+	//test: Priority(header) -> [search:high]
+	//test: Priority(matcher) -> [health:critical] [search:low]
+	//test: AddController(urgent) -> [invalid configuration: priority is invalid [urgent] [low-route]]
+
+}
+
+func ExampleRateLimiter_AllowPriority() {
+	t := newRateLimiter("test-route", nil, NewRateLimiterConfig(true, 503, 1, 4, ""))
+	fmt.Printf("test: AllowPriority() -> [low:%v] [low:%v] [low:%v] [low:%v]\n", t.AllowPriority(PriorityLow), t.AllowPriority(PriorityLow), t.AllowPriority(PriorityLow), t.AllowPriority(PriorityLow))
+	fmt.Printf("test: AllowPriority() -> [normal:%v] [high:%v] [critical:%v]\n", t.AllowPriority(PriorityNormal), t.AllowPriority(PriorityHigh), t.AllowPriority(PriorityCritical))
+
+	config := NewRateLimiterConfig(true, 503, 0, 0, "")
+	config.Adaptive = NewAdaptiveLimitConfig(AIMDLimit, 4, 2, 10)
+	a := newRateLimiter("test-route", nil, config)
+	fmt.Printf("test: Acquire() -> [low:%v] [low:%v] [low:%v] [normal:%v] [high:%v] [high:%v] [critical:%v]\n", a.Acquire(PriorityLow), a.Acquire(PriorityLow), a.Acquire(PriorityLow), a.Acquire(PriorityNormal), a.Acquire(PriorityHigh), a.Acquire(PriorityHigh), a.Acquire(PriorityCritical))
+
+	//Output:
+	//test: AllowPriority() -> [low:true] [low:true] [low:true] [low:false]
+	//test: AllowPriority() -> [normal:true] [high:false] [critical:true]
+	//test: Acquire() -> [low:true] [low:true] [low:false] [normal:true] [high:true] [high:false] [critical:true]
+
+}
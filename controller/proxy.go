@@ -1,9 +1,20 @@
 package controller
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"hash/fnv"
+	"math/rand"
+	"net/http"
 	"net/url"
+	"strconv"
+	"time"
+)
+
+const (
+	PrimaryTarget = "primary"
+	MaxWeight     = 100
 )
 
 type Header struct {
@@ -11,6 +22,15 @@ type Header struct {
 	Value string
 }
 
+// ProxyTarget - a weighted proxy target, the weight is the percentage of requests sent to the target
+type ProxyTarget struct {
+	Name    string
+	Pattern string
+	Weight  int
+}
+
+type proxyTargetKey struct{}
+
 // Proxy - interface for proxy
 type Proxy interface {
 	State
@@ -20,14 +40,29 @@ type Proxy interface {
 	Pattern() string
 	Headers() []Header
 	BuildUrl(uri *url.URL) *url.URL
+	Targets() []ProxyTarget
+	Select(req *http.Request) ProxyTarget
+	BuildTargetUrl(uri *url.URL, target ProxyTarget) *url.URL
+	IsFailover() bool
+	FailoverState() (active, probe bool)
+	Record(statusCode int, err error, probe bool)
 }
 
+// ProxyConfig - the Pattern is the primary target, and receives the requests not sent to the weighted targets
 type ProxyConfig struct {
-	Enabled   bool
-	Pattern   string
-	Headers   []Header
-	Action    Actuator
-	Threshold string
+	Enabled      bool
+	Pattern      string
+	Headers      []Header
+	Action       Actuator
+	Threshold    string
+	Targets      []ProxyTarget
+	StickyHeader string // request header used for sticky target assignment, requests are assigned randomly if empty
+
+	// Automatic failover to the Pattern when the primary fails, the Threshold is a failure count, "5", or a failure
+	// count within an interval, "5/10s"
+	Failover      bool
+	StatusCodes   []int         // failure status codes, defaults to 5xx when empty
+	ProbeInterval time.Duration // wait before probing the primary after a failover, defaults to 5s
 }
 
 var nilProxy = newProxy(NilBehaviorName, nil, NewProxyConfig(false, "", nil, nil, ""))
@@ -43,9 +78,10 @@ func NewProxyConfig(enabled bool, pattern string, headers []Header, action Actua
 }
 
 type proxy struct {
-	table  *table
-	name   string
-	config ProxyConfig
+	table    *table
+	name     string
+	config   ProxyConfig
+	failover *failover
 }
 
 func cloneProxy(curr *proxy) *proxy {
@@ -61,26 +97,49 @@ func newProxy(name string, table *table, config *ProxyConfig) *proxy {
 	if config != nil {
 		t.config = *config
 	}
+	if t.config.Failover {
+		t.failover = newFailover(&t.config)
+	}
 	return t
 }
 
 func (p *proxy) validate() error {
+	if err := p.validateTargets(p.config.Targets); err != nil {
+		return err
+	}
+	if err := p.validateFailover(); err != nil {
+		return err
+	}
 	if p.config.Enabled {
 		return p.validatePattern(p.config.Pattern)
 	}
 	return nil
 }
 
-func proxyState(p *proxy) (string, string) {
+// proxyState - the proxy log field is the selected target name for weighted targets
+func proxyState(p *proxy, req *http.Request) (string, string) {
 	if !p.IsEnabled() {
 		return "", ""
 	}
+	if req != nil {
+		if name, ok := req.Context().Value(proxyTargetKey{}).(string); ok {
+			return name, p.config.Threshold
+		}
+	}
 	if len(p.Pattern()) > 0 {
 		return "true", p.config.Threshold
 	}
 	return "false", p.config.Threshold
 }
 
+// NewProxyTargetContext - create a new context containing the selected proxy target name, for logging
+func NewProxyTargetContext(ctx context.Context, name string) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return context.WithValue(ctx, proxyTargetKey{}, name)
+}
+
 func (p *proxy) Signal(values url.Values) error {
 	if p.IsNil() {
 		return errors.New("invalid signal: proxy is not configured")
@@ -91,6 +150,12 @@ func (p *proxy) Signal(values url.Values) error {
 	if IsDisable(values) {
 		p.Disable()
 	}
+	if values.Has(WeightKey) {
+		err := p.setWeights(values.Get(WeightKey))
+		if err != nil {
+			return err
+		}
+	}
 	if values.Has(PatternKey) {
 		v := values.Get(PatternKey)
 		if len(v) == 0 {
@@ -150,11 +215,55 @@ func (p *proxy) Headers() []Header {
 	return p.config.Headers
 }
 
+func (p *proxy) Targets() []ProxyTarget {
+	return p.config.Targets
+}
+
+// Select - select a target by weight, the primary target receives the remaining requests. With a sticky header, a
+// request is assigned by a hash of the header value, so requests with the same value are sent to the same target.
+func (p *proxy) Select(req *http.Request) ProxyTarget {
+	primary := ProxyTarget{Name: PrimaryTarget, Pattern: p.config.Pattern, Weight: MaxWeight}
+	if len(p.config.Targets) == 0 {
+		return primary
+	}
+	var n int
+	if v := stickyValue(req, p.config.StickyHeader); v != "" {
+		h := fnv.New32a()
+		h.Write([]byte(v))
+		n = int(h.Sum32() % MaxWeight)
+	} else {
+		n = rand.Intn(MaxWeight)
+	}
+	for _, t := range p.config.Targets {
+		if n < t.Weight {
+			return t
+		}
+		n -= t.Weight
+		primary.Weight -= t.Weight
+	}
+	return primary
+}
+
+func stickyValue(req *http.Request, header string) string {
+	if req == nil || header == "" {
+		return ""
+	}
+	return req.Header.Get(header)
+}
+
 func (p *proxy) BuildUrl(uri *url.URL) *url.URL {
-	if uri == nil || len(p.config.Pattern) == 0 {
+	return buildUrl(uri, p.config.Pattern)
+}
+
+func (p *proxy) BuildTargetUrl(uri *url.URL, target ProxyTarget) *url.URL {
+	return buildUrl(uri, target.Pattern)
+}
+
+func buildUrl(uri *url.URL, pattern string) *url.URL {
+	if uri == nil || len(pattern) == 0 {
 		return uri
 	}
-	uri2, err := url.Parse(p.config.Pattern)
+	uri2, err := url.Parse(pattern)
 	if err != nil {
 		return uri
 	}
@@ -227,6 +336,69 @@ func (p *proxy) setAction(action Actuator) {
 	}
 }
 
+// setWeights - set target weights from a "name:weight,name:weight" value
+func (p *proxy) setWeights(value string) error {
+	names, weights := ParseState(value)
+	if len(names) == 0 {
+		return errors.New(fmt.Sprintf("invalid argument: proxy weight is empty [%v]", p.name))
+	}
+	if p.table == nil || p.IsNil() {
+		return nil
+	}
+	p.table.mu.Lock()
+	defer p.table.mu.Unlock()
+	ctrl, ok := p.table.controllers[p.name]
+	if !ok {
+		return nil
+	}
+	targets := append([]ProxyTarget(nil), ctrl.proxy.config.Targets...)
+	for i, name := range names {
+		weight, err := strconv.Atoi(weights[i])
+		if err != nil {
+			return errors.New(fmt.Sprintf("invalid argument: proxy weight is invalid [%v] [%v]", weights[i], p.name))
+		}
+		found := false
+		for j := range targets {
+			if targets[j].Name == name {
+				targets[j].Weight = weight
+				found = true
+			}
+		}
+		if !found {
+			return errors.New(fmt.Sprintf("invalid argument: proxy target not found [%v] [%v]", name, p.name))
+		}
+	}
+	if err := p.validateTargets(targets); err != nil {
+		return err
+	}
+	fc := cloneProxy(ctrl.proxy)
+	fc.config.Targets = targets
+	p.table.update(p.name, cloneController[*proxy](ctrl, fc))
+	return nil
+}
+
+func (p *proxy) validateTargets(targets []ProxyTarget) error {
+	total := 0
+	names := make(map[string]bool)
+	for _, t := range targets {
+		if t.Name == "" || t.Name == PrimaryTarget || names[t.Name] {
+			return errors.New(fmt.Sprintf("invalid configuration: proxy target name is empty, primary, or a duplicate [%v] [%v]", t.Name, p.name))
+		}
+		names[t.Name] = true
+		if t.Weight < 0 || t.Weight > MaxWeight {
+			return errors.New(fmt.Sprintf("invalid configuration: proxy target weight is not in the range 0..100 [%v] [%v]", t.Name, p.name))
+		}
+		total += t.Weight
+		if err := p.validatePattern(t.Pattern); err != nil {
+			return err
+		}
+	}
+	if total > MaxWeight {
+		return errors.New(fmt.Sprintf("invalid configuration: proxy target weights are > 100 [%v]", p.name))
+	}
+	return nil
+}
+
 func (p *proxy) validatePattern(pattern string) error {
 	if len(pattern) == 0 {
 		return errors.New(fmt.Sprintf("invalid argument: proxy pattern is empty [%v]", p.name))
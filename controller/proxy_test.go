@@ -3,7 +3,9 @@ package controller
 import (
 	"errors"
 	"fmt"
+	"net/http"
 	"net/url"
+	"strconv"
 )
 
 type testAction struct{}
@@ -48,12 +50,12 @@ func ExampleProxy_State() {
 	t := newTable(true, false)
 	p := newProxy("test-route", t, NewProxyConfig(false, "http://localhost:8080", nil, nil, "20"))
 
-	valid, threshold := proxyState(p)
+	valid, threshold := proxyState(p, nil)
 	fmt.Printf("test: proxyState(p) -> [enabled:%v] [proxied:%v] [threshold:%v]\n", p.IsEnabled(), valid, threshold)
 
 	p.config.Enabled = true
 
-	valid, threshold = proxyState(p)
+	valid, threshold = proxyState(p, nil)
 	fmt.Printf("test: proxyState(p) -> [enabled:%v] [proxied:%v] [threshold:%v]\n", p.IsEnabled(), valid, threshold)
 
 	//Output:
@@ -226,3 +228,52 @@ func ExampleProxy_SignalAction() {
 	//test: Signal() -> [true] [action:true] [pattern:urn:postgresql:host2:path] [error:test action error]
 
 }
+
+func ExampleProxy_Select() {
+	t := newTable(true, false)
+	config := NewProxyConfig(true, "http://primary:8080", nil, nil, "")
+	config.Targets = []ProxyTarget{{Name: "canary", Pattern: "http://canary:8080", Weight: 5}}
+	config.StickyHeader = "X-User-Id"
+	errs := t.AddController(newRoute("canary-route", config))
+	p := t.LookupByName("canary-route").Proxy()
+
+	counts := make(map[string]int)
+	for i := 0; i < 1000; i++ {
+		req, _ := http.NewRequest("GET", "http://localhost:8081/search", nil)
+		req.Header.Set("X-User-Id", strconv.Itoa(i))
+		counts[p.Select(req).Name]++
+	}
+	fmt.Printf("test: Select() -> [errs:%v] [canary:%v] [primary:%v]\n", errs, counts["canary"] > 20 && counts["canary"] < 80, counts[PrimaryTarget] > 900)
+
+	req, _ := http.NewRequest("GET", "http://localhost:8081/search?q=golang", nil)
+	req.Header.Set("X-User-Id", "user-1234")
+	target := p.Select(req)
+	sticky := true
+	for i := 0; i < 10; i++ {
+		sticky = sticky && p.Select(req).Name == target.Name
+	}
+	fmt.Printf("test: Select(sticky) -> [sticky:%v]\n", sticky)
+
+	err := p.Signal(url.Values{WeightKey: {"canary:100"}})
+	target = t.LookupByName("canary-route").Proxy().Select(req)
+	fmt.Printf("test: Signal(canary:100) -> [err:%v] [target:%v] [url:%v]\n", err, target.Name, p.BuildTargetUrl(req.URL, target))
+
+	err = p.Signal(url.Values{WeightKey: {"canary:101"}})
+	fmt.Printf("test: Signal(canary:101) -> [err:%v]\n", err)
+
+	err = p.Signal(url.Values{WeightKey: {"unknown:10"}})
+	fmt.Printf("test: Signal(unknown:10) -> [err:%v]\n", err)
+
+	ctx := NewProxyTargetContext(req.Context(), target.Name)
+	valid, _ := proxyState(t.LookupByName("canary-route").t().proxy, req.WithContext(ctx))
+	fmt.Printf("test: proxyState(target) -> [proxy:%v]\n", valid)
+
+	//Output:
+	//test: Select() -> [errs:[]] [canary:true] [primary:true]
+	//test: Select(sticky) -> [sticky:true]
+	//test: Signal(canary:100) -> [err:<nil>] [target:canary] [url:http://canary:8080/search?q=golang]
+	//test: Signal(canary:101) -> [err:invalid configuration: proxy target weight is not in the range 0..100 [canary] [canary-route]]
+	//test: Signal(unknown:10) -> [err:invalid argument: proxy target not found [unknown] [canary-route]]
+	//test: proxyState(target) -> [proxy:canary]
+
+}
@@ -4,8 +4,11 @@ import (
 	"errors"
 	"fmt"
 	"golang.org/x/time/rate"
+	"net"
 	"net/http"
 	"net/url"
+	"strings"
+	"time"
 )
 
 const (
@@ -13,14 +16,23 @@ const (
 	DefaultBurst = 1
 )
 
-// RateLimiter - interface for rate limiting
+// RateLimiter - interface for rate limiting, an adaptive rate limiter limits concurrency rather than rate,
+// and requests must Acquire and Release a permit
 type RateLimiter interface {
 	State
 	Actuator
 	Allow() bool
+	AllowPriority(priority string) bool
 	StatusCode() int
 	Limit() rate.Limit
 	Burst() int
+	IsAdaptive() bool
+	IsKeyed() bool
+	Key(req *http.Request) string
+	AllowKey(key, priority string) bool
+	Acquire(priority string) bool
+	Release(latency time.Duration, failure bool)
+	ConcurrencyLimit() int
 }
 
 type RateLimiterConfig struct {
@@ -29,6 +41,21 @@ type RateLimiterConfig struct {
 	Limit      rate.Limit
 	Burst      int
 	Threshold  string
+	Adaptive   *AdaptiveLimitConfig
+
+	// Per key rate limiting, each key has its own limiter with the configured limit and burst
+	KeyHeader      string        // request header containing the key, X-Forwarded-For keys by client IP
+	TrustedProxies []string      // addresses or CIDR ranges of proxies trusted to append to X-Forwarded-For
+	KeyFn          KeyExtractor  `json:"-"` // custom key extraction, used instead of the header
+	MaxKeys        int           // maximum keys, the least recently used key is evicted
+	KeyIdleTimeout time.Duration // keys that are idle for the timeout are expired
+	HashKey        bool          // log a hash of the key rather than the key
+
+	// Floor and ceiling for percentage adjustments, 0 is unbounded
+	MinLimit rate.Limit
+	MaxLimit rate.Limit
+	MinBurst int
+	MaxBurst int
 }
 
 var nilRateLimiter = newRateLimiter(NilBehaviorName, nil, NewRateLimiterConfig(false, 0, 1, 1, ""))
@@ -51,6 +78,9 @@ type rateLimiter struct {
 	table       *table
 	config      RateLimiterConfig
 	rateLimiter *rate.Limiter
+	adaptive    *adaptiveLimit
+	keys        *keyLimiters
+	trusted     []*net.IPNet
 }
 
 func cloneRateLimiter(curr *rateLimiter) *rateLimiter {
@@ -68,6 +98,13 @@ func newRateLimiter(name string, table *table, config *RateLimiterConfig) *rateL
 		t.config = *config
 	}
 	t.rateLimiter = rate.NewLimiter(t.config.Limit, t.config.Burst)
+	if t.config.Adaptive != nil {
+		t.adaptive = newAdaptiveLimit(t.config.Adaptive)
+	}
+	if t.config.KeyHeader != "" || t.config.KeyFn != nil {
+		t.keys = newKeyLimiters(t.config.Limit, t.config.Burst, t.config.MaxKeys, t.config.KeyIdleTimeout)
+	}
+	t.trusted, _ = parseTrustedProxies(t.config.TrustedProxies)
 	return t
 }
 
@@ -78,14 +115,54 @@ func (r *rateLimiter) validate() error {
 	if r.config.Burst < 0 {
 		return errors.New(fmt.Sprintf("invalid configuration: RateLimiter burst is < 0 [%v]", r.name))
 	}
+	if r.config.MaxKeys < 0 {
+		return errors.New(fmt.Sprintf("invalid configuration: RateLimiter max keys is < 0 [%v]", r.name))
+	}
+	if r.config.KeyIdleTimeout < 0 {
+		return errors.New(fmt.Sprintf("invalid configuration: RateLimiter key idle timeout is < 0 [%v]", r.name))
+	}
+	if _, err := parseTrustedProxies(r.config.TrustedProxies); err != nil {
+		return errors.New(fmt.Sprintf("invalid configuration: RateLimiter %v [%v]", err, r.name))
+	}
+	if err := validateBounds(r.config.MinLimit, r.config.MaxLimit, r.config.MinBurst, r.config.MaxBurst); err != nil {
+		return errors.New(fmt.Sprintf("invalid configuration: RateLimiter %v [%v]", err, r.name))
+	}
+	if r.config.Adaptive != nil {
+		if r.keys != nil {
+			return errors.New(fmt.Sprintf("invalid configuration: RateLimiter adaptive limits can not be keyed [%v]", r.name))
+		}
+		return r.config.Adaptive.validate(r.name)
+	}
 	return nil
 }
 
+// syncKeys - set the key limiters to the configured limit and burst. The key limiters are shared by the clones
+// of a rate limiter, so keys keep their state across signals, overrides and rollbacks
+func (r *rateLimiter) syncKeys() {
+	r.keys.set(r.config.Limit, r.config.Burst)
+}
+
+// rateLimiterKey - the key logged for a limited request
+func rateLimiterKey(r *rateLimiter, req *http.Request, statusFlags string) string {
+	if r == nil || !r.IsEnabled() || !r.IsKeyed() || statusFlags != RateLimitFlag {
+		return ""
+	}
+	key := r.Key(req)
+	if r.config.HashKey {
+		return HashKey(key)
+	}
+	return key
+}
+
 func rateLimiterState(r *rateLimiter) (rate.Limit, int, string) {
 	var limit rate.Limit = -1
 	var burst = -1
 	var threshold = ""
 
+	if r != nil && r.IsEnabled() && r.IsAdaptive() {
+		// The computed concurrency limit is logged as the limit, as there is no burst
+		return rate.Limit(r.ConcurrencyLimit()), burst, r.config.Threshold
+	}
 	if r != nil && r.IsEnabled() {
 		limit = r.config.Limit
 		if limit == rate.Inf {
@@ -122,6 +199,10 @@ func (r *rateLimiter) Signal(values url.Values) error {
 	if values == nil {
 		return errors.New("invalid argument: values are nil for rate limiter signal")
 	}
+	pct, err := ParsePercentage(values)
+	if err != nil {
+		return err
+	}
 	UpdateEnable(r, values)
 	limit, burst, err := ParseLimitAndBurst(values)
 	if err != nil {
@@ -138,10 +219,17 @@ func (r *rateLimiter) Signal(values url.Values) error {
 			r.setRateLimiter(limit, burst)
 		}
 	}
+	if pct != NilPercentageValue {
+		r.adjustRateLimiter(pct)
+	}
 	return nil
 }
 
+// Allow - for an adaptive rate limiter, determine if a permit is available without acquiring it
 func (r *rateLimiter) Allow() bool {
+	if r.adaptive != nil {
+		return r.adaptive.available(0)
+	}
 	if r.config.Limit == rate.Inf {
 		return true
 	}
@@ -160,6 +248,86 @@ func (r *rateLimiter) Burst() int {
 	return r.config.Burst
 }
 
+func (r *rateLimiter) IsAdaptive() bool {
+	return r.adaptive != nil
+}
+
+func (r *rateLimiter) IsKeyed() bool {
+	return r.keys != nil
+}
+
+// Key - extract the rate limiter key from a request, returns "" if the rate limiter is not keyed
+func (r *rateLimiter) Key(req *http.Request) string {
+	if r.keys == nil || req == nil {
+		return ""
+	}
+	if r.config.KeyFn != nil {
+		return r.config.KeyFn(req)
+	}
+	if strings.EqualFold(r.config.KeyHeader, ForwardedForHeaderName) {
+		return ClientIP(req, r.trusted)
+	}
+	return req.Header.Get(r.config.KeyHeader)
+}
+
+// AllowKey - allow a request using the limiter for the key, requests without a key use the route limiter
+func (r *rateLimiter) AllowKey(key, priority string) bool {
+	if r.keys == nil || key == "" || priority == PriorityCritical {
+		return r.AllowPriority(priority)
+	}
+	if r.config.Limit == rate.Inf {
+		return true
+	}
+	return allowPriority(r.keys.get(key), r.config.Burst, priority)
+}
+
+// AllowPriority - allow a request, shedding lower priorities first by reserving capacity for higher
+// priorities. Critical requests are always allowed
+func (r *rateLimiter) AllowPriority(priority string) bool {
+	if priority == PriorityCritical {
+		return true
+	}
+	if r.adaptive != nil {
+		return r.adaptive.available(priorityReserve[priority])
+	}
+	if r.config.Limit == rate.Inf {
+		return true
+	}
+	return allowPriority(r.rateLimiter, r.config.Burst, priority)
+}
+
+func allowPriority(limiter *rate.Limiter, burst int, priority string) bool {
+	if reserve := priorityReserve[priority]; reserve > 0 && limiter.Tokens() < reserve*float64(burst) {
+		return false
+	}
+	return limiter.Allow()
+}
+
+// Acquire - acquire an adaptive concurrency permit, lower priorities are shed first. A successful Acquire
+// must be paired with a call to Release
+func (r *rateLimiter) Acquire(priority string) bool {
+	if r.adaptive == nil {
+		return r.AllowPriority(priority)
+	}
+	return r.adaptive.acquire(priorityReserve[priority], priority == PriorityCritical)
+}
+
+// Release - release an adaptive concurrency permit, the latency and outcome of the request adjust the limit
+func (r *rateLimiter) Release(latency time.Duration, failure bool) {
+	if r.adaptive == nil {
+		return
+	}
+	r.adaptive.release(latency, failure)
+}
+
+// ConcurrencyLimit - the current computed concurrency limit of an adaptive rate limiter, -1 if not adaptive
+func (r *rateLimiter) ConcurrencyLimit() int {
+	if r.adaptive == nil {
+		return -1
+	}
+	return r.adaptive.current()
+}
+
 /*
 
 
@@ -189,45 +357,23 @@ func (r *rateLimiter) SetRateLimiter(limit rate.Limit, burst int) {
 	r.table.setRateLimiter(r.name, RateLimiterConfig{Limit: limit, Burst: burst})
 }
 
-func (r *rateLimiter) AdjustRateLimiter(percentage int) bool {
-	newLimit, ok := limitAdjust(float64(r.config.Limit), percentage)
-	if !ok {
-		return false
-	}
-	newBurst, ok1 := burstAdjust(r.config.Burst, percentage)
-	if !ok1 {
-		return false
-	}
-	r.table.setRateLimiter(r.name, RateLimiterConfig{Limit: rate.Limit(newLimit), Burst: newBurst})
-	return true
-}
-
-func limitAdjust(val float64, percentage int) (float64, bool) {
-	change := (math.Abs(float64(percentage)) / 100.0) * val
-	if change >= val {
-		return val, false
-	}
-	if percentage > 0 {
-		return val + change, true
-	}
-	return val - change, true
-}
+*/
 
-func burstAdjust(val int, percentage int) (int, bool) {
-	floatChange := (math.Abs(float64(percentage)) / 100.0) * float64(val)
-	change := int(math.Round(floatChange))
-	if change == 0 || change >= val {
-		return val, false
+func (r *rateLimiter) enableRateLimiter(enabled bool) {
+	if r.table == nil || r.IsNil() {
+		return
 	}
-	if percentage > 0 {
-		return val + change, true
+	r.table.mu.Lock()
+	defer r.table.mu.Unlock()
+	if ctrl, ok := r.table.controllers[r.name]; ok {
+		c := cloneRateLimiter(ctrl.rateLimiter)
+		c.config.Enabled = enabled
+		r.table.update(r.name, cloneController[*rateLimiter](ctrl, c))
 	}
-	return val - change, true
 }
 
-*/
-
-func (r *rateLimiter) enableRateLimiter(enabled bool) {
+// adjustRateLimiter - scale the current limit and burst by a percentage, within the configured bounds
+func (r *rateLimiter) adjustRateLimiter(pct float64) {
 	if r.table == nil || r.IsNil() {
 		return
 	}
@@ -235,7 +381,13 @@ func (r *rateLimiter) enableRateLimiter(enabled bool) {
 	defer r.table.mu.Unlock()
 	if ctrl, ok := r.table.controllers[r.name]; ok {
 		c := cloneRateLimiter(ctrl.rateLimiter)
-		c.config.Enabled = enabled
+		c.config.Limit = limitAdjust(c.config.Limit, pct, c.config.MinLimit, c.config.MaxLimit)
+		c.config.Burst = burstAdjust(c.config.Burst, pct, c.config.MinBurst, c.config.MaxBurst)
+		if c.config.Limit == ctrl.rateLimiter.config.Limit && c.config.Burst == ctrl.rateLimiter.config.Burst {
+			return
+		}
+		c.rateLimiter = rate.NewLimiter(c.config.Limit, c.config.Burst)
+		c.syncKeys()
 		r.table.update(r.name, cloneController[*rateLimiter](ctrl, c))
 	}
 }
@@ -252,6 +404,7 @@ func (r *rateLimiter) setRateLimiter(limit rate.Limit, burst int) {
 		c.config.Burst = burst
 		// Not cloning the limiter as an old reference will not cause stale data when logging
 		c.rateLimiter = rate.NewLimiter(limit, burst)
+		c.syncKeys()
 		r.table.update(r.name, cloneController[*rateLimiter](ctrl, c))
 	}
 }
@@ -147,3 +147,46 @@ func _ExampleRateLimiter_Signal() {
 	//test: Signal(88,5) -> [error:<nil>] [state:map[burst:5 rateLimit:88]]
 
 }
+
+func ExampleRateLimiter_Signal_Percentage() {
+	t := newTable(true, false)
+	config := NewRateLimiterConfig(true, 503, 100, 10, "")
+	config.MinLimit = 50
+	config.MaxBurst = 12
+	t.AddController(newRoute("pct-route", config))
+
+	err := t.LookupByName("pct-route").RateLimiter().Signal(url.Values{PercentKey: {"-20"}})
+	rl := t.LookupByName("pct-route").RateLimiter()
+	fmt.Printf("test: Signal(pct=-20) -> [err:%v] [limit:%v] [burst:%v]\n", err, rl.Limit(), rl.Burst())
+
+	err = t.LookupByName("pct-route").RateLimiter().Signal(url.Values{PercentKey: {"-50"}})
+	rl = t.LookupByName("pct-route").RateLimiter()
+	fmt.Printf("test: Signal(pct=-50) -> [err:%v] [limit:%v] [burst:%v]\n", err, rl.Limit(), rl.Burst())
+
+	err = t.LookupByName("pct-route").RateLimiter().Signal(url.Values{PercentKey: {"100"}})
+	rl = t.LookupByName("pct-route").RateLimiter()
+	fmt.Printf("test: Signal(pct=100) -> [err:%v] [limit:%v] [burst:%v]\n", err, rl.Limit(), rl.Burst())
+
+	for _, pct := range []string{"abc", "0", "-100", "5000"} {
+		err = t.LookupByName("pct-route").RateLimiter().Signal(url.Values{PercentKey: {pct}, EnabledKey: {FalseValue}})
+		rl = t.LookupByName("pct-route").RateLimiter()
+		fmt.Printf("test: Signal(pct=%v) -> [err:%v] [enabled:%v] [limit:%v]\n", pct, err, rl.IsEnabled(), rl.Limit())
+	}
+
+	config = NewRateLimiterConfig(true, 503, 100, 10, "")
+	config.MinBurst = 20
+	config.MaxBurst = 5
+	errs := t.AddController(newRoute("invalid-route", config))
+	fmt.Printf("test: AddController(bounds) -> %v\n", errs)
+
+	//Output:
+	//test: Signal(pct=-20) -> [err:<nil>] [limit:80] [burst:8]
+	//test: Signal(pct=-50) -> [err:<nil>] [limit:50] [burst:4]
+	//test: Signal(pct=100) -> [err:<nil>] [limit:100] [burst:8]
+	//test: Signal(pct=abc) -> [err:invalid argument: percentage value must be > -100 and <= 1000, excluding 0 [abc]] [enabled:true] [limit:100]
+	//test: Signal(pct=0) -> [err:invalid argument: percentage value must be > -100 and <= 1000, excluding 0 [0]] [enabled:true] [limit:100]
+	//test: Signal(pct=-100) -> [err:invalid argument: percentage value must be > -100 and <= 1000, excluding 0 [-100]] [enabled:true] [limit:100]
+	//test: Signal(pct=5000) -> [err:invalid argument: percentage value must be > -100 and <= 1000, excluding 0 [5000]] [enabled:true] [limit:100]
+	//test: AddController(bounds) -> [invalid configuration: RateLimiter limit and burst floors are greater than the ceilings [invalid-route]]
+
+}
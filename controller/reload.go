@@ -0,0 +1,198 @@
+package controller
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"time"
+)
+
+const (
+	DefaultWatchInterval = time.Second * 5
+)
+
+// Reload - replace the table routes with the configuration. Routes are added, updated and removed atomically, and
+// nothing is applied if any route is invalid. Controllers with an unchanged route are kept, as are behaviors
+// with an unchanged configuration, so runtime state and actuator changes survive a reload.
+func (t *table) Reload(config []RouteConfig) []error {
+	var errs []error
+	var hostCtrl, defaultCtrl *controller
+	controllers := make(map[string]*controller, len(config))
+	patterns := newPatternTrie()
+
+	for _, c := range config {
+		route, err := NewRouteFromConfig(c)
+		if err != nil {
+			errs = append(errs, errors.New(fmt.Sprintf("invalid configuration: %v [%v]", err, c.Name)))
+			continue
+		}
+		var ctrl *controller
+		var errs1 []error
+		switch route.Name {
+		case "":
+			errs1 = []error{errors.New("invalid argument: route name is empty")}
+		case HostControllerName:
+			ctrl, errs1 = t.newHostController(route)
+			hostCtrl = ctrl
+		case DefaultEgressRouteName, DefaultIngressRouteName, DefaultControllerName:
+			ctrl, errs1 = t.newController(route)
+			defaultCtrl = ctrl
+		default:
+			if _, ok := controllers[route.Name]; ok {
+				errs1 = []error{errors.New(fmt.Sprintf("invalid argument: route name is a duplicate [%v]", route.Name))}
+				break
+			}
+			ctrl, errs1 = t.newController(route)
+			if len(errs1) == 0 && route.Pattern != "" {
+				if err = patterns.add(route.Name, route.Pattern); err != nil {
+					errs1 = []error{err}
+				}
+			}
+			controllers[route.Name] = ctrl
+		}
+		errs = append(errs, errs1...)
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+
+	t.signalMu.Lock()
+	defer t.signalMu.Unlock()
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for name, ctrl := range controllers {
+		controllers[name] = reloadController(t.controllers[name], ctrl)
+		if controllers[name] != t.controllers[name] {
+			t.history.remove(name)
+			t.removeOverrides(name)
+		}
+	}
+	for name := range t.controllers {
+		if _, ok := controllers[name]; !ok {
+			t.history.remove(name)
+			t.removeOverrides(name)
+		}
+	}
+	if hostCtrl != nil {
+		t.hostCtrl = reloadController(t.hostCtrl, hostCtrl)
+	}
+	if defaultCtrl != nil {
+		t.defaultCtrl = reloadController(t.defaultCtrl, defaultCtrl)
+	}
+	t.controllers = controllers
+	t.patterns = patterns
+	return nil
+}
+
+// reloadController - keep the current controller if the route is unchanged, and the current behaviors with
+// runtime state if their configuration is unchanged. The proxy action is not configurable, so it is ignored when
+// comparing routes, and the current action is kept.
+func reloadController(curr, ctrl *controller) *controller {
+	if curr == nil {
+		return ctrl
+	}
+	route := routeWithoutAction(curr.route)
+	if reflect.DeepEqual(route, ctrl.route) {
+		return curr
+	}
+	if route.RateLimiter != nil && reflect.DeepEqual(route.RateLimiter, ctrl.route.RateLimiter) {
+		ctrl.rateLimiter = curr.rateLimiter
+	}
+	if route.Retry != nil && reflect.DeepEqual(route.Retry, ctrl.route.Retry) {
+		ctrl.retry = curr.retry
+	}
+	if route.Proxy != nil && reflect.DeepEqual(route.Proxy, ctrl.route.Proxy) {
+		ctrl.route.Proxy = curr.route.Proxy
+		ctrl.proxy = curr.proxy
+	} else if route.Proxy != nil && ctrl.route.Proxy != nil && ctrl.route.Proxy.Action == nil {
+		ctrl.route.Proxy.Action = curr.proxy.Action()
+		ctrl.proxy.config.Action = curr.proxy.Action()
+	}
+	if route.CircuitBreaker != nil && reflect.DeepEqual(route.CircuitBreaker, ctrl.route.CircuitBreaker) {
+		ctrl.circuitBreaker = curr.circuitBreaker
+	}
+	if route.Bulkhead != nil && reflect.DeepEqual(route.Bulkhead, ctrl.route.Bulkhead) {
+		ctrl.bulkhead = curr.bulkhead
+	}
+	if route.Mirror != nil && reflect.DeepEqual(route.Mirror, ctrl.route.Mirror) {
+		ctrl.mirror = curr.mirror
+	}
+	if route.Pool != nil && reflect.DeepEqual(route.Pool, ctrl.route.Pool) {
+		ctrl.pool = curr.pool
+	}
+	if route.HealthCheck != nil && reflect.DeepEqual(route.HealthCheck, ctrl.route.HealthCheck) {
+		ctrl.healthCheck = curr.healthCheck
+	}
+	return ctrl
+}
+
+// routeWithoutAction - a copy of the route with the proxy action removed
+func routeWithoutAction(route Route) Route {
+	if route.Proxy != nil && route.Proxy.Action != nil {
+		config := *route.Proxy
+		config.Action = nil
+		route.Proxy = &config
+	}
+	return route
+}
+
+// ReloadRoutes - reload the table from the []byte representation of a route configuration
+func ReloadRoutes(t Table, buf []byte) []error {
+	var config []RouteConfig
+
+	if t == nil {
+		return []error{errors.New("invalid argument: table is nil")}
+	}
+	if buf == nil {
+		return []error{errors.New("invalid argument: buffer is nil")}
+	}
+	err := json.Unmarshal(buf, &config)
+	if err != nil {
+		return []error{err}
+	}
+	return t.Reload(config)
+}
+
+// WatchRoutes - poll the route configuration file, and reload the table when the file changes. The notify function
+// is called with the result of each reload, and the returned function stops the watcher.
+func WatchRoutes(t Table, path string, interval time.Duration, notify func(errs []error)) (stop func(), err error) {
+	if t == nil {
+		return nil, errors.New("invalid argument: table is nil")
+	}
+	info, err1 := os.Stat(path)
+	if err1 != nil {
+		return nil, err1
+	}
+	if interval <= 0 {
+		interval = DefaultWatchInterval
+	}
+	modTime, size := info.ModTime(), info.Size()
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				info, err := os.Stat(path)
+				if err != nil || (info.ModTime().Equal(modTime) && info.Size() == size) {
+					continue
+				}
+				modTime, size = info.ModTime(), info.Size()
+				buf, err1 := os.ReadFile(path)
+				errs := []error{err1}
+				if err1 == nil {
+					errs = ReloadRoutes(t, buf)
+				}
+				if notify != nil {
+					notify(errs)
+				}
+			}
+		}
+	}()
+	return func() { close(done) }, nil
+}
@@ -0,0 +1,116 @@
+package controller
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const (
+	reloadConfig = `[
+	{"Name":"google-search","Pattern":"www.google.com/search","RateLimiter":{"Enabled":true,"Limit":100,"Burst":10}},
+	{"Name":"facebook","Pattern":"www.facebook.com","Timeout":{"Enabled":true,"Duration":"500ms"}}
+]`
+	reloadUpdateConfig = `[
+	{"Name":"google-search","Pattern":"www.google.com/search","RateLimiter":{"Enabled":true,"Limit":100,"Burst":10},"Timeout":{"Enabled":true,"Duration":"1s"}},
+	{"Name":"twitter","Pattern":"www.twitter.com"}
+]`
+	reloadStateConfig = `[
+	{"Name":"state-route","Proxy":{"Enabled":true,"Pattern":"http://localhost:8080","Failover":true,"Threshold":"5"},"CircuitBreaker":{"Enabled":true,"ConsecutiveFailures":5,"CoolDown":"30s"},"Bulkhead":{"Enabled":true,"MaxConcurrent":10},"Pool":{"Enabled":true,"Endpoints":["localhost:8081","localhost:8082"]}}
+]`
+	reloadStateUpdateConfig = `[
+	{"Name":"state-route","Timeout":{"Enabled":true,"Duration":"1s"},"Proxy":{"Enabled":true,"Pattern":"http://localhost:8080","Failover":true,"Threshold":"5"},"CircuitBreaker":{"Enabled":true,"ConsecutiveFailures":5,"CoolDown":"30s"},"Bulkhead":{"Enabled":true,"MaxConcurrent":10},"Pool":{"Enabled":true,"Endpoints":["localhost:8081","localhost:8082"]}}
+]`
+	reloadStateProxyConfig = `[
+	{"Name":"state-route","Timeout":{"Enabled":true,"Duration":"1s"},"Proxy":{"Enabled":true,"Pattern":"http://localhost:9090","Failover":true,"Threshold":"5"},"CircuitBreaker":{"Enabled":true,"ConsecutiveFailures":5,"CoolDown":"30s"},"Bulkhead":{"Enabled":true,"MaxConcurrent":10},"Pool":{"Enabled":true,"Endpoints":["localhost:8081","localhost:8082"]}}
+]`
+	reloadInvalidConfig = `[
+	{"Name":"google-search","RateLimiter":{"Enabled":true,"Limit":-1}},
+	{"Name":"twitter","Timeout":{"Enabled":true,"Duration":"1x"}}
+]`
+)
+
+func ExampleTable_Reload() {
+	t := newTable(true, false)
+	errs := ReloadRoutes(t, []byte(reloadConfig))
+	limiter := t.LookupByName("google-search").t().rateLimiter
+	fmt.Printf("test: Reload() -> [errs:%v] [count:%v] [facebook:%v]\n", errs, t.count(), t.LookupUri("https://www.facebook.com/home", "GET").Name())
+
+	errs = ReloadRoutes(t, []byte(reloadInvalidConfig))
+	fmt.Printf("test: Reload(invalid) -> [errs:%v] [count:%v]\n", errs, t.count())
+
+	errs = ReloadRoutes(t, []byte(reloadUpdateConfig))
+	ctrl := t.LookupByName("google-search")
+	fmt.Printf("test: Reload(update) -> [errs:%v] [count:%v] [timeout:%v] [limiter:%v] [facebook:%v] [twitter:%v]\n", errs, t.count(), ctrl.Timeout().Duration(),
+		ctrl.t().rateLimiter == limiter, t.LookupByName("facebook") != nil, t.LookupUri("https://www.twitter.com", "GET").Name())
+
+	ctrl.Timeout().Disable()
+	errs = ReloadRoutes(t, []byte(reloadUpdateConfig))
+	fmt.Printf("test: Reload(unchanged) -> [errs:%v] [timeout:%v]\n", errs, t.LookupByName("google-search").Timeout().IsEnabled())
+
+	//Output:
+	//test: Reload() -> [errs:[]] [count:2] [facebook:facebook]
+	//test: Reload(invalid) -> [errs:[invalid configuration: RateLimiter limit is < 0 [google-search] invalid configuration: strconv.Atoi: parsing "1x": invalid syntax [twitter]]] [count:2]
+	//test: Reload(update) -> [errs:[]] [count:2] [timeout:1s] [limiter:true] [facebook:false] [twitter:twitter]
+	//test: Reload(unchanged) -> [errs:[]] [timeout:false]
+
+}
+
+func ExampleTable_Reload_State() {
+	var config []RouteConfig
+	t := newTable(true, false)
+	json.Unmarshal([]byte(reloadStateConfig), &config)
+	route, _ := NewRouteFromConfig(config[0])
+	route.Proxy.Action = failoverAction{}
+	errs := t.AddController(route)
+	curr := t.LookupByName("state-route").t()
+	fmt.Printf("test: AddController() -> [errs:%v] [action:%v]\n", errs, curr.proxy.Action() != nil)
+
+	errs = ReloadRoutes(t, []byte(reloadStateConfig))
+	fmt.Printf("test: Reload(unchanged) -> [errs:%v] [controller:%v]\n", errs, t.LookupByName("state-route").t() == curr)
+
+	errs = ReloadRoutes(t, []byte(reloadStateUpdateConfig))
+	ctrl := t.LookupByName("state-route").t()
+	fmt.Printf("test: Reload(timeout) -> [errs:%v] [controller:%v] [action:%v] [proxy:%v] [breaker:%v] [bulkhead:%v] [pool:%v]\n", errs, ctrl == curr, ctrl.proxy.Action() != nil,
+		ctrl.proxy == curr.proxy, ctrl.circuitBreaker == curr.circuitBreaker, ctrl.bulkhead == curr.bulkhead, ctrl.pool == curr.pool)
+
+	errs = ReloadRoutes(t, []byte(reloadStateProxyConfig))
+	ctrl = t.LookupByName("state-route").t()
+	fmt.Printf("test: Reload(proxy) -> [errs:%v] [action:%v] [pattern:%v] [proxy:%v] [breaker:%v]\n", errs, ctrl.proxy.Action() != nil, ctrl.proxy.Pattern(),
+		ctrl.proxy == curr.proxy, ctrl.circuitBreaker == curr.circuitBreaker)
+
+	//Output:
+	//test: AddController() -> [errs:[]] [action:true]
+	//test: Reload(unchanged) -> [errs:[]] [controller:true]
+	//test: Reload(timeout) -> [errs:[]] [controller:false] [action:true] [proxy:true] [breaker:true] [bulkhead:true] [pool:true]
+	//test: Reload(proxy) -> [errs:[]] [action:true] [pattern:http://localhost:9090] [proxy:false] [breaker:true]
+
+}
+
+func ExampleWatchRoutes() {
+	t := newTable(true, false)
+	path := filepath.Join(os.TempDir(), "watch-routes.json")
+	os.WriteFile(path, []byte(reloadConfig), 0644)
+	defer os.Remove(path)
+
+	done := make(chan []error, 1)
+	stop, err := WatchRoutes(t, path, time.Millisecond*10, func(errs []error) { done <- errs })
+	fmt.Printf("test: WatchRoutes() -> [err:%v] [count:%v]\n", err, t.count())
+	defer stop()
+
+	time.Sleep(time.Millisecond * 20)
+	os.WriteFile(path, []byte(reloadUpdateConfig+" "), 0644)
+	errs := <-done
+	fmt.Printf("test: WatchRoutes(changed) -> [errs:%v] [twitter:%v]\n", errs, t.LookupByName("twitter") != nil)
+
+	_, err = WatchRoutes(t, "", 0, nil)
+	fmt.Printf("test: WatchRoutes(invalid) -> [err:%v]\n", err)
+
+	//Output:
+	//test: WatchRoutes() -> [err:<nil>] [count:0]
+	//test: WatchRoutes(changed) -> [errs:[]] [twitter:true]
+	//test: WatchRoutes(invalid) -> [err:stat : no such file or directory]
+
+}
@@ -1,34 +1,98 @@
 package controller
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"golang.org/x/time/rate"
+	"google.golang.org/grpc/codes"
+	"io"
+	"math"
 	"math/rand"
+	"net"
+	"net/http"
 	"net/url"
+	"strconv"
+	"sync"
+	"syscall"
 	"time"
 )
 
 // https://aws.amazon.com/blogs/architecture/exponential-backoff-and-jitter/
 // https://github.com/keikoproj/inverse-exp-backoff
 
+const (
+	ConstantBackoff      = "constant"
+	ExponentialBackoff   = "exponential"
+	DecorrelatedBackoff  = "decorrelated"
+	DefaultRetryAttempts = 2
+	DefaultRetryBodySize = 1 << 20
+
+	// DefaultRetryAfterWait - the maximum wait for a Retry-After header when the retry has no maximum wait
+	DefaultRetryAfterWait = time.Minute
+
+	BudgetFlag = "BG"
+
+	DialError    = "dial"    // connection refused, host unreachable, DNS failures
+	ResetError   = "reset"   // connection reset or closed by the upstream
+	TimeoutError = "timeout" // upstream timeouts, including timeouts from the Timeout behavior
+
+	retryBudgetReserve = 10
+
+	// maxBackoffWait - the backoff wait when there is no maximum wait, so that the jitter calculations do not overflow
+	maxBackoffWait = time.Duration(math.MaxInt64 / 4)
+)
+
 // Retry - interface for retries
 type Retry interface {
 	State
 	Actuator
 	IsValidStatusCode(statusCode int) bool
 	IsRetryable(statusCode int) (ok bool, status string)
+	IsRetryableError(err error) bool
+	IsRetryableTimeout() bool
+	IsRetryableMethod(method string) bool
+	IsRetryableCode(code codes.Code) bool
+	AllowRetry() (ok bool, status string)
+	Deposit()
+	Backoff(attempt int, prev time.Duration) time.Duration
+	Sleep(ctx context.Context, wait time.Duration) bool
 	Limit() rate.Limit
 	Burst() int
 	Wait() time.Duration
+	MaxWait() time.Duration
+	MaxAttempts() int
+	Budget() float64
+	MaxBodySize() int64
 }
 
 type RetryConfig struct {
-	Enabled     bool
-	Limit       rate.Limit
-	Burst       int
-	Wait        time.Duration
-	StatusCodes []int
+	Enabled       bool
+	Limit         rate.Limit
+	Burst         int
+	Wait          time.Duration
+	StatusCodes   []int
+	MaxAttempts   int           // total attempts including the original request, defaults to 2
+	Backoff       string        // constant, exponential or decorrelated, defaults to constant
+	MaxWait       time.Duration // ceiling for the exponential and decorrelated backoff, 0 is unbounded
+	Budget        float64       // ratio of retries to requests, 0 disables the budget
+	Errors        []string      // retryable transport error classes : dial, reset, timeout
+	NonIdempotent bool          // allow retries of methods that are not idempotent
+	MaxBodySize   int64         // maximum request body buffered for replay, larger requests are not retried
+	GRPCCodes     []int         // retryable gRPC status codes, the status codes are mapped when empty
+
+	// Floor and ceiling for percentage adjustments of the retry rate limiter, 0 is unbounded
+	MinLimit rate.Limit
+	MaxLimit rate.Limit
+	MinBurst int
+	MaxBurst int
+}
+
+// retryBudget - token bucket where each request deposits Budget tokens and each retry withdraws one token,
+// shared by all clones of a retry
+type retryBudget struct {
+	mu      sync.Mutex
+	balance float64
 }
 
 var nilRetry = newRetry(NilBehaviorName, nil, NewRetryConfig(false, 0, 0, 0, nil))
@@ -40,15 +104,18 @@ func NewRetryConfig(enabled bool, limit rate.Limit, burst int, wait time.Duratio
 	c.Burst = burst
 	c.StatusCodes = validCodes
 	c.Enabled = enabled
+	c.MaxAttempts = DefaultRetryAttempts
+	c.Backoff = ConstantBackoff
+	c.MaxBodySize = DefaultRetryBodySize
 	return c
 }
 
 type retry struct {
 	name        string
 	table       *table
-	rand        *rand.Rand
 	config      RetryConfig
 	rateLimiter *rate.Limiter
+	budget      *retryBudget
 }
 
 func cloneRetry(curr *retry) *retry {
@@ -61,11 +128,20 @@ func newRetry(name string, table *table, config *RetryConfig) *retry {
 	t := new(retry)
 	t.name = name
 	t.table = table
-	t.rand = rand.New(rand.NewSource(time.Now().UnixNano()))
 	if config != nil {
 		t.config = *config
 	}
+	if t.config.MaxAttempts <= 0 {
+		t.config.MaxAttempts = DefaultRetryAttempts
+	}
+	if t.config.Backoff == "" {
+		t.config.Backoff = ConstantBackoff
+	}
+	if t.config.MaxBodySize <= 0 {
+		t.config.MaxBodySize = DefaultRetryBodySize
+	}
 	t.rateLimiter = rate.NewLimiter(t.config.Limit, t.config.Burst)
+	t.budget = &retryBudget{balance: retryBudgetReserve}
 	return t
 }
 
@@ -82,9 +158,30 @@ func (r *retry) validate() error {
 	if r.config.Wait < 0 {
 		return errors.New(fmt.Sprintf("invalid configuration: wait duration is < 0 [%v]", r.name))
 	}
+	if r.config.MaxWait < 0 {
+		return errors.New(fmt.Sprintf("invalid configuration: max wait duration is < 0 [%v]", r.name))
+	}
+	if !isBackoff(r.config.Backoff) {
+		return errors.New(fmt.Sprintf("invalid configuration: backoff is invalid [%v] [%v]", r.config.Backoff, r.name))
+	}
+	if r.config.Budget < 0 || r.config.Budget > 1 {
+		return errors.New(fmt.Sprintf("invalid configuration: retry budget is not in the range 0..1 [%v]", r.name))
+	}
+	if err := validateBounds(r.config.MinLimit, r.config.MaxLimit, r.config.MinBurst, r.config.MaxBurst); err != nil {
+		return errors.New(fmt.Sprintf("invalid configuration: retry %v [%v]", err, r.name))
+	}
+	for _, class := range r.config.Errors {
+		if class != DialError && class != ResetError && class != TimeoutError {
+			return errors.New(fmt.Sprintf("invalid configuration: retry error class is invalid [%v] [%v]", class, r.name))
+		}
+	}
 	return nil
 }
 
+func isBackoff(backoff string) bool {
+	return backoff == ConstantBackoff || backoff == ExponentialBackoff || backoff == DecorrelatedBackoff
+}
+
 func retryState(r *retry) (rate.Limit, int) {
 	var limit rate.Limit = -1
 	var burst = -1
@@ -134,6 +231,10 @@ func (r *retry) Signal(values url.Values) error {
 	if values == nil {
 		return errors.New("invalid argument: values are nil for retry signal")
 	}
+	pct, err := ParsePercentage(values)
+	if err != nil {
+		return err
+	}
 	UpdateEnable(r, values)
 	limit, burst, err := ParseLimitAndBurst(values)
 	if err != nil {
@@ -150,6 +251,9 @@ func (r *retry) Signal(values url.Values) error {
 			r.setRetryRateLimiter(limit, burst)
 		}
 	}
+	if pct != NilPercentageValue {
+		r.adjustRetryRateLimiter(pct)
+	}
 	if values.Has(WaitKey) {
 		duration, err1 := ParseDuration(values.Get(WaitKey))
 		if err1 != nil {
@@ -162,7 +266,47 @@ func (r *retry) Signal(values url.Values) error {
 			r.setWait(duration)
 		}
 	}
-
+	config := r.config
+	if values.Has(AttemptsKey) {
+		attempts, err1 := strconv.Atoi(values.Get(AttemptsKey))
+		if err1 != nil {
+			return err1
+		}
+		if attempts < 1 {
+			return errors.New(fmt.Sprintf("invalid argument: attempts value is < 1 [%v]", attempts))
+		}
+		config.MaxAttempts = attempts
+	}
+	if values.Has(BackoffKey) {
+		backoff := values.Get(BackoffKey)
+		if !isBackoff(backoff) {
+			return errors.New(fmt.Sprintf("invalid argument: backoff value is invalid [%v]", backoff))
+		}
+		config.Backoff = backoff
+	}
+	if values.Has(MaxWaitKey) {
+		duration, err1 := ParseDuration(values.Get(MaxWaitKey))
+		if err1 != nil {
+			return err1
+		}
+		if duration < 0 {
+			return errors.New("invalid configuration: max wait duration is < 0")
+		}
+		config.MaxWait = duration
+	}
+	if values.Has(BudgetKey) {
+		budget, err1 := strconv.ParseFloat(values.Get(BudgetKey), 64)
+		if err1 != nil {
+			return err1
+		}
+		if budget < 0 || budget > 1 {
+			return errors.New(fmt.Sprintf("invalid argument: budget value is not in the range 0..1 [%v]", budget))
+		}
+		config.Budget = budget
+	}
+	if config.MaxAttempts != r.config.MaxAttempts || config.Backoff != r.config.Backoff || config.MaxWait != r.config.MaxWait || config.Budget != r.config.Budget {
+		r.setBackoff(config)
+	}
 	return nil
 }
 
@@ -184,14 +328,157 @@ func (r *retry) IsRetryable(statusCode int) (bool, string) {
 			if r.config.Wait == 0 {
 				return true, ""
 			}
-			jitter := time.Duration(r.rand.Int31n(1000))
-			time.Sleep(r.config.Wait + jitter)
+			time.Sleep(r.Backoff(1, 0))
 			return true, ""
 		}
 	}
 	return false, ""
 }
 
+// IsRetryableError - determine if a transport error belongs to one of the configured error classes
+func (r *retry) IsRetryableError(err error) bool {
+	if err == nil {
+		return false
+	}
+	return r.isErrorClass(ErrorClass(err))
+}
+
+// IsRetryableCode - determine if a gRPC status code can be retried, either a configured gRPC code, or a code that
+// maps to a configured status code
+func (r *retry) IsRetryableCode(code codes.Code) bool {
+	if len(r.config.GRPCCodes) == 0 {
+		return code != codes.OK && r.IsValidStatusCode(HttpStatusFromCode(code))
+	}
+	for _, c := range r.config.GRPCCodes {
+		if codes.Code(c) == code {
+			return true
+		}
+	}
+	return false
+}
+
+// IsRetryableTimeout - determine if a timeout from the Timeout behavior can be retried
+func (r *retry) IsRetryableTimeout() bool {
+	return r.isErrorClass(TimeoutError)
+}
+
+func (r *retry) isErrorClass(class string) bool {
+	if class == "" {
+		return false
+	}
+	for _, c := range r.config.Errors {
+		if c == class {
+			return true
+		}
+	}
+	return false
+}
+
+// IsRetryableMethod - only idempotent methods are retried, unless the route allows non-idempotent retries
+func (r *retry) IsRetryableMethod(method string) bool {
+	if r.config.NonIdempotent {
+		return true
+	}
+	switch method {
+	case "", http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// AllowRetry - determine if the retry rate limiter and the retry budget allow another attempt, does not
+// check the status code or the attempt count
+func (r *retry) AllowRetry() (bool, string) {
+	if !r.rateLimiter.Allow() {
+		return false, RateLimitFlag
+	}
+	if r.config.Budget <= 0 {
+		return true, ""
+	}
+	r.budget.mu.Lock()
+	defer r.budget.mu.Unlock()
+	if r.budget.balance < 1 {
+		return false, BudgetFlag
+	}
+	r.budget.balance--
+	return true, ""
+}
+
+// Deposit - credit the retry budget for an original, non retried, request
+func (r *retry) Deposit() {
+	if r.config.Budget <= 0 {
+		return
+	}
+	r.budget.mu.Lock()
+	defer r.budget.mu.Unlock()
+	r.budget.balance += r.config.Budget
+	if r.budget.balance > retryBudgetReserve {
+		r.budget.balance = retryBudgetReserve
+	}
+}
+
+// Backoff - wait before the given retry attempt, attempt 1 being the first retry, prev is the wait returned
+// for the previous attempt
+func (r *retry) Backoff(attempt int, prev time.Duration) time.Duration {
+	base := r.config.Wait
+	if base <= 0 {
+		return 0
+	}
+	maxWait := r.config.MaxWait
+	if maxWait <= 0 || maxWait > maxBackoffWait {
+		maxWait = maxBackoffWait
+	}
+	var wait time.Duration
+	switch r.config.Backoff {
+	case ExponentialBackoff:
+		// Full jitter : random_between(0, min(cap, base * 2 ** attempt))
+		ceiling := base
+		for i := 1; i < attempt && ceiling < maxWait; i++ {
+			ceiling *= 2
+		}
+		if ceiling > maxWait {
+			ceiling = maxWait
+		}
+		wait = time.Duration(rand.Int63n(int64(ceiling) + 1))
+	case DecorrelatedBackoff:
+		// Decorrelated jitter : min(cap, random_between(base, prev * 3))
+		if base > maxWait {
+			base = maxWait
+		}
+		if prev < base {
+			prev = base
+		}
+		if prev > maxWait {
+			prev = maxWait
+		}
+		wait = base + time.Duration(rand.Int63n(int64(prev*3-base)+1))
+		if wait > maxWait {
+			wait = maxWait
+		}
+	default:
+		wait = base + time.Duration(rand.Int31n(1000))
+	}
+	return wait
+}
+
+// Sleep - wait for the backoff duration, returns false if the context is done before the wait has elapsed
+func (r *retry) Sleep(ctx context.Context, wait time.Duration) bool {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if wait <= 0 {
+		return ctx.Err() == nil
+	}
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
 func (r *retry) Limit() rate.Limit {
 	return r.config.Limit
 }
@@ -204,21 +491,21 @@ func (r *retry) Wait() time.Duration {
 	return r.config.Wait
 }
 
-/*
-func (r *retry) AdjustRateLimiter(percentage int) bool {
-	newLimit, ok := limitAdjust(float64(r.config.Limit), percentage)
-	if !ok {
-		return false
-	}
-	newBurst, ok1 := burstAdjust(r.config.Burst, percentage)
-	if !ok1 {
-		return false
-	}
-	r.table.setRateLimiter(r.name, RateLimiterConfig{Limit: rate.Limit(newLimit), Burst: newBurst})
-	return true
+func (r *retry) MaxWait() time.Duration {
+	return r.config.MaxWait
 }
 
-*/
+func (r *retry) MaxAttempts() int {
+	return r.config.MaxAttempts
+}
+
+func (r *retry) MaxBodySize() int64 {
+	return r.config.MaxBodySize
+}
+
+func (r *retry) Budget() float64 {
+	return r.config.Budget
+}
 
 //func (r *retry) LimitAndBurst() (rate.Limit, int) {
 //	return r.config.Limit, r.config.Burst
@@ -255,6 +542,25 @@ func (r *retry) enableRetry(enable bool) {
 	}
 }
 
+// adjustRetryRateLimiter - scale the current retry limit and burst by a percentage, within the configured bounds
+func (r *retry) adjustRetryRateLimiter(pct float64) {
+	if r.table == nil || r.IsNil() {
+		return
+	}
+	r.table.mu.Lock()
+	defer r.table.mu.Unlock()
+	if ctrl, ok := r.table.controllers[r.name]; ok {
+		c := cloneRetry(ctrl.retry)
+		c.config.Limit = limitAdjust(c.config.Limit, pct, c.config.MinLimit, c.config.MaxLimit)
+		c.config.Burst = burstAdjust(c.config.Burst, pct, c.config.MinBurst, c.config.MaxBurst)
+		if c.config.Limit == ctrl.retry.config.Limit && c.config.Burst == ctrl.retry.config.Burst {
+			return
+		}
+		c.rateLimiter = rate.NewLimiter(c.config.Limit, c.config.Burst)
+		r.table.update(r.name, cloneController[*retry](ctrl, c))
+	}
+}
+
 func (r *retry) setRetryRateLimiter(limit rate.Limit, burst int) {
 	if r.table == nil || r.IsNil() {
 		return
@@ -284,6 +590,22 @@ func (r *retry) setWait(duration time.Duration) {
 	}
 }
 
+func (r *retry) setBackoff(config RetryConfig) {
+	if r.table == nil || r.IsNil() {
+		return
+	}
+	r.table.mu.Lock()
+	defer r.table.mu.Unlock()
+	if ctrl, ok := r.table.controllers[r.name]; ok {
+		c := cloneRetry(ctrl.retry)
+		c.config.MaxAttempts = config.MaxAttempts
+		c.config.Backoff = config.Backoff
+		c.config.MaxWait = config.MaxWait
+		c.config.Budget = config.Budget
+		r.table.update(r.name, cloneController[*retry](ctrl, c))
+	}
+}
+
 /*
 func (r *retry) setRetryRateBurst(burst int) {
 	if r.table == nil {
@@ -302,3 +624,68 @@ func (r *retry) setRetryRateBurst(burst int) {
 
 
 */
+
+// ErrorClass - classify a transport error as a dial, reset or timeout error, returns "" if the error is
+// not a transport error
+func ErrorClass(err error) string {
+	if err == nil {
+		return ""
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return TimeoutError
+	}
+	if errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.EPIPE) || errors.Is(err, syscall.ECONNABORTED) ||
+		errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return ResetError
+	}
+	if errors.Is(err, syscall.ECONNREFUSED) || errors.Is(err, syscall.EHOSTUNREACH) || errors.Is(err, syscall.ENETUNREACH) {
+		return DialError
+	}
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		return DialError
+	}
+	var opErr *net.OpError
+	if errors.As(err, &opErr) && opErr.Op == "dial" {
+		if opErr.Timeout() {
+			return TimeoutError
+		}
+		return DialError
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return TimeoutError
+	}
+	return ""
+}
+
+// RetryAfter - parse the Retry-After header of a 429 or 503 response, the header can be delay-seconds or
+// an HTTP-date. Negative delays, and delays that overflow a time.Duration, are invalid
+func RetryAfter(resp *http.Response) (time.Duration, bool) {
+	if resp == nil || resp.Header == nil {
+		return 0, false
+	}
+	if resp.StatusCode != http.StatusTooManyRequests && resp.StatusCode != http.StatusServiceUnavailable {
+		return 0, false
+	}
+	v := Trim(resp.Header.Get(RetryAfterHeaderName))
+	if v == "" {
+		return 0, false
+	}
+	if v[0] == '-' || v[0] == '+' || (v[0] >= '0' && v[0] <= '9') {
+		seconds, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || seconds < 0 || seconds > int64(math.MaxInt64/time.Second) {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+	t, err := http.ParseTime(v)
+	if err != nil {
+		return 0, false
+	}
+	d := time.Until(t)
+	if d < 0 {
+		d = 0
+	}
+	return d, true
+}
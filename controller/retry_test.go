@@ -1,7 +1,14 @@
 package controller
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"golang.org/x/time/rate"
+	"net"
+	"net/http"
+	"net/url"
+	"syscall"
 	"time"
 )
 
@@ -174,3 +181,229 @@ func ExampleRetry_IsRetryable_RateLimit() {
 	//test: IsRetryable(504) -> [ok:true] [status:]
 
 }
+
+func ExampleRetry_Backoff() {
+	config := NewRetryConfig(true, 100, 10, time.Millisecond*100, []int{503})
+	rt := newRetry("test-route", nil, config)
+	wait := rt.Backoff(1, 0)
+	fmt.Printf("test: Backoff(constant) -> [attempts:%v] [in-range:%v]\n", rt.MaxAttempts(), wait >= time.Millisecond*100 && wait < time.Millisecond*100+time.Microsecond)
+
+	config.Backoff = ExponentialBackoff
+	config.MaxWait = time.Millisecond * 300
+	rt = newRetry("test-route", nil, config)
+	inRange := true
+	for attempt := 1; attempt <= 5; attempt++ {
+		ceiling := time.Millisecond * 100 << (attempt - 1)
+		if ceiling > config.MaxWait {
+			ceiling = config.MaxWait
+		}
+		if wait = rt.Backoff(attempt, 0); wait < 0 || wait > ceiling {
+			inRange = false
+		}
+	}
+	fmt.Printf("test: Backoff(exponential) -> [in-range:%v]\n", inRange)
+
+	config.Backoff = DecorrelatedBackoff
+	rt = newRetry("test-route", nil, config)
+	inRange = true
+	wait = 0
+	for attempt := 1; attempt <= 5; attempt++ {
+		if wait = rt.Backoff(attempt, wait); wait < time.Millisecond*100 || wait > config.MaxWait {
+			inRange = false
+		}
+	}
+	fmt.Printf("test: Backoff(decorrelated) -> [in-range:%v]\n", inRange)
+
+	config.Backoff = "linear"
+	fmt.Printf("test: validate() -> [%v]\n", newRetry("test-route", nil, config).validate())
+
+	//Output:
+	//test: Backoff(constant) -> [attempts:2] [in-range:true]
+	//test: Backoff(exponential) -> [in-range:true]
+	//test: Backoff(decorrelated) -> [in-range:true]
+	//test: validate() -> [invalid configuration: backoff is invalid [linear] [test-route]]
+
+}
+
+func ExampleRetry_Backoff_NoMaxWait() {
+	config := NewRetryConfig(true, 100, 10, time.Millisecond*100, []int{503})
+	config.Backoff = ExponentialBackoff
+	rt := newRetry("test-route", nil, config)
+	inRange := true
+	for attempt := 1; attempt <= 100; attempt++ {
+		if wait := rt.Backoff(attempt, 0); wait < 0 {
+			inRange = false
+		}
+	}
+	fmt.Printf("test: Backoff(exponential) -> [in-range:%v]\n", inRange)
+
+	config.Backoff = DecorrelatedBackoff
+	rt = newRetry("test-route", nil, config)
+	inRange = true
+	var wait time.Duration
+	for attempt := 1; attempt <= 100; attempt++ {
+		if wait = rt.Backoff(attempt, wait); wait < time.Millisecond*100 {
+			inRange = false
+		}
+	}
+	fmt.Printf("test: Backoff(decorrelated) -> [in-range:%v]\n", inRange)
+
+	//Output:
+	//test: Backoff(exponential) -> [in-range:true]
+	//test: Backoff(decorrelated) -> [in-range:true]
+
+}
+
+func ExampleRetry_Budget() {
+	config := NewRetryConfig(true, rate.Inf, 1, 0, []int{503})
+	config.Budget = 0.5
+	rt := newRetry("test-route", nil, config)
+
+	count := 0
+	for ok, _ := rt.AllowRetry(); ok; ok, _ = rt.AllowRetry() {
+		count++
+	}
+	_, status := rt.AllowRetry()
+	fmt.Printf("test: AllowRetry() -> [reserve:%v] [status:%v]\n", count, status)
+
+	rt.Deposit()
+	ok, _ := rt.AllowRetry()
+	fmt.Printf("test: Deposit() -> [allow:%v]\n", ok)
+
+	rt.Deposit()
+	rt.Deposit()
+	ok, _ = rt.AllowRetry()
+	fmt.Printf("test: Deposit(2) -> [allow:%v]\n", ok)
+
+	//Output:
+	//test: AllowRetry() -> [reserve:10] [status:BG]
+	//test: Deposit() -> [allow:false]
+	//test: Deposit(2) -> [allow:true]
+
+}
+
+func ExampleRetry_Sleep() {
+	rt := newRetry("test-route", nil, NewRetryConfig(true, 100, 10, 0, []int{503}))
+	fmt.Printf("test: Sleep(10ms) -> [%v]\n", rt.Sleep(context.Background(), time.Millisecond*10))
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*10)
+	defer cancel()
+	fmt.Printf("test: Sleep(timeout) -> [%v]\n", rt.Sleep(ctx, time.Second))
+
+	//Output:
+	//test: Sleep(10ms) -> [true]
+	//test: Sleep(timeout) -> [false]
+
+}
+
+func ExampleRetry_Signal_Backoff() {
+	name := "test-route"
+	t := newTable(true, false)
+	errs := t.AddController(newRoute(name, NewRetryConfig(true, 100, 10, time.Millisecond*10, []int{503})))
+	fmt.Printf("test: Add() -> [%v] [count:%v]\n", errs, t.count())
+
+	values := make(url.Values)
+	values.Add(AttemptsKey, "4")
+	values.Add(BackoffKey, ExponentialBackoff)
+	values.Add(MaxWaitKey, "2s")
+	values.Add(BudgetKey, "0.2")
+	err := t.LookupByName(name).Retry().Signal(values)
+	rt := t.LookupByName(name).Retry()
+	fmt.Printf("test: Signal() -> [error:%v] [attempts:%v] [max-wait:%v] [budget:%v]\n", err, rt.MaxAttempts(), rt.MaxWait(), rt.Budget())
+
+	err = rt.Signal(NewValues(BackoffKey, "linear"))
+	fmt.Printf("test: Signal(linear) -> [error:%v]\n", err)
+
+	//Output:
+	//test: Add() -> [[]] [count:1]
+	//test: Signal() -> [error:<nil>] [attempts:4] [max-wait:2s] [budget:0.2]
+	//test: Signal(linear) -> [error:invalid argument: backoff value is invalid [linear]]
+
+}
+
+func ExampleRetry_IsRetryableError() {
+	rt := newRetry("test-route", nil, NewRetryConfig(true, 100, 10, 0, []int{503}))
+	rt.config.Errors = []string{DialError, ResetError}
+	dial := &net.OpError{Op: "dial", Net: "tcp", Err: syscall.ECONNREFUSED}
+	reset := &net.OpError{Op: "read", Net: "tcp", Err: syscall.ECONNRESET}
+
+	fmt.Printf("test: ErrorClass() -> [dial:%v] [reset:%v] [timeout:%v] [other:%v]\n", ErrorClass(dial), ErrorClass(reset), ErrorClass(context.DeadlineExceeded), ErrorClass(errors.New("invalid")))
+	fmt.Printf("test: IsRetryableError() -> [dial:%v] [reset:%v] [timeout:%v] [nil:%v]\n", rt.IsRetryableError(dial), rt.IsRetryableError(reset), rt.IsRetryableError(context.DeadlineExceeded), rt.IsRetryableError(nil))
+	fmt.Printf("test: IsRetryableMethod() -> [GET:%v] [PUT:%v] [POST:%v] [PATCH:%v]\n", rt.IsRetryableMethod(http.MethodGet), rt.IsRetryableMethod(http.MethodPut), rt.IsRetryableMethod(http.MethodPost), rt.IsRetryableMethod(http.MethodPatch))
+
+	rt.config.NonIdempotent = true
+	fmt.Printf("test: IsRetryableMethod(non-idempotent) -> [POST:%v]\n", rt.IsRetryableMethod(http.MethodPost))
+
+	rt.config.Errors = []string{"refused"}
+	fmt.Printf("test: validate() -> [%v]\n", rt.validate())
+
+	//Output:
+	//test: ErrorClass() -> [dial:dial] [reset:reset] [timeout:timeout] [other:]
+	//test: IsRetryableError() -> [dial:true] [reset:true] [timeout:false] [nil:false]
+	//test: IsRetryableMethod() -> [GET:true] [PUT:true] [POST:false] [PATCH:false]
+	//test: IsRetryableMethod(non-idempotent) -> [POST:true]
+	//test: validate() -> [invalid configuration: retry error class is invalid [refused] [test-route]]
+
+}
+
+func ExampleRetryAfter() {
+	resp := &http.Response{StatusCode: http.StatusServiceUnavailable, Header: make(http.Header)}
+	resp.Header.Set(RetryAfterHeaderName, "2")
+	d, ok := RetryAfter(resp)
+	fmt.Printf("test: RetryAfter(seconds) -> [wait:%v] [ok:%v]\n", d, ok)
+
+	resp.Header.Set(RetryAfterHeaderName, time.Now().Add(time.Minute).UTC().Format(http.TimeFormat))
+	d, ok = RetryAfter(resp)
+	fmt.Printf("test: RetryAfter(http-date) -> [in-range:%v] [ok:%v]\n", d > time.Second*55 && d <= time.Minute, ok)
+
+	resp.StatusCode = http.StatusInternalServerError
+	d, ok = RetryAfter(resp)
+	fmt.Printf("test: RetryAfter(500) -> [wait:%v] [ok:%v]\n", d, ok)
+
+	resp.StatusCode = http.StatusTooManyRequests
+	resp.Header.Set(RetryAfterHeaderName, "soon")
+	d, ok = RetryAfter(resp)
+	fmt.Printf("test: RetryAfter(invalid) -> [wait:%v] [ok:%v]\n", d, ok)
+
+	resp.Header.Set(RetryAfterHeaderName, "-5")
+	d, ok = RetryAfter(resp)
+	fmt.Printf("test: RetryAfter(negative) -> [wait:%v] [ok:%v]\n", d, ok)
+
+	resp.Header.Set(RetryAfterHeaderName, "9223372036854775807")
+	d, ok = RetryAfter(resp)
+	fmt.Printf("test: RetryAfter(overflow) -> [wait:%v] [ok:%v]\n", d, ok)
+
+	resp.Header.Set(RetryAfterHeaderName, "99999999999999999999")
+	d, ok = RetryAfter(resp)
+	fmt.Printf("test: RetryAfter(out-of-range) -> [wait:%v] [ok:%v]\n", d, ok)
+
+	//Output:
+	//test: RetryAfter(seconds) -> [wait:2s] [ok:true]
+	//test: RetryAfter(http-date) -> [in-range:true] [ok:true]
+	//test: RetryAfter(500) -> [wait:0s] [ok:false]
+	//test: RetryAfter(invalid) -> [wait:0s] [ok:false]
+	//test: RetryAfter(negative) -> [wait:0s] [ok:false]
+	//test: RetryAfter(overflow) -> [wait:0s] [ok:false]
+	//test: RetryAfter(out-of-range) -> [wait:0s] [ok:false]
+
+}
+
+func ExampleRetry_Signal_Percentage() {
+	t := newTable(true, false)
+	config := NewRetryConfig(true, 10, 4, 0, []int{503})
+	config.MaxLimit = 15
+	t.AddController(newRoute("retry-pct-route", config))
+
+	err := t.LookupByName("retry-pct-route").Retry().Signal(url.Values{PercentKey: {"100"}})
+	r := t.LookupByName("retry-pct-route").Retry()
+	fmt.Printf("test: Signal(pct=100) -> [err:%v] [limit:%v] [burst:%v]\n", err, r.Limit(), r.Burst())
+
+	err = t.LookupByName("retry-pct-route").Retry().Signal(url.Values{PercentKey: {"-50"}})
+	r = t.LookupByName("retry-pct-route").Retry()
+	fmt.Printf("test: Signal(pct=-50) -> [err:%v] [limit:%v] [burst:%v]\n", err, r.Limit(), r.Burst())
+
+	//Output:
+	//test: Signal(pct=100) -> [err:<nil>] [limit:15] [burst:8]
+	//test: Signal(pct=-50) -> [err:<nil>] [limit:7.5] [burst:4]
+
+}
@@ -6,41 +6,154 @@ import (
 
 // Route - route data
 type Route struct {
-	Name        string
-	Pattern     string
-	Traffic     string // egress/ingress
-	Ping        bool   // health traffic
-	Protocol    string // gRPC, HTTP10, HTTP11, HTTP2, HTTP3
-	Timeout     *TimeoutConfig
-	RateLimiter *RateLimiterConfig
-	Retry       *RetryConfig
-	Proxy       *ProxyConfig
+	Name           string
+	Pattern        string
+	Traffic        string // egress/ingress
+	Ping           bool   // health traffic
+	Protocol       string // gRPC, HTTP10, HTTP11, HTTP2, HTTP3
+	Priority       string // critical, high, normal, low
+	Timeout        *TimeoutConfig
+	RateLimiter    *RateLimiterConfig
+	Retry          *RetryConfig
+	Proxy          *ProxyConfig
+	CircuitBreaker *CircuitBreakerConfig
+	Bulkhead       *BulkheadConfig
+	Hedge          *HedgeConfig
+	Mirror         *MirrorConfig
+	Pool           *PoolConfig
+	HealthCheck    *HealthCheckConfig
 }
 
 type TimeoutConfigJson struct {
 	Enabled    bool
 	StatusCode int
 	Duration   string
+	Propagate  bool
+}
+
+type RateLimiterConfigJson struct {
+	Enabled        bool
+	StatusCode     int
+	Limit          rate.Limit
+	Burst          int
+	Threshold      string
+	Adaptive       *AdaptiveLimitConfig
+	KeyHeader      string
+	TrustedProxies []string
+	MaxKeys        int
+	KeyIdleTimeout string
+	HashKey        bool
+	MinLimit       rate.Limit
+	MaxLimit       rate.Limit
+	MinBurst       int
+	MaxBurst       int
 }
 
 type RetryConfigJson struct {
+	Enabled       bool
+	Limit         rate.Limit
+	Burst         int
+	Wait          string
+	StatusCodes   []int
+	MaxAttempts   int
+	Backoff       string
+	MaxWait       string
+	Budget        float64
+	Errors        []string
+	NonIdempotent bool
+	MaxBodySize   int64
+	GRPCCodes     []int
+	MinLimit      rate.Limit
+	MaxLimit      rate.Limit
+	MinBurst      int
+	MaxBurst      int
+}
+
+type ProxyConfigJson struct {
+	Enabled       bool
+	Pattern       string
+	Headers       []Header
+	Threshold     string
+	Targets       []ProxyTarget
+	StickyHeader  string
+	Failover      bool
+	StatusCodes   []int
+	ProbeInterval string
+}
+
+type CircuitBreakerConfigJson struct {
+	Enabled             bool
+	StatusCode          int
+	FailureRatio        float64
+	MinRequests         int
+	ConsecutiveFailures int
+	Interval            string
+	CoolDown            string
+	HalfOpenRequests    int
+	StatusCodes         []int
+}
+
+type BulkheadConfigJson struct {
+	Enabled       bool
+	StatusCode    int
+	MaxConcurrent int
+	MaxQueue      int
+	QueueTimeout  string
+}
+
+type HedgeConfigJson struct {
+	Enabled   bool
+	Delay     string
+	MaxHedges int
+	Methods   []string
+}
+
+type MirrorConfigJson struct {
 	Enabled     bool
-	Limit       rate.Limit
-	Burst       int
-	Wait        string
-	StatusCodes []int
+	Pattern     string
+	Percentage  float64
+	MaxBodySize int64
+	Timeout     string
+	MaxInFlight int
+}
+
+type PoolConfigJson struct {
+	Enabled           bool
+	Policy            string
+	Endpoints         []string
+	ConsecutiveErrors int
+	BaseEjection      string
+	MaxEjection       string
+}
+
+type HealthCheckConfigJson struct {
+	Enabled            bool
+	Scheme             string
+	Host               string
+	Path               string
+	Interval           string
+	Timeout            string
+	HealthyThreshold   int
+	UnhealthyThreshold int
 }
 
 type RouteConfig struct {
-	Name        string
-	Pattern     string
-	Traffic     string // Egress/Ingress
-	Ping        bool   // Health traffic
-	Protocol    string // gRPC, HTTP10, HTTP11, HTTP2, HTTP3
-	Timeout     *TimeoutConfigJson
-	RateLimiter *RateLimiterConfig
-	Retry       *RetryConfigJson
-	Proxy       *ProxyConfig
+	Name           string
+	Pattern        string
+	Traffic        string // Egress/Ingress
+	Ping           bool   // Health traffic
+	Protocol       string // gRPC, HTTP10, HTTP11, HTTP2, HTTP3
+	Priority       string // critical, high, normal, low
+	Timeout        *TimeoutConfigJson
+	RateLimiter    *RateLimiterConfigJson
+	Retry          *RetryConfigJson
+	Proxy          *ProxyConfigJson
+	CircuitBreaker *CircuitBreakerConfigJson
+	Bulkhead       *BulkheadConfigJson
+	Hedge          *HedgeConfigJson
+	Mirror         *MirrorConfigJson
+	Pool           *PoolConfigJson
+	HealthCheck    *HealthCheckConfigJson
 }
 
 func newRoute(name string, config ...any) Route {
@@ -67,6 +180,18 @@ func NewRoute(name string, traffic, protocol string, ping bool, config ...any) R
 			route.Proxy = c
 		case *RetryConfig:
 			route.Retry = c
+		case *CircuitBreakerConfig:
+			route.CircuitBreaker = c
+		case *BulkheadConfig:
+			route.Bulkhead = c
+		case *HedgeConfig:
+			route.Hedge = c
+		case *MirrorConfig:
+			route.Mirror = c
+		case *PoolConfig:
+			route.Pool = c
+		case *HealthCheckConfig:
+			route.HealthCheck = c
 		}
 	}
 	return route
@@ -80,14 +205,24 @@ func NewRouteFromConfig(config RouteConfig) (Route, error) {
 	route.Traffic = config.Traffic
 	route.Ping = config.Ping
 	route.Protocol = config.Protocol
-	route.Proxy = config.Proxy
-	route.RateLimiter = config.RateLimiter
+	route.Priority = config.Priority
 	if config.Timeout != nil {
 		duration, err := ParseDuration(config.Timeout.Duration)
 		if err != nil {
 			return Route{}, err
 		}
 		route.Timeout = NewTimeoutConfig(config.Timeout.Enabled, config.Timeout.StatusCode, duration)
+		route.Timeout.Propagate = config.Timeout.Propagate
+	}
+	if config.RateLimiter != nil {
+		idle, err := ParseDuration(config.RateLimiter.KeyIdleTimeout)
+		if err != nil {
+			return Route{}, err
+		}
+		rl := config.RateLimiter
+		route.RateLimiter = &RateLimiterConfig{Enabled: rl.Enabled, StatusCode: rl.StatusCode, Limit: rl.Limit, Burst: rl.Burst, Threshold: rl.Threshold, Adaptive: rl.Adaptive,
+			KeyHeader: rl.KeyHeader, TrustedProxies: rl.TrustedProxies, MaxKeys: rl.MaxKeys, KeyIdleTimeout: idle, HashKey: rl.HashKey,
+			MinLimit: rl.MinLimit, MaxLimit: rl.MaxLimit, MinBurst: rl.MinBurst, MaxBurst: rl.MaxBurst}
 	}
 	if config.Retry != nil {
 		duration, err := ParseDuration(config.Retry.Wait)
@@ -95,10 +230,127 @@ func NewRouteFromConfig(config RouteConfig) (Route, error) {
 			return Route{}, err
 		}
 		route.Retry = NewRetryConfig(config.Retry.Enabled, config.Retry.Limit, config.Retry.Burst, duration, config.Retry.StatusCodes)
+		maxWait, err1 := ParseDuration(config.Retry.MaxWait)
+		if err1 != nil {
+			return Route{}, err1
+		}
+		route.Retry.MaxWait = maxWait
+		route.Retry.Budget = config.Retry.Budget
+		route.Retry.Errors = config.Retry.Errors
+		route.Retry.NonIdempotent = config.Retry.NonIdempotent
+		route.Retry.GRPCCodes = config.Retry.GRPCCodes
+		route.Retry.MinLimit = config.Retry.MinLimit
+		route.Retry.MaxLimit = config.Retry.MaxLimit
+		route.Retry.MinBurst = config.Retry.MinBurst
+		route.Retry.MaxBurst = config.Retry.MaxBurst
+		if config.Retry.MaxAttempts > 0 {
+			route.Retry.MaxAttempts = config.Retry.MaxAttempts
+		}
+		if config.Retry.MaxBodySize > 0 {
+			route.Retry.MaxBodySize = config.Retry.MaxBodySize
+		}
+		if config.Retry.Backoff != "" {
+			route.Retry.Backoff = config.Retry.Backoff
+		}
+	}
+	if config.Proxy != nil {
+		probe, err := ParseDuration(config.Proxy.ProbeInterval)
+		if err != nil {
+			return Route{}, err
+		}
+		route.Proxy = NewProxyConfig(config.Proxy.Enabled, config.Proxy.Pattern, config.Proxy.Headers, nil, config.Proxy.Threshold)
+		route.Proxy.Targets = config.Proxy.Targets
+		route.Proxy.StickyHeader = config.Proxy.StickyHeader
+		route.Proxy.Failover = config.Proxy.Failover
+		route.Proxy.StatusCodes = config.Proxy.StatusCodes
+		route.Proxy.ProbeInterval = probe
+	}
+	if config.CircuitBreaker != nil {
+		interval, err := ParseDuration(config.CircuitBreaker.Interval)
+		if err != nil {
+			return Route{}, err
+		}
+		coolDown, err1 := ParseDuration(config.CircuitBreaker.CoolDown)
+		if err1 != nil {
+			return Route{}, err1
+		}
+		route.CircuitBreaker = NewCircuitBreakerConfig(config.CircuitBreaker.Enabled, config.CircuitBreaker.StatusCode, config.CircuitBreaker.FailureRatio, config.CircuitBreaker.ConsecutiveFailures, interval, coolDown, config.CircuitBreaker.StatusCodes)
+		route.CircuitBreaker.MinRequests = config.CircuitBreaker.MinRequests
+		if config.CircuitBreaker.HalfOpenRequests > 0 {
+			route.CircuitBreaker.HalfOpenRequests = config.CircuitBreaker.HalfOpenRequests
+		}
+	}
+	if config.Bulkhead != nil {
+		duration, err := ParseDuration(config.Bulkhead.QueueTimeout)
+		if err != nil {
+			return Route{}, err
+		}
+		route.Bulkhead = NewBulkheadConfig(config.Bulkhead.Enabled, config.Bulkhead.StatusCode, config.Bulkhead.MaxConcurrent, config.Bulkhead.MaxQueue, duration)
+	}
+	if config.Hedge != nil {
+		duration, err := ParseDuration(config.Hedge.Delay)
+		if err != nil {
+			return Route{}, err
+		}
+		route.Hedge = NewHedgeConfig(config.Hedge.Enabled, duration, config.Hedge.MaxHedges, config.Hedge.Methods)
+	}
+	if config.Mirror != nil {
+		timeout, err := ParseDuration(config.Mirror.Timeout)
+		if err != nil {
+			return Route{}, err
+		}
+		route.Mirror = NewMirrorConfig(config.Mirror.Enabled, config.Mirror.Pattern, config.Mirror.Percentage)
+		if config.Mirror.MaxBodySize > 0 {
+			route.Mirror.MaxBodySize = config.Mirror.MaxBodySize
+		}
+		if timeout > 0 {
+			route.Mirror.Timeout = timeout
+		}
+		if config.Mirror.MaxInFlight > 0 {
+			route.Mirror.MaxInFlight = config.Mirror.MaxInFlight
+		}
+	}
+	if config.Pool != nil {
+		base, err := ParseDuration(config.Pool.BaseEjection)
+		if err != nil {
+			return Route{}, err
+		}
+		maxEjection, err1 := ParseDuration(config.Pool.MaxEjection)
+		if err1 != nil {
+			return Route{}, err1
+		}
+		route.Pool = NewPoolConfig(config.Pool.Enabled, config.Pool.Policy, config.Pool.Endpoints)
+		if config.Pool.ConsecutiveErrors != 0 {
+			route.Pool.ConsecutiveErrors = config.Pool.ConsecutiveErrors
+		}
+		if base > 0 {
+			route.Pool.BaseEjection = base
+		}
+		if maxEjection > 0 {
+			route.Pool.MaxEjection = maxEjection
+		}
+	}
+	if config.HealthCheck != nil {
+		interval, err := ParseDuration(config.HealthCheck.Interval)
+		if err != nil {
+			return Route{}, err
+		}
+		timeout, err1 := ParseDuration(config.HealthCheck.Timeout)
+		if err1 != nil {
+			return Route{}, err1
+		}
+		route.HealthCheck = NewHealthCheckConfig(config.HealthCheck.Enabled, config.HealthCheck.Host, config.HealthCheck.Path, interval, timeout)
+		route.HealthCheck.Scheme = config.HealthCheck.Scheme
+		if config.HealthCheck.HealthyThreshold > 0 {
+			route.HealthCheck.HealthyThreshold = config.HealthCheck.HealthyThreshold
+		}
+		if config.HealthCheck.UnhealthyThreshold > 0 {
+			route.HealthCheck.UnhealthyThreshold = config.HealthCheck.UnhealthyThreshold
+		}
 	}
 	return route, nil
 }
 
 func (r Route) IsConfigured() bool {
-	return r.Retry != nil || r.Timeout != nil || r.RateLimiter != nil || r.Proxy != nil
+	return r.Retry != nil || r.Timeout != nil || r.RateLimiter != nil || r.Proxy != nil || r.CircuitBreaker != nil || r.Bulkhead != nil || r.Hedge != nil || r.Mirror != nil || r.Pool != nil || r.HealthCheck != nil
 }
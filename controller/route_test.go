@@ -67,7 +67,7 @@ func ExampleConfig_Marshal() {
 	fmt.Printf("test: Config{} -> [error:%v] %v\n", err, string(buf))
 
 	//Output:
-	//test: Config{} -> [error:<nil>] {"Name":"test-route","Pattern":"google.com","Traffic":"ingress","Ping":true,"Protocol":"HTTP11","Timeout":{"Enabled":false,"StatusCode":504,"Duration":20000},"RateLimiter":{"Enabled":false,"StatusCode":503,"Limit":100,"Burst":25,"Threshold":""},"Retry":{"Enabled":false,"Limit":100,"Burst":33,"Wait":500,"StatusCodes":[503,504]},"Proxy":{"Enabled":false,"Pattern":"http:","Headers":null,"Action":null,"Threshold":""}}
+	//test: Config{} -> [error:<nil>] {"Name":"test-route","Pattern":"google.com","Traffic":"ingress","Ping":true,"Protocol":"HTTP11","Priority":"","Timeout":{"Enabled":false,"StatusCode":504,"Duration":20000,"Propagate":false},"RateLimiter":{"Enabled":false,"StatusCode":503,"Limit":100,"Burst":25,"Threshold":"","Adaptive":null,"KeyHeader":"","TrustedProxies":null,"MaxKeys":0,"KeyIdleTimeout":0,"HashKey":false,"MinLimit":0,"MaxLimit":0,"MinBurst":0,"MaxBurst":0},"Retry":{"Enabled":false,"Limit":100,"Burst":33,"Wait":500,"StatusCodes":[503,504],"MaxAttempts":0,"Backoff":"","MaxWait":0,"Budget":0,"Errors":null,"NonIdempotent":false,"MaxBodySize":0,"GRPCCodes":null,"MinLimit":0,"MaxLimit":0,"MinBurst":0,"MaxBurst":0},"Proxy":{"Enabled":false,"Pattern":"http:","Headers":null,"Action":null,"Threshold":"","Targets":null,"StickyHeader":"","Failover":false,"StatusCodes":null,"ProbeInterval":0},"CircuitBreaker":null,"Bulkhead":null,"Hedge":null,"Mirror":null,"Pool":null,"HealthCheck":null}
 
 }
 
@@ -100,9 +100,9 @@ func ExampleNewRouteFromConfig() {
 	fmt.Printf("test: NewRouteFromConfig() [err:%v] [route:%v]\n", err, route)
 
 	//Output:
-	//test: NewRouteFromConfig() [err:strconv.Atoi: parsing "5x": invalid syntax] [route:{   false  <nil> <nil> <nil> <nil>}]
-	//test: NewRouteFromConfig() [err:<nil>] [timeout:&{true 5040 500ms}] [retry:&{false 100 25 4m5s []}]
-	//test: NewRouteFromConfig() [err:strconv.Atoi: parsing "x34": invalid syntax] [route:{   false  <nil> <nil> <nil> <nil>}]
+	//test: NewRouteFromConfig() [err:strconv.Atoi: parsing "5x": invalid syntax] [route:{   false   <nil> <nil> <nil> <nil> <nil> <nil> <nil> <nil> <nil> <nil>}]
+	//test: NewRouteFromConfig() [err:<nil>] [timeout:&{true 5040 500ms false}] [retry:&{false 100 25 4m5s [] 2 constant 0s 0 [] false 1048576 [] 0 0 0 0}]
+	//test: NewRouteFromConfig() [err:strconv.Atoi: parsing "x34": invalid syntax] [route:{   false   <nil> <nil> <nil> <nil> <nil> <nil> <nil> <nil> <nil> <nil>}]
 
 }
 
@@ -118,3 +118,68 @@ func _ExampleConfig_Unmarshal() {
 	//Output:
 	//test: Config{} -> [error:<nil>] [{test-route {504 20µs} {100 25 503} {100 33 500ns [503 504]} {false <nil>}}]
 }
+
+func ExampleNewRouteFromConfig_Propagate() {
+	var config RouteConfig
+	err := json.Unmarshal([]byte(`{"Name":"propagate-route","Traffic":"egress","Timeout":{"Enabled":true,"StatusCode":504,"Duration":"500ms","Propagate":true}}`), &config)
+	route, err1 := NewRouteFromConfig(config)
+	t := newTable(true, false)
+	errs := t.AddController(route)
+	fmt.Printf("test: NewRouteFromConfig() -> [err:%v] [err1:%v] [errs:%v] [propagate:%v]\n", err, err1, errs, t.LookupByName("propagate-route").Timeout().Propagate())
+
+	//Output:
+	//test: NewRouteFromConfig() -> [err:<nil>] [err1:<nil>] [errs:[]] [propagate:true]
+
+}
+
+func ExampleNewRouteFromConfig_Mirror() {
+	var config RouteConfig
+	err := json.Unmarshal([]byte(`{"Name":"mirror-route","Traffic":"egress","Mirror":{"Enabled":true,"Pattern":"http://localhost:8081","Percentage":10,"Timeout":"2s","MaxInFlight":10}}`), &config)
+	route, err1 := NewRouteFromConfig(config)
+	t := newTable(true, false)
+	errs := t.AddController(route)
+	mc := t.LookupByName("mirror-route").Mirror()
+	fmt.Printf("test: NewRouteFromConfig() -> [err:%v] [err1:%v] [errs:%v] [timeout:%v] [max-in-flight:%v] [max-body:%v]\n", err, err1, errs, mc.Timeout(), mc.MaxInFlight(), mc.MaxBodySize())
+
+	buf, err2 := json.Marshal(t.LookupByName("mirror-route").Snapshot().Mirror)
+	fmt.Printf("test: Snapshot() -> [err:%v] [%v]\n", err2, string(buf))
+
+	config.Mirror.Timeout = "2x"
+	_, err = NewRouteFromConfig(config)
+	fmt.Printf("test: NewRouteFromConfig(invalid) -> [err:%v]\n", err)
+
+	//Output:
+	//test: NewRouteFromConfig() -> [err:<nil>] [err1:<nil>] [errs:[]] [timeout:2s] [max-in-flight:10] [max-body:65536]
+	//test: Snapshot() -> [err:<nil>] [{"Enabled":true,"Pattern":"http://localhost:8081","Percentage":10,"MaxBodySize":65536,"Timeout":"2s","MaxInFlight":10}]
+	//test: NewRouteFromConfig(invalid) -> [err:strconv.Atoi: parsing "2x": invalid syntax]
+
+}
+
+func ExampleNewRouteFromConfig_Durations() {
+	var config RouteConfig
+	err := json.Unmarshal([]byte(`{"Name":"duration-route","Traffic":"egress","RateLimiter":{"Enabled":true,"StatusCode":429,"Limit":10,"Burst":5,"KeyHeader":"X-Tenant","KeyIdleTimeout":"2m"},
+		"Proxy":{"Enabled":false,"Pattern":"http://localhost:8081","Threshold":"5","Failover":true,"ProbeInterval":"10s"}}`), &config)
+	route, err1 := NewRouteFromConfig(config)
+	t := newTable(true, false)
+	errs := t.AddController(route)
+	ctrl := t.LookupByName("duration-route").t()
+	fmt.Printf("test: NewRouteFromConfig() -> [err:%v] [err1:%v] [errs:%v] [key-idle:%v] [probe:%v]\n", err, err1, errs, ctrl.rateLimiter.config.KeyIdleTimeout, ctrl.proxy.config.ProbeInterval)
+
+	snapshot := ctrl.Snapshot()
+	fmt.Printf("test: Snapshot() -> [key-idle:%v] [probe:%v]\n", snapshot.RateLimiter.KeyIdleTimeout, snapshot.Proxy.ProbeInterval)
+
+	config.Proxy.ProbeInterval = "10x"
+	_, err = NewRouteFromConfig(config)
+	fmt.Printf("test: NewRouteFromConfig(probe) -> [err:%v]\n", err)
+
+	config.RateLimiter.KeyIdleTimeout = "2x"
+	_, err = NewRouteFromConfig(config)
+	fmt.Printf("test: NewRouteFromConfig(key-idle) -> [err:%v]\n", err)
+
+	//Output:
+	//test: NewRouteFromConfig() -> [err:<nil>] [err1:<nil>] [errs:[]] [key-idle:2m0s] [probe:10s]
+	//test: Snapshot() -> [key-idle:2m] [probe:10s]
+	//test: NewRouteFromConfig(probe) -> [err:strconv.Atoi: parsing "10x": invalid syntax]
+	//test: NewRouteFromConfig(key-idle) -> [err:strconv.Atoi: parsing "2x": invalid syntax]
+
+}
@@ -1,8 +1,9 @@
 package controller
 
 const (
-	EgressTraffic       = "egress"
-	IngressTraffic      = "ingress"
-	PingTraffic         = "ping"
-	RequestIdHeaderName = "X-REQUEST-ID"
+	EgressTraffic        = "egress"
+	IngressTraffic       = "ingress"
+	PingTraffic          = "ping"
+	RequestIdHeaderName  = "X-REQUEST-ID"
+	RetryAfterHeaderName = "Retry-After"
 )
@@ -0,0 +1,131 @@
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// redactedValue - the value of a proxy header in a snapshot, as header values may contain credentials
+const redactedValue = "[redacted]"
+
+// Snapshot - the current configuration of a controller, including any changes made by actuator signals. Only
+// configured behaviors are included, and the result can be marshalled to the JSON route configuration format.
+// Proxy header values are redacted, so a snapshot can not be used to restore them
+func (c *controller) Snapshot() RouteConfig {
+	config := RouteConfig{Name: c.name, Pattern: c.route.Pattern, Traffic: c.route.Traffic, Ping: c.ping, Protocol: c.route.Protocol, Priority: c.priority}
+	if !c.timeout.IsNil() {
+		config.Timeout = &TimeoutConfigJson{Enabled: c.timeout.IsEnabled(), StatusCode: c.timeout.config.StatusCode, Duration: FormatDuration(c.timeout.config.Duration), Propagate: c.timeout.config.Propagate}
+	}
+	if !c.rateLimiter.IsNil() {
+		rl := c.rateLimiter.config
+		config.RateLimiter = &RateLimiterConfigJson{Enabled: rl.Enabled, StatusCode: rl.StatusCode, Limit: rl.Limit, Burst: rl.Burst, Threshold: rl.Threshold, Adaptive: rl.Adaptive,
+			KeyHeader: rl.KeyHeader, TrustedProxies: rl.TrustedProxies, MaxKeys: rl.MaxKeys, KeyIdleTimeout: FormatDuration(rl.KeyIdleTimeout), HashKey: rl.HashKey,
+			MinLimit: rl.MinLimit, MaxLimit: rl.MaxLimit, MinBurst: rl.MinBurst, MaxBurst: rl.MaxBurst}
+	}
+	if !c.retry.IsNil() {
+		rc := c.retry.config
+		config.Retry = &RetryConfigJson{Enabled: rc.Enabled, Limit: rc.Limit, Burst: rc.Burst, Wait: FormatDuration(rc.Wait), StatusCodes: rc.StatusCodes,
+			MaxAttempts: rc.MaxAttempts, Backoff: rc.Backoff, MaxWait: FormatDuration(rc.MaxWait), Budget: rc.Budget, Errors: rc.Errors, NonIdempotent: rc.NonIdempotent, MaxBodySize: rc.MaxBodySize, GRPCCodes: rc.GRPCCodes,
+			MinLimit: rc.MinLimit, MaxLimit: rc.MaxLimit, MinBurst: rc.MinBurst, MaxBurst: rc.MaxBurst}
+	}
+	if !c.proxy.IsNil() {
+		pc := c.proxy.config
+		config.Proxy = &ProxyConfigJson{Enabled: pc.Enabled, Pattern: pc.Pattern, Headers: redactHeaders(pc.Headers), Threshold: pc.Threshold, Targets: pc.Targets,
+			StickyHeader: pc.StickyHeader, Failover: pc.Failover, StatusCodes: pc.StatusCodes, ProbeInterval: FormatDuration(pc.ProbeInterval)}
+	}
+	if !c.circuitBreaker.IsNil() {
+		cb := c.circuitBreaker.config
+		config.CircuitBreaker = &CircuitBreakerConfigJson{Enabled: cb.Enabled, StatusCode: cb.StatusCode, FailureRatio: cb.FailureRatio, MinRequests: cb.MinRequests,
+			ConsecutiveFailures: cb.ConsecutiveFailures, Interval: FormatDuration(cb.Interval), CoolDown: FormatDuration(cb.CoolDown), HalfOpenRequests: cb.HalfOpenRequests, StatusCodes: cb.StatusCodes}
+	}
+	if !c.bulkhead.IsNil() {
+		bh := c.bulkhead.config
+		config.Bulkhead = &BulkheadConfigJson{Enabled: bh.Enabled, StatusCode: bh.StatusCode, MaxConcurrent: bh.MaxConcurrent, MaxQueue: bh.MaxQueue, QueueTimeout: FormatDuration(bh.QueueTimeout)}
+	}
+	if !c.hedge.IsNil() {
+		hc := c.hedge.config
+		config.Hedge = &HedgeConfigJson{Enabled: hc.Enabled, Delay: FormatDuration(hc.Delay), MaxHedges: hc.MaxHedges, Methods: hc.Methods}
+	}
+	if !c.mirror.IsNil() {
+		mc := c.mirror.config
+		config.Mirror = &MirrorConfigJson{Enabled: mc.Enabled, Pattern: mc.Pattern, Percentage: mc.Percentage, MaxBodySize: mc.MaxBodySize,
+			Timeout: FormatDuration(mc.Timeout), MaxInFlight: mc.MaxInFlight}
+	}
+	if !c.pool.IsNil() {
+		pc := c.pool.config
+		config.Pool = &PoolConfigJson{Enabled: pc.Enabled, Policy: pc.Policy, Endpoints: pc.Endpoints, ConsecutiveErrors: pc.ConsecutiveErrors,
+			BaseEjection: FormatDuration(pc.BaseEjection), MaxEjection: FormatDuration(pc.MaxEjection)}
+	}
+	if !c.healthCheck.IsNil() {
+		hc := c.healthCheck.config
+		config.HealthCheck = &HealthCheckConfigJson{Enabled: hc.Enabled, Scheme: hc.Scheme, Host: hc.Host, Path: hc.Path, Interval: FormatDuration(hc.Interval),
+			Timeout: FormatDuration(hc.Timeout), HealthyThreshold: hc.HealthyThreshold, UnhealthyThreshold: hc.UnhealthyThreshold}
+	}
+	return config
+}
+
+func redactHeaders(headers []Header) []Header {
+	if headers == nil {
+		return nil
+	}
+	redacted := make([]Header, len(headers))
+	for i, h := range headers {
+		redacted[i] = Header{Name: h.Name, Value: redactedValue}
+	}
+	return redacted
+}
+
+// Snapshot - the current configuration of the host, default and route controllers, routes are sorted by name
+func (t *table) Snapshot() []RouteConfig {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	var configs []RouteConfig
+	if !t.egress {
+		configs = append(configs, t.hostCtrl.Snapshot())
+	}
+	configs = append(configs, t.defaultCtrl.Snapshot())
+	names := make([]string, 0, len(t.controllers))
+	for name := range t.controllers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		configs = append(configs, t.controllers[name].Snapshot())
+	}
+	return configs
+}
+
+// Behavior - the configuration of a behavior, returns an error if the behavior name is invalid or not configured
+func (r RouteConfig) Behavior(name string) (any, error) {
+	var config any
+	var ok bool
+	switch name {
+	case TimeoutBehavior:
+		config, ok = r.Timeout, r.Timeout != nil
+	case RateLimitBehavior:
+		config, ok = r.RateLimiter, r.RateLimiter != nil
+	case RetryBehavior:
+		config, ok = r.Retry, r.Retry != nil
+	case ProxyBehavior:
+		config, ok = r.Proxy, r.Proxy != nil
+	case CircuitBreakerBehavior:
+		config, ok = r.CircuitBreaker, r.CircuitBreaker != nil
+	case BulkheadBehavior:
+		config, ok = r.Bulkhead, r.Bulkhead != nil
+	case HedgeBehavior:
+		config, ok = r.Hedge, r.Hedge != nil
+	case MirrorBehavior:
+		config, ok = r.Mirror, r.Mirror != nil
+	case PoolBehavior:
+		config, ok = r.Pool, r.Pool != nil
+	case HealthCheckBehavior:
+		config, ok = r.HealthCheck, r.HealthCheck != nil
+	default:
+		return nil, errors.New(fmt.Sprintf("invalid argument: behavior [%s] is not supported", name))
+	}
+	if !ok {
+		return nil, errors.New(fmt.Sprintf("invalid argument: behavior [%s] is not configured [%v]", name, r.Name))
+	}
+	return config, nil
+}
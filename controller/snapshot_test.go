@@ -0,0 +1,44 @@
+package controller
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"time"
+)
+
+func ExampleTable_Snapshot() {
+	t := newTable(true, false)
+	t.SetDefaultController(NewRoute(DefaultEgressRouteName, EgressTraffic, "", false, NewTimeoutConfig(true, 504, time.Second*2)))
+	t.AddController(NewRoute("proxy-route", EgressTraffic, "", false, NewProxyConfig(true, "http://localhost:8080", []Header{{Name: "name", Value: "value"}}, nil, "")))
+	t.AddController(NewRoute("limit-route", EgressTraffic, "", false, NewRateLimiterConfig(true, 503, 100, 10, "")))
+
+	t.LookupByName("limit-route").RateLimiter().Signal(NewValues(RateLimitKey, "50"))
+	buf, err := json.Marshal(t.Snapshot())
+	fmt.Printf("test: Snapshot() -> [err:%v] %v\n", err, string(buf))
+
+	t.LookupByName("proxy-route").Signal(url.Values{BehaviorKey: {ProxyBehavior}, EnabledKey: {FalseValue}})
+	buf, err = json.Marshal(t.History("proxy-route")[0].Old)
+	fmt.Printf("test: History() -> [err:%v] [headers:%v] %v\n", err, t.LookupByName("proxy-route").Proxy().Headers(), string(buf))
+
+	//Output:
+	//test: Snapshot() -> [err:<nil>] [{"Name":"default-egress","Pattern":"","Traffic":"egress","Ping":false,"Protocol":"","Priority":"","Timeout":{"Enabled":true,"StatusCode":504,"Duration":"2s","Propagate":false},"RateLimiter":null,"Retry":null,"Proxy":null,"CircuitBreaker":null,"Bulkhead":null,"Hedge":null,"Mirror":null,"Pool":null,"HealthCheck":null},{"Name":"limit-route","Pattern":"","Traffic":"egress","Ping":false,"Protocol":"","Priority":"","Timeout":null,"RateLimiter":{"Enabled":true,"StatusCode":503,"Limit":50,"Burst":10,"Threshold":"","Adaptive":null,"KeyHeader":"","TrustedProxies":null,"MaxKeys":0,"KeyIdleTimeout":"","HashKey":false,"MinLimit":0,"MaxLimit":0,"MinBurst":0,"MaxBurst":0},"Retry":null,"Proxy":null,"CircuitBreaker":null,"Bulkhead":null,"Hedge":null,"Mirror":null,"Pool":null,"HealthCheck":null},{"Name":"proxy-route","Pattern":"","Traffic":"egress","Ping":false,"Protocol":"","Priority":"","Timeout":null,"RateLimiter":null,"Retry":null,"Proxy":{"Enabled":true,"Pattern":"http://localhost:8080","Headers":[{"Name":"name","Value":"[redacted]"}],"Threshold":"","Targets":null,"StickyHeader":"","Failover":false,"StatusCodes":null,"ProbeInterval":""},"CircuitBreaker":null,"Bulkhead":null,"Hedge":null,"Mirror":null,"Pool":null,"HealthCheck":null}]
+	//test: History() -> [err:<nil>] [headers:[{name value}]] {"Enabled":true,"Pattern":"http://localhost:8080","Headers":[{"Name":"name","Value":"[redacted]"}],"Threshold":"","Targets":null,"StickyHeader":"","Failover":false,"StatusCodes":null,"ProbeInterval":""}
+
+}
+
+func ExampleFormatDuration() {
+	for _, d := range []time.Duration{0, time.Minute * 5, time.Second * 90, time.Millisecond * 1500, time.Microsecond * 250} {
+		s := FormatDuration(d)
+		d1, err := ParseDuration(s)
+		fmt.Printf("test: FormatDuration(%v) -> [%v] [parsed:%v] [err:%v]\n", d, s, d1 == d, err)
+	}
+
+	//Output:
+	//test: FormatDuration(0s) -> [] [parsed:true] [err:<nil>]
+	//test: FormatDuration(5m0s) -> [5m] [parsed:true] [err:<nil>]
+	//test: FormatDuration(1m30s) -> [90s] [parsed:true] [err:<nil>]
+	//test: FormatDuration(1.5s) -> [1500ms] [parsed:true] [err:<nil>]
+	//test: FormatDuration(250µs) -> [250µs] [parsed:true] [err:<nil>]
+
+}
@@ -93,3 +93,18 @@ func ParseDuration(s string) (time.Duration, error) {
 	}
 	return time.Duration(val) * time.Second, nil
 }
+
+// FormatDuration - format a duration in the units understood by ParseDuration
+func FormatDuration(d time.Duration) string {
+	switch {
+	case d == 0:
+		return ""
+	case d%time.Minute == 0:
+		return strconv.FormatInt(int64(d/time.Minute), 10) + "m"
+	case d%time.Second == 0:
+		return strconv.FormatInt(int64(d/time.Second), 10) + "s"
+	case d%time.Millisecond == 0:
+		return strconv.FormatInt(int64(d/time.Millisecond), 10) + "ms"
+	}
+	return strconv.FormatInt(int64(d/time.Microsecond), 10) + "µs"
+}
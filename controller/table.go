@@ -4,6 +4,8 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"net/url"
+	"sort"
 	"sync"
 )
 
@@ -12,9 +14,14 @@ type Configuration interface {
 	SetAction(name string, action Actuator) error
 	SetHttpMatcher(fn HttpMatcher)
 	SetUriMatcher(fn UriMatcher)
+	SetPriorityMatcher(fn PriorityMatcher)
 	SetDefaultController(route Route) []error
 	SetHostController(route Route) []error
 	AddController(route Route) []error
+	Reload(config []RouteConfig) []error
+	Rollback(name, caller string) error
+	SignalAll(values url.Values) []error
+	StartHealthChecks(client *http.Client) (stop func())
 }
 
 // Controllers - public interface
@@ -24,6 +31,12 @@ type Controllers interface {
 	LookupHttp(req *http.Request) Controller
 	LookupUri(urn string, method string) Controller
 	LookupByName(name string) Controller
+	Priority(req *http.Request) string
+	Snapshot() []RouteConfig
+	History(name string) []Change
+	LastChange(name string) (Change, bool)
+	Overrides(name string) []Override
+	Health(name string) []Health
 }
 
 // Table - controller table
@@ -47,15 +60,20 @@ func EgressTable() Table {
 }
 
 type table struct {
-	egress       bool
-	allowDefault bool
-	mu           sync.RWMutex
-	httpMatch    HttpMatcher
-	uriMatch     UriMatcher
-	hostCtrl     *controller
-	defaultCtrl  *controller
-	nilCtrl      *controller
-	controllers  map[string]*controller
+	egress        bool
+	allowDefault  bool
+	mu            sync.RWMutex
+	signalMu      sync.Mutex // serializes controller changes, so a signal records its own change
+	httpMatch     HttpMatcher
+	uriMatch      UriMatcher
+	priorityMatch PriorityMatcher
+	patterns      *patternTrie
+	history       *history
+	overrides     map[string]*Override
+	hostCtrl      *controller
+	defaultCtrl   *controller
+	nilCtrl       *controller
+	controllers   map[string]*controller
 }
 
 // NewEgressTable - create a new Egress table
@@ -72,13 +90,13 @@ func newTable(egress, allowDefault bool) *table {
 	t := new(table)
 	t.egress = egress
 	t.allowDefault = allowDefault
-	t.httpMatch = func(req *http.Request) (name string, ok bool) {
-		return "", true
-	}
-	t.uriMatch = func(urn string, method string) (name string, ok bool) {
-		return "", true
-	}
+	// Route patterns are matched by default, a custom matcher overrides pattern matching
+	t.patterns = newPatternTrie()
+	t.httpMatch = t.matchHttp
+	t.uriMatch = t.matchUri
 	t.controllers = make(map[string]*controller, 100)
+	t.history = newHistory(DefaultHistorySize)
+	t.overrides = make(map[string]*Override)
 	t.hostCtrl = newDefaultController(HostControllerName)
 	t.defaultCtrl = newDefaultController(DefaultControllerName)
 	t.nilCtrl = newDefaultController(NilControllerName)
@@ -117,44 +135,40 @@ func (t *table) SetUriMatcher(fn UriMatcher) {
 }
 
 func (t *table) SetHostController(route Route) []error {
-	if t.isEgress() {
-		return []error{errors.New("host controller configuration is not valid for egress traffic")}
-	}
-	if !t.isEgress() && (route.Retry != nil || route.Timeout != nil || route.Proxy != nil) {
-		return []error{errors.New("host controller configuration does not allow retry, rate limiter, or proxy controllers")}
-	}
-	t.mu.Lock()
-	defer t.mu.Unlock()
-	route.Name = HostControllerName
-	ctrl, errs := newController(route, t)
+	ctrl, errs := t.newHostController(route)
 	if len(errs) > 0 {
 		return errs
 	}
-	err := ctrl.validate(t.egress)
-	if err != nil {
-		return []error{err}
-	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
 	t.hostCtrl = ctrl
 	return nil
 }
 
+func (t *table) newHostController(route Route) (*controller, []error) {
+	if t.isEgress() {
+		return nil, []error{errors.New("host controller configuration is not valid for egress traffic")}
+	}
+	if !t.isEgress() && (route.Retry != nil || route.Timeout != nil || route.Proxy != nil) {
+		return nil, []error{errors.New("host controller configuration does not allow retry, rate limiter, or proxy controllers")}
+	}
+	route.Name = HostControllerName
+	return t.newController(route)
+}
+
 func (t *table) SetDefaultController(route Route) []error {
 	//if !t.isEgress() {
 	//	return []error{errors.New("default controller configuration is not valid for ingress traffic")}
 	//}
-	t.mu.Lock()
-	defer t.mu.Unlock()
 	if route.Name == "" {
 		route.Name = DefaultControllerName
 	}
-	ctrl, errs := newController(route, t)
+	ctrl, errs := t.newController(route)
 	if len(errs) > 0 {
 		return errs
 	}
-	err := ctrl.validate(t.egress)
-	if err != nil {
-		return []error{err}
-	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
 	t.defaultCtrl = ctrl
 	return nil
 }
@@ -226,21 +240,61 @@ func (t *table) AddController(route Route) []error {
 	if IsEmpty(route.Name) {
 		return []error{errors.New("invalid argument: route name is empty")}
 	}
+	ctrl, errs := t.newController(route)
+	if len(errs) > 0 {
+		return errs
+	}
 	t.mu.Lock()
 	defer t.mu.Unlock()
+	if _, ok := t.controllers[route.Name]; ok {
+		return []error{errors.New(fmt.Sprintf("invalid argument: route name is a duplicate [%v]", route.Name))}
+	}
+	if route.Pattern != "" {
+		if err := t.patterns.add(route.Name, route.Pattern); err != nil {
+			return []error{err}
+		}
+	}
+	t.controllers[route.Name] = ctrl
+	return nil
+}
+
+func (t *table) newController(route Route) (*controller, []error) {
 	ctrl, errs := newController(route, t)
 	if len(errs) > 0 {
-		return errs
+		return nil, errs
 	}
 	err := ctrl.validate(t.egress)
 	if err != nil {
-		return []error{err}
+		return nil, []error{err}
 	}
-	if _, ok := t.controllers[route.Name]; ok {
-		return []error{errors.New(fmt.Sprintf("invalid argument: route name is a duplicate [%v]", route.Name))}
+	return ctrl, nil
+}
+
+// SignalAll - signal all route controllers that have the behavior configured, for example to scale the rate
+// limits of every route by a percentage
+func (t *table) SignalAll(values url.Values) []error {
+	behavior := values.Get(BehaviorKey)
+	if !IsBehavior(behavior) {
+		return []error{errors.New(fmt.Sprintf("invalid argument: behavior [%s] is not supported", behavior))}
 	}
-	t.controllers[route.Name] = ctrl
-	return nil
+	t.mu.RLock()
+	var controllers []*controller
+	for _, ctrl := range t.controllers {
+		controllers = append(controllers, ctrl)
+	}
+	t.mu.RUnlock()
+	sort.Slice(controllers, func(i, j int) bool { return controllers[i].name < controllers[j].name })
+
+	var errs []error
+	for _, ctrl := range controllers {
+		if config, err := ctrl.Snapshot().Behavior(behavior); err != nil || config == nil {
+			continue
+		}
+		if err := ctrl.Signal(values); err != nil {
+			errs = append(errs, errors.New(fmt.Sprintf("%v [%v]", err, ctrl.name)))
+		}
+	}
+	return errs
 }
 
 func (t *table) exists(name string) bool {
@@ -282,5 +336,8 @@ func (t *table) remove(name string) {
 	}
 	t.mu.Lock()
 	delete(t.controllers, name)
+	t.patterns.remove(name)
+	t.history.remove(name)
+	t.removeOverrides(name)
 	t.mu.Unlock()
 }
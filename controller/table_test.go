@@ -3,6 +3,7 @@ package controller
 import (
 	"fmt"
 	"net/http"
+	"net/url"
 	"time"
 )
 
@@ -200,3 +201,22 @@ func ExampleTable_Add_Ingress() {
 	//test: AddRoute(invalid) -> []
 
 }
+
+func ExampleTable_SignalAll() {
+	t := newTable(true, false)
+	t.AddController(newRoute("route-1", NewRateLimiterConfig(true, 503, 100, 10, "")))
+	t.AddController(newRoute("route-2", NewRateLimiterConfig(true, 503, 50, 5, "")))
+	t.AddController(newRoute("route-3", NewTimeoutConfig(true, 504, time.Second)))
+
+	errs := t.SignalAll(url.Values{BehaviorKey: {RateLimitBehavior}, PercentKey: {"-20"}})
+	fmt.Printf("test: SignalAll(pct=-20) -> [errs:%v] [route-1:%v] [route-2:%v] [route-3:%v]\n", errs, t.LookupByName("route-1").RateLimiter().Limit(),
+		t.LookupByName("route-2").RateLimiter().Limit(), t.LookupByName("route-3").RateLimiter().IsNil())
+
+	errs = t.SignalAll(url.Values{BehaviorKey: {"invalid"}})
+	fmt.Printf("test: SignalAll(invalid) -> [errs:%v]\n", errs)
+
+	//Output:
+	//test: SignalAll(pct=-20) -> [errs:[]] [route-1:80] [route-2:40] [route-3:true]
+	//test: SignalAll(invalid) -> [errs:[invalid argument: behavior [invalid] is not supported]]
+
+}
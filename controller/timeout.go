@@ -14,12 +14,14 @@ type Timeout interface {
 	Actuator
 	StatusCode() int
 	Duration() time.Duration
+	Propagate() bool
 }
 
 type TimeoutConfig struct {
 	Enabled    bool
 	StatusCode int
 	Duration   time.Duration
+	Propagate  bool // propagate the remaining budget to the upstream in the request timeout headers
 }
 
 var nilTimeout = newTimeout(NilBehaviorName, nil, NewTimeoutConfig(false, 0, 1))
@@ -76,6 +78,10 @@ func (t *timeout) Signal(values url.Values) error {
 	if values == nil {
 		return errors.New("invalid argument: values are nil for timeout signal")
 	}
+	pct, err := ParsePercentage(values)
+	if err != nil {
+		return err
+	}
 	UpdateEnable(t, values)
 	if values.Has(DurationKey) {
 		duration, err := ParseDuration(values.Get(DurationKey))
@@ -89,7 +95,6 @@ func (t *timeout) Signal(values url.Values) error {
 			t.setTimeout(duration)
 		}
 	}
-	pct := ParsePercentage(values)
 	if pct != NilPercentageValue {
 		val := t.Duration() + time.Duration(pct*float64(t.Duration()))
 		t.setTimeout(val)
@@ -126,6 +131,12 @@ func (t *timeout) Duration() time.Duration {
 	return t.config.Duration
 }
 
+// Propagate - determine if the remaining budget of a request is written to the upstream request timeout headers,
+// upstreams that are not part of the deadline budget should not receive them
+func (t *timeout) Propagate() bool {
+	return t.config.Propagate
+}
+
 func (t *timeout) enableTimeout(enable bool) {
 	if t.table == nil || t.IsNil() {
 		return
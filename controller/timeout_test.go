@@ -40,7 +40,7 @@ func Example_newTimeout() {
 	//test: validate() -> [name:!] [error:<nil>]
 	//test: newTimeout() -> [name:test-route] [current:100ns]
 	//test: newTimeout() -> [name:test-route2] [current:2s]
-	//test: cloneTimeout() -> [prev-config:{true 503 2s}] [prev-name:test-route2] [curr-config:{true 503 1s}] [curr-name:test-route2]
+	//test: cloneTimeout() -> [prev-config:{true 503 2s false}] [prev-name:test-route2] [curr-config:{true 503 1s false}] [curr-name:test-route2]
 
 }
 
@@ -58,7 +58,7 @@ func ExampleTimeout_State() {
 	fmt.Printf("test: timeoutState(map,t) -> [enabled:%v] [timeout:%v]\n", t.IsEnabled(), timeoutState(t))
 
 	//Output:
-	//test: newTimeout() -> [name:test-route] [state:{true 504 2s}]
+	//test: newTimeout() -> [name:test-route] [state:{true 504 2s false}]
 	//test: timeoutState(map,t) -> [enabled:true] [timeout:2000]
 	//test: timeoutState(map,t) -> [enabled:false] [timeout:-1]
 
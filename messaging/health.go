@@ -0,0 +1,47 @@
+package messaging
+
+import (
+	"errors"
+	"fmt"
+	"github.com/go-sre/core/runtime"
+	"github.com/go-sre/host/controller"
+	"sync"
+)
+
+var healthLocation = PkgUrl + "/health"
+
+var healthRoutes = struct {
+	mu sync.RWMutex
+	m  map[string][]string
+}{m: make(map[string][]string)}
+
+// RegisterHealthRoutes - associate egress routes with a resource, the health of the route upstreams is included
+// in Ping responses for the resource
+func RegisterHealthRoutes(uri string, routes ...string) error {
+	if uri == "" {
+		return errors.New("invalid argument: uri is empty")
+	}
+	healthRoutes.mu.Lock()
+	defer healthRoutes.mu.Unlock()
+	healthRoutes.m[uri] = append(healthRoutes.m[uri], routes...)
+	return nil
+}
+
+// HealthStatus - status of the upstreams of the egress routes of a resource, unavailable if any upstream is unhealthy
+func HealthStatus(uri string) *runtime.Status {
+	healthRoutes.mu.RLock()
+	routes := healthRoutes.m[uri]
+	healthRoutes.mu.RUnlock()
+	var errs []error
+	for _, route := range routes {
+		for _, health := range controller.EgressTable().Health(route) {
+			if !health.Healthy {
+				errs = append(errs, errors.New(fmt.Sprintf("upstream is unhealthy: [%v] [%v] [%v]", health.Route, health.Target, health.LastError)))
+			}
+		}
+	}
+	if len(errs) > 0 {
+		return runtime.NewStatus(runtime.StatusUnavailable, healthLocation, errs...)
+	}
+	return runtime.NewStatusOK()
+}
@@ -0,0 +1,46 @@
+package messaging
+
+import (
+	"fmt"
+	"github.com/go-sre/core/runtime"
+	"github.com/go-sre/host/controller"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+)
+
+func ExampleHealthStatus() {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+	host := strings.TrimPrefix(server.URL, "http://")
+
+	uri := "urn:ping:health"
+	hc := controller.NewHealthCheckConfig(true, host, "", time.Millisecond*100, time.Millisecond*50)
+	hc.UnhealthyThreshold = 1
+	errs := controller.EgressTable().AddController(controller.NewRoute("health-route", controller.EgressTraffic, "", false, hc))
+	RegisterHealthRoutes(uri, "health-route")
+	fmt.Printf("test: HealthStatus() -> [errs:%v] [%v]\n", errs, HealthStatus(uri))
+
+	controller.SetHealthFn(func(route, target string, healthy bool) {})
+	stop := controller.EgressTable().StartHealthChecks(nil)
+	time.Sleep(time.Millisecond * 300)
+	stop()
+
+	status := HealthStatus(uri)
+	fmt.Printf("test: HealthStatus() -> [code:%v] [errors:%v]\n", status.Code(), len(status.Errors()))
+
+	c := make(chan Message, 16)
+	RegisterResource(uri, c)
+	go pingGood(c)
+	status = Ping[runtime.DebugError](nil, uri)
+	fmt.Printf("test: Ping() -> [code:%v] [location:%v]\n", status.Code(), status.Location() == healthLocation)
+
+	//Output:
+	//test: HealthStatus() -> [errs:[]] [OK]
+	//test: HealthStatus() -> [code:Unavailable] [errors:1]
+	//test: Ping() -> [code:Unavailable] [location:true]
+
+}
@@ -14,7 +14,8 @@ const (
 
 var pingLocation = PkgUrl + "/ping"
 
-// Ping - templated function to "ping" a resource
+// Ping - templated function to "ping" a resource, the status is unavailable if an upstream of an egress route
+// registered for the resource is unhealthy
 func Ping[E runtime.ErrorHandler](ctx context.Context, uri string) (status *runtime.Status) {
 	var e E
 
@@ -37,6 +38,11 @@ func Ping[E runtime.ErrorHandler](ctx context.Context, uri string) (status *runt
 		if result.Status == nil {
 			return e.Handle(ctx, pingLocation, errors.New(fmt.Sprintf("ping response status not available: [%v]", uri))).SetCode(runtime.StatusNotProvided)
 		}
+		if result.Status.OK() {
+			if status = HealthStatus(uri); !status.OK() {
+				return status
+			}
+		}
 		return result.Status
 	}
 	return e.Handle(ctx, pingLocation, errors.New(fmt.Sprintf("ping response time out: [%v]", uri))).SetCode(runtime.StatusDeadlineExceeded)
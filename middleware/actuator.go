@@ -1,6 +1,7 @@
 package middleware
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 	"github.com/go-sre/host/controller"
@@ -9,6 +10,16 @@ import (
 	"strings"
 )
 
+const (
+	historyResource   = "history"
+	rollbackResource  = "rollback"
+	overridesResource = "overrides"
+	healthResource    = "health"
+
+	// Changes to the all routes name are applied to every route in the table
+	allRoutes = "*"
+)
+
 func ActuatorHandler(w http.ResponseWriter, r *http.Request) {
 	if r == nil {
 		w.WriteHeader(http.StatusBadRequest)
@@ -20,18 +31,81 @@ func ActuatorHandler(w http.ResponseWriter, r *http.Request) {
 		w.Write([]byte(err.Error()))
 		return
 	}
-	if r.URL.Query() == nil || len(r.URL.Query()) == 0 {
+	// Reads are GET requests, and changes are restricted to POST and PUT
+	read := r.Method == http.MethodGet || r.Method == ""
+	if !read && r.Method != http.MethodPost && r.Method != http.MethodPut {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		w.Write([]byte(fmt.Sprintf("invalid argument: method [%v] is not allowed", r.Method)))
+		return
+	}
+	traffic, route, behavior, err := parseUrl(r.URL)
+	if read && isTraffic(traffic) {
+		// Snapshots of a table or a route do not require a route or behavior name
+		err = nil
+	}
+	if err != nil {
 		w.WriteHeader(http.StatusBadRequest)
-		err := errors.New(fmt.Sprintf("invalid argument: request URL does not contain any query arguments"))
 		w.Write([]byte(err.Error()))
 		return
 	}
-	traffic, route, behavior, err := parseUrl(r.URL)
+	authBehavior := behavior
+	if !read && behavior == rollbackResource {
+		authBehavior = rollbackBehavior(traffic, route)
+	}
+	caller, status, err := authorize(r, traffic, route, authBehavior, !read)
 	if err != nil {
+		w.WriteHeader(status)
+		w.Write([]byte(err.Error()))
+		return
+	}
+	if read {
+		if len(r.URL.Query()) > 0 {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			w.Write([]byte("invalid argument: changes require a POST or PUT request"))
+			return
+		}
+		switch behavior {
+		case historyResource:
+			writeJson(w, table(traffic).History(route))
+			return
+		case overridesResource:
+			writeJson(w, table(traffic).Overrides(route))
+			return
+		case healthResource:
+			writeJson(w, table(traffic).Health(route))
+			return
+		}
+		snapshotHandler(w, traffic, route, behavior)
+		return
+	}
+	if behavior == rollbackResource {
+		rollbackHandler(w, traffic, route, caller)
+		return
+	}
+	if r.URL.Query() == nil || len(r.URL.Query()) == 0 {
 		w.WriteHeader(http.StatusBadRequest)
+		err = errors.New(fmt.Sprintf("invalid argument: request URL does not contain any query arguments"))
 		w.Write([]byte(err.Error()))
 		return
 	}
+	values := r.URL.Query()
+	// The behavior is authorized from the path, a query argument would bypass that authorization
+	if values.Has(controller.BehaviorKey) {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(fmt.Sprintf("invalid argument: query argument is not allowed [%v]", controller.BehaviorKey)))
+		return
+	}
+	values.Set(controller.BehaviorKey, behavior)
+	values.Set(controller.CallerKey, caller)
+	if route == allRoutes {
+		if errs := table(traffic).SignalAll(values); len(errs) > 0 {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(fmt.Sprintf("%v", errs)))
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		return
+	}
 	var ctrl controller.Controller
 	if traffic == controller.EgressTraffic {
 		ctrl = controller.EgressTable().LookupByName(route)
@@ -44,8 +118,6 @@ func ActuatorHandler(w http.ResponseWriter, r *http.Request) {
 		w.Write([]byte(err.Error()))
 		return
 	}
-	values := r.URL.Query()
-	values.Add(controller.BehaviorKey, behavior)
 	err = ctrl.Signal(values)
 	if err != nil {
 		w.WriteHeader(http.StatusBadRequest)
@@ -55,6 +127,91 @@ func ActuatorHandler(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusOK)
 }
 
+// snapshotHandler - write the JSON configuration of all controllers in a table, a controller, or a behavior
+func snapshotHandler(w http.ResponseWriter, traffic, route, behavior string) {
+	var snapshot any
+	if route == "" {
+		snapshot = table(traffic).Snapshot()
+	} else {
+		ctrl := lookupController(traffic, route)
+		if ctrl == nil {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(fmt.Sprintf("invalid argument: route [%s] not found in [%s] table", route, traffic)))
+			return
+		}
+		config := ctrl.Snapshot()
+		snapshot = config
+		if behavior != "" {
+			var err error
+			snapshot, err = config.Behavior(behavior)
+			if err != nil {
+				w.WriteHeader(http.StatusNotFound)
+				w.Write([]byte(err.Error()))
+				return
+			}
+		}
+	}
+	writeJson(w, snapshot)
+}
+
+func rollbackHandler(w http.ResponseWriter, traffic, route, caller string) {
+	err := table(traffic).Rollback(route, caller)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(err.Error()))
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+// rollbackBehavior - a rollback is authorized as a write of the behavior of the change it restores, or of all
+// behaviors if the route has no change to rollback
+func rollbackBehavior(traffic, route string) string {
+	if change, ok := table(traffic).LastChange(route); ok {
+		return change.Behavior
+	}
+	return AnyValue
+}
+
+func writeJson(w http.ResponseWriter, v any) {
+	buf, err := json.Marshal(v)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(err.Error()))
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(buf)
+}
+
+func isTraffic(traffic string) bool {
+	return traffic == controller.IngressTraffic || traffic == controller.EgressTraffic
+}
+
+func table(traffic string) controller.Table {
+	if traffic == controller.EgressTraffic {
+		return controller.EgressTable()
+	}
+	return controller.IngressTable()
+}
+
+// lookupController - exact lookup by name, including the host and default controllers which are not in the table
+// controllers
+func lookupController(traffic, route string) controller.Controller {
+	t := table(traffic)
+	if route == controller.HostControllerName && traffic == controller.IngressTraffic {
+		return t.Host()
+	}
+	if ctrl := t.Default(); route == controller.DefaultControllerName || route == ctrl.Name() {
+		return ctrl
+	}
+	if ctrl := t.LookupByName(route); ctrl != nil && ctrl.Name() == route {
+		return ctrl
+	}
+	return nil
+}
+
 func parseUrl(url *url.URL) (traffic string, route string, behavior string, err error) {
 	if url == nil {
 		return "", "", "", errors.New("invalid argument: request URL is nil")
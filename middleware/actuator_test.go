@@ -1,12 +1,14 @@
 package middleware
 
 import (
+	"encoding/json"
 	"fmt"
 	"github.com/go-sre/host/controller"
 	"io"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"time"
 )
 
 func init() {
@@ -45,8 +47,32 @@ func ExampleActuatorHandler_InvalidArgument() {
 
 	*/
 
+	req, _ = http.NewRequest("POST", "http://localhost:8080/actuator/egress/timeout-route/timeout", nil)
+	record = httptest.NewRecorder()
+	ActuatorHandler(record, req)
+	resp = record.Result()
+	body, _ = io.ReadAll(resp.Body)
+	fmt.Printf("test: ActuatorHandler(POST) -> [statusCode:%v] [body:%v]\n", resp.StatusCode, string(body))
+
+	req, _ = http.NewRequest("GET", "http://localhost:8080/actuator/egress/timeout-route/timeout?enabled=false", nil)
+	record = httptest.NewRecorder()
+	ActuatorHandler(record, req)
+	resp = record.Result()
+	body, _ = io.ReadAll(resp.Body)
+	fmt.Printf("test: ActuatorHandler(GET) -> [statusCode:%v] [body:%v]\n", resp.StatusCode, string(body))
+
+	req, _ = http.NewRequest("DELETE", "http://localhost:8080/actuator/egress/timeout-route/timeout", nil)
+	record = httptest.NewRecorder()
+	ActuatorHandler(record, req)
+	resp = record.Result()
+	body, _ = io.ReadAll(resp.Body)
+	fmt.Printf("test: ActuatorHandler(DELETE) -> [statusCode:%v] [body:%v]\n", resp.StatusCode, string(body))
+
 	//Output:
-	//test: ActuatorHandler(nil) -> [statusCode:400] [body:invalid argument: request URL does not contain any query arguments]
+	//test: ActuatorHandler(nil) -> [statusCode:400] [body:invalid argument: request URL path does not contain traffic type]
+	//test: ActuatorHandler(POST) -> [statusCode:400] [body:invalid argument: request URL does not contain any query arguments]
+	//test: ActuatorHandler(GET) -> [statusCode:405] [body:invalid argument: changes require a POST or PUT request]
+	//test: ActuatorHandler(DELETE) -> [statusCode:405] [body:invalid argument: method [DELETE] is not allowed]
 
 }
 
@@ -54,7 +80,7 @@ func ExampleActuatorHandler_Timeout() {
 	ctrl := controller.EgressTable().LookupByName(timeoutRoute)
 	fmt.Printf("test: TimeoutController() -> [enabled:%v] [duration:%v]\n", ctrl.Timeout().IsEnabled(), ctrl.Timeout().Duration())
 
-	req, _ := http.NewRequest("GET", "http://localhost:8080/actuator/egress/timeout-route/timeout?enabled=false&duration=2s", nil)
+	req, _ := http.NewRequest("POST", "http://localhost:8080/actuator/egress/timeout-route/timeout?enabled=false&duration=2s", nil)
 	record := httptest.NewRecorder()
 	ActuatorHandler(record, req)
 	resp := record.Result()
@@ -64,10 +90,16 @@ func ExampleActuatorHandler_Timeout() {
 	ctrl = controller.EgressTable().LookupByName(timeoutRoute)
 	fmt.Printf("test: TimeoutController() -> [enabled:%v] [duration:%v]\n", ctrl.Timeout().IsEnabled(), ctrl.Timeout().Duration())
 
+	req, _ = http.NewRequest("POST", "http://localhost:8080/actuator/egress/timeout-route/timeout?pct=5000", nil)
+	record = httptest.NewRecorder()
+	ActuatorHandler(record, req)
+	fmt.Printf("test: ActuatorHandler(pct=5000) -> [statusCode:%v] [body:%v]\n", record.Result().StatusCode, record.Body.String())
+
 	//Output:
 	//test: TimeoutController() -> [enabled:true] [duration:1ms]
 	//test: ActuatorHandler(disabled,2s) -> [statusCode:200] [body:]
 	//test: TimeoutController() -> [enabled:false] [duration:2s]
+	//test: ActuatorHandler(pct=5000) -> [statusCode:400] [body:invalid argument: percentage value must be > -100 and <= 1000, excluding 0 [5000]]
 
 }
 
@@ -75,7 +107,7 @@ func ExampleActuatorHandler_RateLimit() {
 	ctrl := controller.EgressTable().LookupByName(rateLimitRoute)
 	fmt.Printf("test: RateLimitController() -> [enabled:%v] [limit:%v] [burst:%v]\n", ctrl.RateLimiter().IsEnabled(), ctrl.RateLimiter().Limit(), ctrl.RateLimiter().Burst())
 
-	req, _ := http.NewRequest("GET", "http://localhost:8080/actuator/egress/rate-limit-route/rate-limit?enabled=false&limit=45&burst=5", nil)
+	req, _ := http.NewRequest("POST", "http://localhost:8080/actuator/egress/rate-limit-route/rate-limit?enabled=false&limit=45&burst=5", nil)
 	record := httptest.NewRecorder()
 	ActuatorHandler(record, req)
 	resp := record.Result()
@@ -96,7 +128,7 @@ func ExampleActuatorHandler_Retry() {
 	ctrl := controller.EgressTable().LookupByName(retryRoute)
 	fmt.Printf("test: RetryController() -> [enabled:%v] [limit:%v] [burst:%v] [wait:%v]\n", ctrl.Retry().IsEnabled(), ctrl.Retry().Limit(), ctrl.Retry().Burst(), ctrl.Retry().Wait())
 
-	req, _ := http.NewRequest("GET", "http://localhost:8080/actuator/egress/retry-route/retry?enabled=false&limit=45&burst=5&wait=100ms", nil)
+	req, _ := http.NewRequest("POST", "http://localhost:8080/actuator/egress/retry-route/retry?enabled=false&limit=45&burst=5&wait=100ms", nil)
 	record := httptest.NewRecorder()
 	ActuatorHandler(record, req)
 	resp := record.Result()
@@ -117,7 +149,7 @@ func ExampleActuatorHandler_Proxy() {
 	ctrl := controller.EgressTable().LookupByName(proxyRoute)
 	fmt.Printf("test: ProxyController() -> [enabled:%v] [pattern:%v]\n", ctrl.Proxy().IsEnabled(), ctrl.Proxy().Pattern())
 
-	req, _ := http.NewRequest("GET", "http://localhost:8080/actuator/egress/proxy-route/proxy?enabled=false&pattern=http://localhost:8080", nil)
+	req, _ := http.NewRequest("POST", "http://localhost:8080/actuator/egress/proxy-route/proxy?enabled=false&pattern=http://localhost:8080", nil)
 	record := httptest.NewRecorder()
 	ActuatorHandler(record, req)
 	resp := record.Result()
@@ -177,3 +209,145 @@ func ExampleActuatorParse_Url() {
 	//test: parseUrl(http://localhost:8080/actuator/egress/test-route/timeout) -> [t:egress] [r:test-route] [b:timeout] [err:<nil>]
 
 }
+
+func ExampleActuatorHandler_Snapshot() {
+	route := controller.NewRoute("snapshot-route", controller.EgressTraffic, "", false, controller.NewTimeoutConfig(true, 504, time.Millisecond*500),
+		controller.NewRetryConfig(true, 10, 2, time.Millisecond*100, []int{503}))
+	route.Pattern = "www.snapshot.com"
+	errs := controller.EgressTable().AddController(route)
+	fmt.Printf("test: AddController() -> %v\n", errs)
+
+	for _, uri := range []string{"/actuator/egress/snapshot-route", "/actuator/egress/snapshot-route/retry", "/actuator/egress/snapshot-route/hedge", "/actuator/egress/invalid-route"} {
+		req, _ := http.NewRequest("GET", "http://localhost:8080"+uri, nil)
+		record := httptest.NewRecorder()
+		ActuatorHandler(record, req)
+		resp := record.Result()
+		body, _ := io.ReadAll(resp.Body)
+		fmt.Printf("test: ActuatorHandler(%v) -> [statusCode:%v] [body:%v]\n", uri, resp.StatusCode, string(body))
+	}
+
+	req, _ := http.NewRequest("POST", "http://localhost:8080/actuator/egress/snapshot-route/timeout?enabled=false", nil)
+	ActuatorHandler(httptest.NewRecorder(), req)
+	req, _ = http.NewRequest("GET", "http://localhost:8080/actuator/egress/snapshot-route/timeout", nil)
+	record := httptest.NewRecorder()
+	ActuatorHandler(record, req)
+	body, _ := io.ReadAll(record.Result().Body)
+	fmt.Printf("test: ActuatorHandler(signalled) -> [statusCode:%v] [body:%v]\n", record.Result().StatusCode, string(body))
+
+	//Output:
+	//test: AddController() -> []
+	//test: ActuatorHandler(/actuator/egress/snapshot-route) -> [statusCode:200] [body:{"Name":"snapshot-route","Pattern":"www.snapshot.com","Traffic":"egress","Ping":false,"Protocol":"","Priority":"","Timeout":{"Enabled":true,"StatusCode":504,"Duration":"500ms","Propagate":false},"RateLimiter":null,"Retry":{"Enabled":true,"Limit":10,"Burst":2,"Wait":"100ms","StatusCodes":[503],"MaxAttempts":2,"Backoff":"constant","MaxWait":"","Budget":0,"Errors":null,"NonIdempotent":false,"MaxBodySize":1048576,"GRPCCodes":null,"MinLimit":0,"MaxLimit":0,"MinBurst":0,"MaxBurst":0},"Proxy":null,"CircuitBreaker":null,"Bulkhead":null,"Hedge":null,"Mirror":null,"Pool":null,"HealthCheck":null}]
+	//test: ActuatorHandler(/actuator/egress/snapshot-route/retry) -> [statusCode:200] [body:{"Enabled":true,"Limit":10,"Burst":2,"Wait":"100ms","StatusCodes":[503],"MaxAttempts":2,"Backoff":"constant","MaxWait":"","Budget":0,"Errors":null,"NonIdempotent":false,"MaxBodySize":1048576,"GRPCCodes":null,"MinLimit":0,"MaxLimit":0,"MinBurst":0,"MaxBurst":0}]
+	//test: ActuatorHandler(/actuator/egress/snapshot-route/hedge) -> [statusCode:404] [body:invalid argument: behavior [hedge] is not configured [snapshot-route]]
+	//test: ActuatorHandler(/actuator/egress/invalid-route) -> [statusCode:404] [body:invalid argument: route [invalid-route] not found in [egress] table]
+	//test: ActuatorHandler(signalled) -> [statusCode:200] [body:{"Enabled":false,"StatusCode":504,"Duration":"500ms","Propagate":false}]
+
+}
+
+func ExampleActuatorHandler_Rollback() {
+	route := controller.NewRoute("rollback-route", controller.EgressTraffic, "", false, controller.NewTimeoutConfig(true, 504, time.Millisecond*500))
+	errs := controller.EgressTable().AddController(route)
+	fmt.Printf("test: AddController() -> %v\n", errs)
+
+	req, _ := http.NewRequest("POST", "http://localhost:8080/actuator/egress/rollback-route/timeout?duration=2s&caller=spoofed", nil)
+	req.SetBasicAuth("admin", "secret")
+	ActuatorHandler(httptest.NewRecorder(), req)
+
+	req, _ = http.NewRequest("GET", "http://localhost:8080/actuator/egress/rollback-route/history", nil)
+	record := httptest.NewRecorder()
+	ActuatorHandler(record, req)
+	var changes []controller.Change
+	err := json.NewDecoder(record.Result().Body).Decode(&changes)
+	fmt.Printf("test: ActuatorHandler(history) -> [statusCode:%v] [err:%v] [changes:%v] [caller:%v] [old:%v] [new:%v]\n", record.Result().StatusCode, err, len(changes), changes[0].Caller, changes[0].Old, changes[0].New)
+
+	req, _ = http.NewRequest("POST", "http://localhost:8080/actuator/egress/rollback-route/rollback", nil)
+	record = httptest.NewRecorder()
+	ActuatorHandler(record, req)
+	fmt.Printf("test: ActuatorHandler(rollback) -> [statusCode:%v] [duration:%v]\n", record.Result().StatusCode, controller.EgressTable().LookupByName("rollback-route").Timeout().Duration())
+
+	record = httptest.NewRecorder()
+	ActuatorHandler(record, req)
+	body, _ := io.ReadAll(record.Result().Body)
+	fmt.Printf("test: ActuatorHandler(rollback) -> [statusCode:%v] [body:%v]\n", record.Result().StatusCode, string(body))
+
+	//Output:
+	//test: AddController() -> []
+	//test: ActuatorHandler(history) -> [statusCode:200] [err:<nil>] [changes:1] [caller:admin] [old:map[Duration:500ms Enabled:true Propagate:false StatusCode:504]] [new:map[Duration:2s Enabled:true Propagate:false StatusCode:504]]
+	//test: ActuatorHandler(rollback) -> [statusCode:200] [duration:500ms]
+	//test: ActuatorHandler(rollback) -> [statusCode:400] [body:invalid argument: route has no changes to rollback [rollback-route]]
+
+}
+
+func ExampleActuatorHandler_Overrides() {
+	route := controller.NewRoute("override-route", controller.EgressTraffic, "", false, controller.NewTimeoutConfig(true, 504, time.Millisecond*500))
+	errs := controller.EgressTable().AddController(route)
+	fmt.Printf("test: AddController() -> %v\n", errs)
+
+	req, _ := http.NewRequest("POST", "http://localhost:8080/actuator/egress/override-route/timeout?duration=2s&ttl=15m", nil)
+	req.SetBasicAuth("on-call", "secret")
+	record := httptest.NewRecorder()
+	ActuatorHandler(record, req)
+	fmt.Printf("test: ActuatorHandler(ttl) -> [statusCode:%v] [duration:%v]\n", record.Result().StatusCode, controller.EgressTable().LookupByName("override-route").Timeout().Duration())
+
+	req, _ = http.NewRequest("GET", "http://localhost:8080/actuator/egress/override-route/overrides", nil)
+	record = httptest.NewRecorder()
+	ActuatorHandler(record, req)
+	var overrides []controller.Override
+	err := json.NewDecoder(record.Result().Body).Decode(&overrides)
+	fmt.Printf("test: ActuatorHandler(overrides) -> [statusCode:%v] [err:%v] [overrides:%v] [behavior:%v] [caller:%v] [remaining:%v]\n", record.Result().StatusCode, err, len(overrides),
+		overrides[0].Behavior, overrides[0].Caller, overrides[0].Remaining > time.Minute*14)
+
+	req, _ = http.NewRequest("POST", "http://localhost:8080/actuator/egress/override-route/rollback", nil)
+	ActuatorHandler(httptest.NewRecorder(), req)
+	fmt.Printf("test: ActuatorHandler(rollback) -> [overrides:%v] [duration:%v]\n", len(controller.EgressTable().Overrides("override-route")), controller.EgressTable().LookupByName("override-route").Timeout().Duration())
+
+	//Output:
+	//test: AddController() -> []
+	//test: ActuatorHandler(ttl) -> [statusCode:200] [duration:2s]
+	//test: ActuatorHandler(overrides) -> [statusCode:200] [err:<nil>] [overrides:1] [behavior:timeout] [caller:on-call] [remaining:true]
+	//test: ActuatorHandler(rollback) -> [overrides:0] [duration:500ms]
+
+}
+
+func ExampleActuatorHandler_AllRoutes() {
+	for _, uri := range []string{"/actuator/ingress/*/rate-limit?pct=-20", "/actuator/ingress/*/invalid?pct=-20"} {
+		req, _ := http.NewRequest("POST", "http://localhost:8080"+uri, nil)
+		record := httptest.NewRecorder()
+		ActuatorHandler(record, req)
+		fmt.Printf("test: ActuatorHandler(%v) -> [statusCode:%v] [body:%v]\n", uri, record.Result().StatusCode, record.Body.String())
+	}
+
+	//Output:
+	//test: ActuatorHandler(/actuator/ingress/*/rate-limit?pct=-20) -> [statusCode:200] [body:]
+	//test: ActuatorHandler(/actuator/ingress/*/invalid?pct=-20) -> [statusCode:400] [body:[invalid argument: behavior [invalid] is not supported]]
+
+}
+
+func ExampleActuatorHandler_Health() {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+	host := server.Listener.Addr().String()
+
+	hc := controller.NewHealthCheckConfig(true, host, "", time.Millisecond*100, time.Millisecond*50)
+	errs := controller.EgressTable().AddController(controller.NewRoute("health-route", controller.EgressTraffic, "", false, hc))
+	fmt.Printf("test: AddController() -> %v\n", errs)
+
+	stop := controller.EgressTable().StartHealthChecks(nil)
+	time.Sleep(time.Millisecond * 250)
+	stop()
+
+	req, _ := http.NewRequest("GET", "http://localhost:8080/actuator/egress/health-route/health", nil)
+	record := httptest.NewRecorder()
+	ActuatorHandler(record, req)
+	var health []controller.Health
+	err := json.NewDecoder(record.Result().Body).Decode(&health)
+	fmt.Printf("test: ActuatorHandler(health) -> [statusCode:%v] [err:%v] [targets:%v] [target:%v] [healthy:%v] [successes:%v]\n", record.Result().StatusCode, err, len(health),
+		health[0].Target == host, health[0].Healthy, health[0].Successes > 0)
+
+	//Output:
+	//test: AddController() -> []
+	//test: ActuatorHandler(health) -> [statusCode:200] [err:<nil>] [targets:1] [target:true] [healthy:true] [successes:true]
+
+}
@@ -0,0 +1,264 @@
+package middleware
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	AuthorizationHeaderName = "Authorization"
+	TimestampHeaderName     = "X-Actuator-Timestamp"
+	NonceHeaderName         = "X-Actuator-Nonce"
+	BearerScheme            = "Bearer"
+	HmacScheme              = "HMAC-SHA256"
+	AnyValue                = "*"
+
+	DefaultHmacSkew = time.Minute * 5
+)
+
+// Principal - an authenticated actuator caller
+type Principal struct {
+	Name  string
+	Roles []string
+}
+
+// Authenticator - authenticates an actuator request, ok is false if the request does not contain credentials
+// for the authenticator, and an error is returned for invalid credentials
+type Authenticator func(r *http.Request) (principal Principal, ok bool, err error)
+
+// AuthorizationRule - grants roles read, or read and write, access to the actuator. An empty or "*" traffic,
+// route or behavior matches any value.
+type AuthorizationRule struct {
+	Roles    []string
+	Traffic  string
+	Route    string
+	Behavior string
+	Write    bool
+}
+
+// HmacKey - shared secret for HMAC signed requests
+type HmacKey struct {
+	Secret    []byte
+	Principal Principal
+}
+
+var (
+	authenticators []Authenticator
+	rules          []AuthorizationRule
+)
+
+// SetActuatorAuthenticators - configure actuator authentication, the first authenticator to find credentials
+// authenticates the request. Without authenticators, the actuator does not authenticate callers.
+func SetActuatorAuthenticators(fns ...Authenticator) {
+	authenticators = fns
+}
+
+// SetActuatorRules - configure actuator authorization. Without rules, authenticated callers have full access.
+func SetActuatorRules(r []AuthorizationRule) {
+	rules = r
+}
+
+// BearerAuthenticator - static bearer token authentication
+func BearerAuthenticator(tokens map[string]Principal) Authenticator {
+	return func(r *http.Request) (Principal, bool, error) {
+		token, ok := credentials(r, BearerScheme)
+		if !ok {
+			return Principal{}, false, nil
+		}
+		for t, p := range tokens {
+			if subtle.ConstantTimeCompare([]byte(t), []byte(token)) == 1 {
+				return p, true, nil
+			}
+		}
+		return Principal{}, true, errors.New("invalid credentials: bearer token is invalid")
+	}
+}
+
+// HmacAuthenticator - HMAC signed request authentication, the Authorization header contains the key id and the
+// signature : "HMAC-SHA256 key-id:hex-signature". See SignRequest for the signed content.
+//
+// A nonce is accepted once within the timestamp skew, so a captured request can not be replayed against this
+// authenticator. The nonces are not shared, so a captured request can still be replayed once against each other
+// process within the skew, and requests should be sent over TLS.
+func HmacAuthenticator(keys map[string]HmacKey, skew time.Duration) Authenticator {
+	if skew <= 0 {
+		skew = DefaultHmacSkew
+	}
+	nonces := &nonceCache{seen: make(map[string]time.Time)}
+	return func(r *http.Request) (Principal, bool, error) {
+		cred, ok := credentials(r, HmacScheme)
+		if !ok {
+			return Principal{}, false, nil
+		}
+		id, signature, found := strings.Cut(cred, ":")
+		key, ok1 := keys[id]
+		if !found || !ok1 {
+			return Principal{}, true, errors.New(fmt.Sprintf("invalid credentials: HMAC key is invalid [%v]", id))
+		}
+		ts, err := strconv.ParseInt(r.Header.Get(TimestampHeaderName), 10, 64)
+		if err != nil {
+			return Principal{}, true, errors.New("invalid credentials: HMAC timestamp is invalid")
+		}
+		if d := time.Since(time.Unix(ts, 0)); d > skew || d < -skew {
+			return Principal{}, true, errors.New("invalid credentials: HMAC timestamp is expired")
+		}
+		nonce := r.Header.Get(NonceHeaderName)
+		if nonce == "" {
+			return Principal{}, true, errors.New("invalid credentials: HMAC nonce is empty")
+		}
+		expected := sign(r, key.Secret, ts, nonce)
+		if !hmac.Equal([]byte(expected), []byte(signature)) {
+			return Principal{}, true, errors.New("invalid credentials: HMAC signature is invalid")
+		}
+		// Nonces are recorded after the signature is verified, so only signed requests are recorded
+		if !nonces.add(id+":"+nonce, time.Unix(ts, 0).Add(skew)) {
+			return Principal{}, true, errors.New("invalid credentials: HMAC nonce has been used")
+		}
+		return key.Principal, true, nil
+	}
+}
+
+// nonceCache - the nonces of authenticated requests, a nonce is kept until its timestamp expires
+type nonceCache struct {
+	mu   sync.Mutex
+	seen map[string]time.Time
+}
+
+// add - record a nonce, returns false if the nonce has been used
+func (c *nonceCache) add(nonce string, expires time.Time) bool {
+	now := time.Now()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for n, exp := range c.seen {
+		if now.After(exp) {
+			delete(c.seen, n)
+		}
+	}
+	if _, ok := c.seen[nonce]; ok {
+		return false
+	}
+	c.seen[nonce] = expires
+	return true
+}
+
+// SignRequest - sign an actuator request with a random nonce, the signature is the HMAC-SHA256 of the method,
+// request URI, timestamp and nonce, separated by new lines
+func SignRequest(r *http.Request, id string, secret []byte, t time.Time) {
+	ts := t.Unix()
+	buf := make([]byte, 16)
+	rand.Read(buf)
+	nonce := hex.EncodeToString(buf)
+	r.Header.Set(TimestampHeaderName, strconv.FormatInt(ts, 10))
+	r.Header.Set(NonceHeaderName, nonce)
+	r.Header.Set(AuthorizationHeaderName, HmacScheme+" "+id+":"+sign(r, secret, ts, nonce))
+}
+
+func sign(r *http.Request, secret []byte, ts int64, nonce string) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(r.Method + "\n" + r.URL.RequestURI() + "\n" + strconv.FormatInt(ts, 10) + "\n" + nonce))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// CertificateAuthenticator - mTLS authentication, the client certificate subject, or subject common name,
+// is matched
+func CertificateAuthenticator(subjects map[string]Principal) Authenticator {
+	return func(r *http.Request) (Principal, bool, error) {
+		if r.TLS == nil || len(r.TLS.PeerCertificates) == 0 {
+			return Principal{}, false, nil
+		}
+		subject := r.TLS.PeerCertificates[0].Subject
+		if p, ok := subjects[subject.String()]; ok {
+			return p, true, nil
+		}
+		if p, ok := subjects[subject.CommonName]; ok {
+			return p, true, nil
+		}
+		return Principal{}, true, errors.New(fmt.Sprintf("invalid credentials: certificate subject is not authorized [%v]", subject))
+	}
+}
+
+func credentials(r *http.Request, scheme string) (string, bool) {
+	auth := r.Header.Get(AuthorizationHeaderName)
+	if len(auth) <= len(scheme) || !strings.EqualFold(auth[:len(scheme)], scheme) || auth[len(scheme)] != ' ' {
+		return "", false
+	}
+	return strings.TrimSpace(auth[len(scheme)+1:]), true
+}
+
+// authorize - authenticate and authorize an actuator request, returns the caller identity, or the response status
+// code and an error
+func authorize(r *http.Request, traffic, route, behavior string, write bool) (caller string, status int, err error) {
+	if len(authenticators) == 0 {
+		return callerIdentity(r), http.StatusOK, nil
+	}
+	var principal Principal
+	var ok bool
+	for _, fn := range authenticators {
+		principal, ok, err = fn(r)
+		if err != nil {
+			return "", http.StatusUnauthorized, err
+		}
+		if ok {
+			break
+		}
+	}
+	if !ok {
+		return "", http.StatusUnauthorized, errors.New("invalid credentials: request is not authenticated")
+	}
+	if len(rules) == 0 {
+		return principal.Name, http.StatusOK, nil
+	}
+	for _, rule := range rules {
+		if rule.allows(principal, traffic, route, behavior, write) {
+			return principal.Name, http.StatusOK, nil
+		}
+	}
+	access := "read"
+	if write {
+		access = "write"
+	}
+	return "", http.StatusForbidden, errors.New(fmt.Sprintf("invalid access: [%v] is not authorized to %v [%v/%v/%v]", principal.Name, access, traffic, route, behavior))
+}
+
+func (a AuthorizationRule) allows(p Principal, traffic, route, behavior string, write bool) bool {
+	if write && !a.Write {
+		return false
+	}
+	if !matches(a.Traffic, traffic) || !matches(a.Route, route) || !matches(a.Behavior, behavior) {
+		return false
+	}
+	for _, role := range a.Roles {
+		if role == AnyValue {
+			return true
+		}
+		for _, r := range p.Roles {
+			if r == role {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func matches(rule, value string) bool {
+	return rule == "" || rule == AnyValue || rule == value
+}
+
+// callerIdentity - the caller recorded in the change history when authentication is not configured, the basic
+// authentication user or the remote address
+func callerIdentity(r *http.Request) string {
+	if user, _, ok := r.BasicAuth(); ok && user != "" {
+		return user
+	}
+	return r.RemoteAddr
+}
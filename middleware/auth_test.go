@@ -0,0 +1,168 @@
+package middleware
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"fmt"
+	"github.com/go-sre/host/controller"
+	"net/http"
+	"net/http/httptest"
+	"time"
+)
+
+func ExampleAuthenticator() {
+	bearer := BearerAuthenticator(map[string]Principal{"token-1234": {Name: "on-call-bot", Roles: []string{"on-call"}}})
+	hmacFn := HmacAuthenticator(map[string]HmacKey{"key-1": {Secret: []byte("secret"), Principal: Principal{Name: "deployer"}}}, 0)
+	cert := CertificateAuthenticator(map[string]Principal{"ops-client": {Name: "ops"}})
+
+	req, _ := http.NewRequest("POST", "http://localhost:8080/actuator/egress/route/proxy?enabled=false", nil)
+	p, ok, err := bearer(req)
+	fmt.Printf("test: BearerAuthenticator(none) -> [principal:%v] [ok:%v] [err:%v]\n", p.Name, ok, err)
+
+	req.Header.Set(AuthorizationHeaderName, "Bearer token-1234")
+	p, ok, err = bearer(req)
+	fmt.Printf("test: BearerAuthenticator() -> [principal:%v] [ok:%v] [err:%v]\n", p.Name, ok, err)
+
+	req.Header.Set(AuthorizationHeaderName, "Bearer token-5678")
+	p, ok, err = bearer(req)
+	fmt.Printf("test: BearerAuthenticator(invalid) -> [principal:%v] [ok:%v] [err:%v]\n", p.Name, ok, err)
+
+	SignRequest(req, "key-1", []byte("secret"), time.Now())
+	p, ok, err = hmacFn(req)
+	fmt.Printf("test: HmacAuthenticator() -> [principal:%v] [ok:%v] [err:%v]\n", p.Name, ok, err)
+
+	p, ok, err = hmacFn(req)
+	fmt.Printf("test: HmacAuthenticator(replay) -> [principal:%v] [ok:%v] [err:%v]\n", p.Name, ok, err)
+
+	SignRequest(req, "key-1", []byte("secret"), time.Now())
+	req.Header.Del(NonceHeaderName)
+	p, ok, err = hmacFn(req)
+	fmt.Printf("test: HmacAuthenticator(no-nonce) -> [principal:%v] [ok:%v] [err:%v]\n", p.Name, ok, err)
+
+	SignRequest(req, "key-1", []byte("invalid"), time.Now())
+	p, ok, err = hmacFn(req)
+	fmt.Printf("test: HmacAuthenticator(invalid) -> [principal:%v] [ok:%v] [err:%v]\n", p.Name, ok, err)
+
+	SignRequest(req, "key-1", []byte("secret"), time.Now().Add(-time.Hour))
+	p, ok, err = hmacFn(req)
+	fmt.Printf("test: HmacAuthenticator(expired) -> [principal:%v] [ok:%v] [err:%v]\n", p.Name, ok, err)
+
+	req.TLS = &tls.ConnectionState{PeerCertificates: []*x509.Certificate{{Subject: pkix.Name{CommonName: "ops-client"}}}}
+	p, ok, err = cert(req)
+	fmt.Printf("test: CertificateAuthenticator() -> [principal:%v] [ok:%v] [err:%v]\n", p.Name, ok, err)
+
+	req.TLS.PeerCertificates[0].Subject.CommonName = "unknown-client"
+	p, ok, err = cert(req)
+	fmt.Printf("test: CertificateAuthenticator(invalid) -> [principal:%v] [ok:%v] [err:%v]\n", p.Name, ok, err)
+
+	//Output:
+	//test: BearerAuthenticator(none) -> [principal:] [ok:false] [err:<nil>]
+	//test: BearerAuthenticator() -> [principal:on-call-bot] [ok:true] [err:<nil>]
+	//test: BearerAuthenticator(invalid) -> [principal:] [ok:true] [err:invalid credentials: bearer token is invalid]
+	//test: HmacAuthenticator() -> [principal:deployer] [ok:true] [err:<nil>]
+	//test: HmacAuthenticator(replay) -> [principal:] [ok:true] [err:invalid credentials: HMAC nonce has been used]
+	//test: HmacAuthenticator(no-nonce) -> [principal:] [ok:true] [err:invalid credentials: HMAC nonce is empty]
+	//test: HmacAuthenticator(invalid) -> [principal:] [ok:true] [err:invalid credentials: HMAC signature is invalid]
+	//test: HmacAuthenticator(expired) -> [principal:] [ok:true] [err:invalid credentials: HMAC timestamp is expired]
+	//test: CertificateAuthenticator() -> [principal:ops] [ok:true] [err:<nil>]
+	//test: CertificateAuthenticator(invalid) -> [principal:] [ok:true] [err:invalid credentials: certificate subject is not authorized [CN=unknown-client]]
+
+}
+
+func ExampleActuatorHandler_Authorization() {
+	SetActuatorAuthenticators(BearerAuthenticator(map[string]Principal{
+		"on-call-token": {Name: "on-call-bot", Roles: []string{"on-call"}},
+		"admin-token":   {Name: "admin", Roles: []string{"admin"}},
+	}))
+	SetActuatorRules([]AuthorizationRule{
+		{Roles: []string{"admin"}, Write: true},
+		{Roles: []string{"on-call"}, Traffic: controller.EgressTraffic, Behavior: controller.ProxyBehavior, Write: true},
+		{Roles: []string{AnyValue}},
+	})
+	defer SetActuatorAuthenticators()
+	defer SetActuatorRules(nil)
+	controller.EgressTable().AddController(controller.NewRoute("auth-route", controller.EgressTraffic, "", false, controller.NewProxyConfig(true, "http://localhost:8081", nil, nil, "")))
+
+	for _, s := range []struct{ method, uri, token string }{
+		{"POST", "/actuator/egress/auth-route/proxy?enabled=false", ""},
+		{"POST", "/actuator/egress/auth-route/proxy?enabled=false", "invalid-token"},
+		{"POST", "/actuator/egress/auth-route/proxy?enabled=false", "on-call-token"},
+		{"POST", "/actuator/egress/auth-route/proxy?behavior=timeout&duration=1ms", "on-call-token"},
+		{"POST", "/actuator/ingress/host/rate-limit?limit=10", "on-call-token"},
+		{"GET", "/actuator/egress/auth-route/history", "on-call-token"},
+		{"POST", "/actuator/egress/auth-route/rollback", "admin-token"},
+	} {
+		req, _ := http.NewRequest(s.method, "http://localhost:8080"+s.uri, nil)
+		if s.token != "" {
+			req.Header.Set(AuthorizationHeaderName, "Bearer "+s.token)
+		}
+		record := httptest.NewRecorder()
+		ActuatorHandler(record, req)
+		body := record.Body.String()
+		if record.Result().StatusCode == http.StatusOK {
+			// History responses contain timestamps
+			body = ""
+		}
+		fmt.Printf("test: ActuatorHandler(%v,%v) -> [statusCode:%v] [body:%v]\n", s.uri, s.token, record.Result().StatusCode, body)
+	}
+	changes := controller.EgressTable().History("auth-route")
+	fmt.Printf("test: History() -> [changes:%v] [caller:%v] [rollback:%v]\n", len(changes), changes[0].Caller, changes[1].Caller)
+
+	//Output:
+	//test: ActuatorHandler(/actuator/egress/auth-route/proxy?enabled=false,) -> [statusCode:401] [body:invalid credentials: request is not authenticated]
+	//test: ActuatorHandler(/actuator/egress/auth-route/proxy?enabled=false,invalid-token) -> [statusCode:401] [body:invalid credentials: bearer token is invalid]
+	//test: ActuatorHandler(/actuator/egress/auth-route/proxy?enabled=false,on-call-token) -> [statusCode:200] [body:]
+	//test: ActuatorHandler(/actuator/egress/auth-route/proxy?behavior=timeout&duration=1ms,on-call-token) -> [statusCode:400] [body:invalid argument: query argument is not allowed [behavior]]
+	//test: ActuatorHandler(/actuator/ingress/host/rate-limit?limit=10,on-call-token) -> [statusCode:403] [body:invalid access: [on-call-bot] is not authorized to write [ingress/host/rate-limit]]
+	//test: ActuatorHandler(/actuator/egress/auth-route/history,on-call-token) -> [statusCode:200] [body:]
+	//test: ActuatorHandler(/actuator/egress/auth-route/rollback,admin-token) -> [statusCode:200] [body:]
+	//test: History() -> [changes:2] [caller:on-call-bot] [rollback:admin]
+
+}
+
+func ExampleActuatorHandler_Authorization_Rollback() {
+	SetActuatorAuthenticators(BearerAuthenticator(map[string]Principal{
+		"on-call-token": {Name: "on-call-bot", Roles: []string{"on-call"}},
+		"admin-token":   {Name: "admin", Roles: []string{"admin"}},
+	}))
+	SetActuatorRules([]AuthorizationRule{
+		{Roles: []string{"admin"}, Write: true},
+		{Roles: []string{"on-call"}, Traffic: controller.EgressTraffic, Behavior: controller.ProxyBehavior, Write: true},
+	})
+	defer SetActuatorAuthenticators()
+	defer SetActuatorRules(nil)
+	controller.EgressTable().AddController(controller.NewRoute("auth-rollback-route", controller.EgressTraffic, "", false,
+		controller.NewTimeoutConfig(true, 504, time.Second), controller.NewProxyConfig(true, "http://localhost:8081", nil, nil, "")))
+	controller.EgressTable().AddController(controller.NewRoute("auth-rollback-none", controller.EgressTraffic, "", false))
+
+	// A rollback is authorized as a write of the behavior it restores
+	for _, s := range []struct{ uri, token string }{
+		{"/actuator/egress/auth-rollback-route/timeout?duration=2s", "admin-token"},
+		{"/actuator/egress/auth-rollback-route/rollback", "on-call-token"},
+		{"/actuator/egress/auth-rollback-route/proxy?enabled=false", "on-call-token"},
+		{"/actuator/egress/auth-rollback-route/rollback", "on-call-token"},
+		{"/actuator/egress/auth-rollback-route/rollback", "on-call-token"},
+		{"/actuator/egress/auth-rollback-none/rollback", "on-call-token"},
+		{"/actuator/egress/auth-rollback-none/rollback", "admin-token"},
+	} {
+		req, _ := http.NewRequest(http.MethodPost, "http://localhost:8080"+s.uri, nil)
+		req.Header.Set(AuthorizationHeaderName, "Bearer "+s.token)
+		record := httptest.NewRecorder()
+		ActuatorHandler(record, req)
+		fmt.Printf("test: ActuatorHandler(%v,%v) -> [statusCode:%v] [body:%v]\n", s.uri, s.token, record.Result().StatusCode, record.Body.String())
+	}
+	ctrl := controller.EgressTable().LookupByName("auth-rollback-route")
+	fmt.Printf("test: LookupByName() -> [timeout:%v] [proxy:%v]\n", ctrl.Timeout().Duration(), ctrl.Proxy().IsEnabled())
+
+	//Output:
+	//test: ActuatorHandler(/actuator/egress/auth-rollback-route/timeout?duration=2s,admin-token) -> [statusCode:200] [body:]
+	//test: ActuatorHandler(/actuator/egress/auth-rollback-route/rollback,on-call-token) -> [statusCode:403] [body:invalid access: [on-call-bot] is not authorized to write [egress/auth-rollback-route/timeout]]
+	//test: ActuatorHandler(/actuator/egress/auth-rollback-route/proxy?enabled=false,on-call-token) -> [statusCode:200] [body:]
+	//test: ActuatorHandler(/actuator/egress/auth-rollback-route/rollback,on-call-token) -> [statusCode:200] [body:]
+	//test: ActuatorHandler(/actuator/egress/auth-rollback-route/rollback,on-call-token) -> [statusCode:403] [body:invalid access: [on-call-bot] is not authorized to write [egress/auth-rollback-route/timeout]]
+	//test: ActuatorHandler(/actuator/egress/auth-rollback-none/rollback,on-call-token) -> [statusCode:403] [body:invalid access: [on-call-bot] is not authorized to write [egress/auth-rollback-none/*]]
+	//test: ActuatorHandler(/actuator/egress/auth-rollback-none/rollback,admin-token) -> [statusCode:400] [body:invalid argument: route has no changes to rollback [auth-rollback-none]]
+	//test: LookupByName() -> [timeout:2s] [proxy:true]
+
+}
@@ -0,0 +1,261 @@
+package middleware
+
+import (
+	"context"
+	"github.com/go-sre/host/controller"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	authorityKey = ":authority"
+)
+
+// ControllerUnaryClientInterceptor - gRPC unary client interceptor that applies egress controllers, rate limiting,
+// timeouts and retries of configured status codes
+func ControllerUnaryClientInterceptor(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	start := time.Now()
+	attempt := 1
+	r, ctrl, ctx := egressGRPC(ctx, cc, method)
+	if rlc := ctrl.RateLimiter(); rlc.IsEnabled() && !rlc.AllowKey(rlc.Key(r), "") {
+		code := controller.CodeFromHttpStatus(rlc.StatusCode())
+		ctrl.LogHttpEgress(start, time.Since(start), r, controller.NewGRPCResponse(r, code), 0, controller.RateLimitFlag)
+		return status.Error(code, "request was rate limited")
+	}
+	invoke := func(ctx context.Context) error {
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+	err, statusFlags := invokeGRPC(ctx, ctrl.Timeout(), invoke)
+	if rc := ctrl.Retry(); rc.IsEnabled() {
+		var wait time.Duration
+
+		rc.Deposit()
+		for ; attempt < rc.MaxAttempts() && rc.IsRetryableCode(status.Code(err)); attempt++ {
+			ok, retryFlags := rc.AllowRetry()
+			if !ok {
+				// Retry rate limited or over budget
+				statusFlags = controller.RetryFlag + "-" + retryFlags
+				break
+			}
+			duration := time.Since(start)
+			wait = rc.Backoff(attempt, wait)
+			if !rc.Sleep(ctx, wait) {
+				statusFlags = controller.RetryCancelledFlag
+				break
+			}
+			ctrl.LogHttpEgress(start, duration, r, controller.NewGRPCResponse(r, status.Code(err)), attempt, statusFlags)
+			start = time.Now()
+			err, statusFlags = invokeGRPC(ctx, ctrl.Timeout(), invoke)
+		}
+	}
+	ctrl.LogHttpEgress(start, time.Since(start), r, controller.NewGRPCResponse(r, status.Code(err)), attempt, statusFlags)
+	return err
+}
+
+// ControllerStreamClientInterceptor - gRPC stream client interceptor that applies egress controllers, rate limiting
+// and timeouts. Streams are not retried, and the route timeout applies to the whole stream
+func ControllerStreamClientInterceptor(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	start := time.Now()
+	r, ctrl, ctx := egressGRPC(ctx, cc, method)
+	if rlc := ctrl.RateLimiter(); rlc.IsEnabled() && !rlc.AllowKey(rlc.Key(r), "") {
+		code := controller.CodeFromHttpStatus(rlc.StatusCode())
+		ctrl.LogHttpEgress(start, time.Since(start), r, controller.NewGRPCResponse(r, code), 0, controller.RateLimitFlag)
+		return nil, status.Error(code, "request was rate limited")
+	}
+	s := &clientStream{ctrl: ctrl, req: r, start: start, parent: ctx, cancel: func() {}, serverStreams: desc.ServerStreams}
+	if tc := ctrl.Timeout(); tc.IsEnabled() && tc.Duration() > 0 {
+		ctx, s.cancel = context.WithTimeout(ctx, tc.Duration())
+	}
+	cs, err := streamer(ctx, desc, cc, method, opts...)
+	if err != nil {
+		s.done(err)
+		return nil, err
+	}
+	s.ClientStream = cs
+	return s, nil
+}
+
+// clientStream - logs the stream once the final status is received
+type clientStream struct {
+	grpc.ClientStream
+	ctrl          controller.Controller
+	req           *http.Request
+	start         time.Time
+	parent        context.Context
+	cancel        context.CancelFunc
+	serverStreams bool
+	once          sync.Once
+}
+
+func (s *clientStream) RecvMsg(m any) error {
+	err := s.ClientStream.RecvMsg(m)
+	if err != nil || !s.serverStreams {
+		s.done(err)
+	}
+	return err
+}
+
+func (s *clientStream) done(err error) {
+	s.once.Do(func() {
+		s.cancel()
+		code := codes.OK
+		if err != io.EOF {
+			code = status.Code(err)
+		}
+		statusFlags := ""
+		if code == codes.DeadlineExceeded && s.parent.Err() == nil {
+			statusFlags = controller.UpstreamTimeoutFlag
+		}
+		s.ctrl.LogHttpEgress(s.start, time.Since(s.start), s.req, controller.NewGRPCResponse(s.req, code), 1, statusFlags)
+	})
+}
+
+// ControllerUnaryServerInterceptor - gRPC unary server interceptor that applies the ingress host and route controllers
+func ControllerUnaryServerInterceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	var resp any
+	err := serveGRPC(ctx, info.FullMethod, func(ctx context.Context) error {
+		var err error
+		resp, err = handler(ctx, req)
+		return err
+	})
+	return resp, err
+}
+
+// ControllerStreamServerInterceptor - gRPC stream server interceptor that applies the ingress host and route
+// controllers, the route timeout applies to the whole stream
+func ControllerStreamServerInterceptor(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	return serveGRPC(ss.Context(), info.FullMethod, func(ctx context.Context) error {
+		return handler(srv, &serverStream{ServerStream: ss, ctx: ctx})
+	})
+}
+
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *serverStream) Context() context.Context {
+	return s.ctx
+}
+
+// serveGRPC - apply the ingress host controller rate limiting and bulkhead, and the route controller bulkhead and
+// timeout, then log the call with the gRPC status
+func serveGRPC(ctx context.Context, fullMethod string, serve func(ctx context.Context) error) error {
+	start := time.Now().UTC()
+	md, _ := metadata.FromIncomingContext(ctx)
+	r := grpcRequest(ctx, first(md.Get(authorityKey)), fullMethod, md)
+	ctrl := controller.IngressTable().Host()
+	priority := controller.IngressTable().Priority(r)
+	code := codes.OK
+
+	if rlc := ctrl.RateLimiter(); rlc.IsEnabled() && rlc.IsAdaptive() {
+		if !rlc.Acquire(priority) {
+			return rejectGRPC(ctrl, start, r, rlc.StatusCode(), controller.RateLimitFlag)
+		}
+		defer func() {
+			rlc.Release(time.Since(start), controller.HttpStatusFromCode(code) >= http.StatusInternalServerError)
+		}()
+	} else if rlc.IsEnabled() && !rlc.AllowKey(rlc.Key(r), priority) {
+		return rejectGRPC(ctrl, start, r, rlc.StatusCode(), controller.RateLimitFlag)
+	}
+	if bh := ctrl.Bulkhead(); bh.IsEnabled() {
+		if !bh.Acquire(ctx) {
+			return rejectGRPC(ctrl, start, r, bh.StatusCode(), controller.BulkheadFlag)
+		}
+		defer bh.Release()
+	}
+	ctrl = controller.IngressTable().LookupHttp(r)
+	if bh := ctrl.Bulkhead(); bh.IsEnabled() {
+		if !bh.Acquire(ctx) {
+			return rejectGRPC(ctrl, start, r, bh.StatusCode(), controller.BulkheadFlag)
+		}
+		defer bh.Release()
+	}
+	if toc := ctrl.Timeout(); toc.IsEnabled() && toc.Duration() > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, toc.Duration())
+		defer cancel()
+	}
+	err := serve(ctx)
+	code = status.Code(err)
+	ctrl.LogGRPCIngress(start, time.Since(start), r, code, "")
+	return err
+}
+
+func rejectGRPC(ctrl controller.Controller, start time.Time, r *http.Request, statusCode int, statusFlags string) error {
+	code := controller.CodeFromHttpStatus(statusCode)
+	ctrl.LogGRPCIngress(start, time.Since(start), r, code, statusFlags)
+	if statusFlags == controller.BulkheadFlag {
+		return status.Error(code, "request was rejected by the bulkhead")
+	}
+	return status.Error(code, "request was rate limited")
+}
+
+// egressGRPC - lookup the egress controller of a call, and propagate the request id and route name in the
+// outgoing metadata
+func egressGRPC(ctx context.Context, cc *grpc.ClientConn, method string) (*http.Request, controller.Controller, context.Context) {
+	md, _ := metadata.FromOutgoingContext(ctx)
+	target := ""
+	if cc != nil {
+		target = cc.Target()
+	}
+	r := grpcRequest(ctx, authority(target), method, md)
+	ctrl := controller.EgressTable().LookupHttp(r)
+	ctrl.UpdateHeaders(r)
+	if len(md.Get(controller.RequestIdHeaderName)) == 0 {
+		ctx = metadata.AppendToOutgoingContext(ctx, controller.RequestIdHeaderName, r.Header.Get(controller.RequestIdHeaderName))
+	}
+	ctx = metadata.AppendToOutgoingContext(ctx, controller.FromRouteHeaderName, ctrl.Name())
+	return r, ctrl, ctx
+}
+
+// invokeGRPC - invoke a call with the route timeout, a deadline exceeded from the route timeout is flagged as an
+// upstream timeout
+func invokeGRPC(ctx context.Context, tc controller.Timeout, invoke func(ctx context.Context) error) (err error, statusFlags string) {
+	if tc == nil || !tc.IsEnabled() || tc.Duration() <= 0 {
+		return invoke(ctx), ""
+	}
+	ctx2, cancel := context.WithTimeout(ctx, tc.Duration())
+	defer cancel()
+	err = invoke(ctx2)
+	if status.Code(err) == codes.DeadlineExceeded && ctx.Err() == nil {
+		statusFlags = controller.UpstreamTimeoutFlag
+	}
+	return
+}
+
+// grpcRequest - an HTTP request describing a gRPC call, used for route lookup and access logging. gRPC calls are
+// HTTP/2 POST requests to the full method path
+func grpcRequest(ctx context.Context, authority, fullMethod string, md metadata.MD) *http.Request {
+	req := &http.Request{Method: http.MethodPost, Proto: "HTTP/2.0", ProtoMajor: 2, Host: authority, Header: make(http.Header)}
+	req.URL = &url.URL{Scheme: "http", Host: authority, Path: fullMethod}
+	for k, v := range md {
+		if !strings.HasPrefix(k, ":") {
+			req.Header[http.CanonicalHeaderKey(k)] = v
+		}
+	}
+	return req.WithContext(ctx)
+}
+
+// authority - the host of a dial target, "dns:///localhost:8080" is "localhost:8080"
+func authority(target string) string {
+	if i := strings.LastIndex(target, "/"); i >= 0 {
+		return target[i+1:]
+	}
+	return target
+}
+
+func first(values []string) string {
+	if len(values) == 0 {
+		return ""
+	}
+	return values[0]
+}
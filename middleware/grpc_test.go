@@ -0,0 +1,75 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"github.com/go-sre/host/controller"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	grpcRoute  = "grpc-route"
+	grpcTarget = "localhost:50051"
+)
+
+func Example_grpcUnaryClient() {
+	cc, err := grpc.Dial(grpcTarget, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		fmt.Printf("test: Dial() -> [err:%v]\n", err)
+		return
+	}
+	defer cc.Close()
+
+	calls := 0
+	var md metadata.MD
+	invoker := func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		calls++
+		md, _ = metadata.FromOutgoingContext(ctx)
+		if calls == 1 {
+			return status.Error(codes.Unavailable, "unavailable")
+		}
+		return nil
+	}
+	err = ControllerUnaryClientInterceptor(context.Background(), "/test.Service/Get", nil, nil, cc, invoker)
+	fmt.Printf("test: ControllerUnaryClientInterceptor() -> [err:%v] [calls:%v] [from-route:%v] [request-id:%v]\n", err, calls, md.Get(controller.FromRouteHeaderName), len(md.Get(controller.RequestIdHeaderName)) == 1)
+
+	calls = 0
+	invoker = func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		calls++
+		return status.Error(codes.InvalidArgument, "invalid")
+	}
+	err = ControllerUnaryClientInterceptor(context.Background(), "/test.Service/Get", nil, nil, cc, invoker)
+	fmt.Printf("test: ControllerUnaryClientInterceptor() -> [code:%v] [calls:%v]\n", status.Code(err), calls)
+
+	//Output:
+	//test: Write() -> [{"traffic":"egress","route-name":"grpc-route","method":"POST","host":"localhost:50051","path":"/test.Service/Get","protocol":"HTTP/2.0","status-code":503,"status-flags":"","bytes-received":-1,"bytes-sent":0,"timeout-ms":-1,"rate-limit":-1,"rate-burst":-1,"rate-threshold":,"retry":false,"proxy":, "proxy-threshold":}]
+	//test: Write() -> [{"traffic":"egress","route-name":"grpc-route","method":"POST","host":"localhost:50051","path":"/test.Service/Get","protocol":"HTTP/2.0","status-code":200,"status-flags":"","bytes-received":-1,"bytes-sent":0,"timeout-ms":-1,"rate-limit":100,"rate-burst":10,"rate-threshold":,"retry":true,"proxy":, "proxy-threshold":}]
+	//test: ControllerUnaryClientInterceptor() -> [err:<nil>] [calls:2] [from-route:[grpc-route]] [request-id:true]
+	//test: Write() -> [{"traffic":"egress","route-name":"grpc-route","method":"POST","host":"localhost:50051","path":"/test.Service/Get","protocol":"HTTP/2.0","status-code":400,"status-flags":"","bytes-received":-1,"bytes-sent":0,"timeout-ms":-1,"rate-limit":-1,"rate-burst":-1,"rate-threshold":,"retry":false,"proxy":, "proxy-threshold":}]
+	//test: ControllerUnaryClientInterceptor() -> [code:InvalidArgument] [calls:1]
+
+}
+
+func Example_grpcUnaryServer() {
+	route := controller.NewRoute(grpcRoute, controller.IngressTraffic, "", false)
+	route.Pattern = "POST localhost:50051/test.Service/*"
+	errs := controller.IngressTable().AddController(route)
+	fmt.Printf("test: AddController() -> [errs:%v]\n", errs)
+
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(":authority", grpcTarget))
+	handler := func(ctx context.Context, req any) (any, error) {
+		return nil, status.Error(codes.NotFound, "not found")
+	}
+	_, err := ControllerUnaryServerInterceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: "/test.Service/Get"}, handler)
+	fmt.Printf("test: ControllerUnaryServerInterceptor() -> [code:%v]\n", status.Code(err))
+
+	//Output:
+	//test: AddController() -> [errs:[]]
+	//test: Write() -> [{"traffic":"ingress","route-name":"grpc-route","method":"POST","host":"localhost:50051","path":"/test.Service/Get","protocol":"HTTP/2.0","status-code":404,"status-flags":"","bytes-received":-1,"bytes-sent":0,"timeout-ms":-1,"rate-limit":-1,"rate-burst":-1,"rate-threshold":,"retry":,"proxy":, "proxy-threshold":}]
+	//test: ControllerUnaryServerInterceptor() -> [code:NotFound]
+
+}
@@ -1,6 +1,7 @@
 package middleware
 
 import (
+	"context"
 	"github.com/felixge/httpsnoop"
 	"github.com/go-sre/host/controller"
 	"net/http"
@@ -12,13 +13,51 @@ func ControllerHttpHostMetricsHandler(appHandler http.Handler, msg string) http.
 	wrappedH := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		start := time.Now().UTC()
 		ctrl := controller.IngressTable().Host()
+		priority := controller.IngressTable().Priority(r)
 		var m httpsnoop.Metrics
 
-		if rlc := ctrl.RateLimiter(); rlc.IsEnabled() && !rlc.Allow() {
+		if budget, ok := controller.RequestTimeout(r.Header); ok {
+			if budget <= 0 {
+				w.WriteHeader(http.StatusGatewayTimeout)
+				ctrl.LogHttpIngress(start, time.Since(start), r, http.StatusGatewayTimeout, 0, controller.DeadlineExceededFlag)
+				return
+			}
+			ctx, cancel := context.WithTimeout(r.Context(), budget)
+			defer cancel()
+			r = r.WithContext(ctx)
+		}
+
+		if rlc := ctrl.RateLimiter(); rlc.IsEnabled() && rlc.IsAdaptive() {
+			if !rlc.Acquire(priority) {
+				w.WriteHeader(rlc.StatusCode())
+				ctrl.LogHttpIngress(start, time.Since(start), r, rlc.StatusCode(), 0, controller.RateLimitFlag)
+				return
+			}
+			defer func() {
+				rlc.Release(time.Since(start), m.Code >= http.StatusInternalServerError)
+			}()
+		} else if rlc.IsEnabled() && !rlc.AllowKey(rlc.Key(r), priority) {
+			w.WriteHeader(rlc.StatusCode())
 			ctrl.LogHttpIngress(start, time.Since(start), r, rlc.StatusCode(), 0, controller.RateLimitFlag)
 			return
 		}
+		if bh := ctrl.Bulkhead(); bh.IsEnabled() {
+			if !bh.Acquire(r.Context()) {
+				w.WriteHeader(bh.StatusCode())
+				ctrl.LogHttpIngress(start, time.Since(start), r, bh.StatusCode(), 0, controller.BulkheadFlag)
+				return
+			}
+			defer bh.Release()
+		}
 		ctrl = controller.IngressTable().LookupHttp(r)
+		if bh := ctrl.Bulkhead(); bh.IsEnabled() {
+			if !bh.Acquire(r.Context()) {
+				w.WriteHeader(bh.StatusCode())
+				ctrl.LogHttpIngress(start, time.Since(start), r, bh.StatusCode(), 0, controller.BulkheadFlag)
+				return
+			}
+			defer bh.Release()
+		}
 		if toc := ctrl.Timeout(); toc.IsEnabled() && toc.Duration() > 0 {
 			m = httpsnoop.CaptureMetrics(http.TimeoutHandler(appHandler, toc.Duration(), msg), w, r)
 		} else {
@@ -2,13 +2,36 @@ package middleware
 
 import (
 	"fmt"
+	"github.com/go-sre/host/controller"
 	"net/http"
+	"net/http/httptest"
 )
 
 func ExampleTimeoutHandler() {
 
 }
 
+func ExampleControllerHttpHostMetricsHandler_RateLimited() {
+	errs := controller.IngressTable().SetHostController(controller.NewRoute("", controller.IngressTraffic, "", false, controller.NewRateLimiterConfig(true, http.StatusServiceUnavailable, 1, 1, "")))
+	defer controller.IngressTable().SetHostController(controller.NewRoute("", controller.IngressTraffic, "", false))
+	h := ControllerHttpHostMetricsHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}), "")
+
+	for i := 0; i < 2; i++ {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest("GET", "http://localhost:8080/search", nil))
+		fmt.Printf("test: ServeHTTP() -> [errs:%v] [status:%v]\n", errs, rec.Code)
+	}
+
+	//Output:
+	//test: Write() -> [{"traffic":"ingress","route-name":"*","method":"GET","host":"localhost:8080","path":"/search","protocol":"HTTP/1.1","status-code":200,"status-flags":"","bytes-received":-1,"bytes-sent":0,"timeout-ms":-1,"rate-limit":-1,"rate-burst":-1,"rate-threshold":,"retry":,"proxy":, "proxy-threshold":}]
+	//test: ServeHTTP() -> [errs:[]] [status:200]
+	//test: Write() -> [{"traffic":"ingress","route-name":"host","method":"GET","host":"localhost:8080","path":"/search","protocol":"HTTP/1.1","status-code":503,"status-flags":"RL","bytes-received":-1,"bytes-sent":0,"timeout-ms":-1,"rate-limit":1,"rate-burst":1,"rate-threshold":,"retry":,"proxy":, "proxy-threshold":}]
+	//test: ServeHTTP() -> [errs:[]] [status:503]
+
+}
+
 func _ExampleMiddleware() {
 	m := http.NewServeMux()
 	if m != nil {
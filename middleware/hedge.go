@@ -0,0 +1,157 @@
+package middleware
+
+import (
+	"context"
+	"github.com/go-sre/host/controller"
+	"io"
+	"net/http"
+	"time"
+)
+
+type hedgeResult struct {
+	index       int
+	start       time.Time
+	resp        *http.Response
+	err         error
+	statusFlags string
+}
+
+// cancelBody - cancels the context of a request once the response body is closed, for the winning hedged request
+// and the exchange timeout
+type cancelBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b cancelBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
+
+// send - exchange a request, balanced across the pool endpoints and hedged if configured
+func (w *controllerWrapper) send(ctrl controller.Controller, req *http.Request, attempt int) (*http.Response, error, string) {
+	if pl := ctrl.Pool(); pl.IsEnabled() {
+		return w.balance(ctrl, pl, req, attempt)
+	}
+	return w.forward(ctrl, req, attempt)
+}
+
+// forward - send the request, hedged if configured
+func (w *controllerWrapper) forward(ctrl controller.Controller, req *http.Request, attempt int) (*http.Response, error, string) {
+	if hc := ctrl.Hedge(); hc.IsEnabled() && hc.IsHedgeable(req.Method) && replayable(req) {
+		return w.hedge(ctrl, hc, req, attempt)
+	}
+	return w.exchange(ctrl.Timeout(), req)
+}
+
+// hedge - send copies of a request, spaced by the hedge delay, and return the first successful response.
+// Failed requests are logged with their error status flag, and losing requests are cancelled and logged with the
+// hedge cancelled status flag
+func (w *controllerWrapper) hedge(ctrl controller.Controller, hc controller.Hedge, req *http.Request, attempt int) (*http.Response, error, string) {
+	results := make(chan hedgeResult, hc.MaxHedges()+1)
+	var cancels []context.CancelFunc
+	launch := func() error {
+		ctx, cancel := context.WithCancel(req.Context())
+		r := req.Clone(ctx)
+		if req.GetBody != nil && req.Body != nil && req.Body != http.NoBody {
+			body, err := req.GetBody()
+			if err != nil {
+				cancel()
+				return err
+			}
+			r.Body = body
+		}
+		cancels = append(cancels, cancel)
+		index := len(cancels) - 1
+		go func() {
+			start := time.Now()
+			resp, err, statusFlags := w.exchange(ctrl.Timeout(), r)
+			results <- hedgeResult{index: index, start: start, resp: resp, err: err, statusFlags: statusFlags}
+		}()
+		return nil
+	}
+	if err := launch(); err != nil {
+		return nil, err, ""
+	}
+	timer := time.NewTimer(hc.Delay())
+	defer timer.Stop()
+	var last hedgeResult
+	for pending := 1; pending > 0; {
+		select {
+		case <-timer.C:
+			if len(cancels) <= hc.MaxHedges() && launch() == nil {
+				pending++
+				timer.Reset(hc.Delay())
+			}
+		case <-req.Context().Done():
+			for _, cancel := range cancels {
+				cancel()
+			}
+			go drainHedges(ctrl, req, results, pending, attempt)
+			return nil, req.Context().Err(), ""
+		case result := <-results:
+			pending--
+			// A timeout of the route Timeout behavior is a failure, as a later hedge may still succeed
+			if result.err != nil || result.statusFlags == controller.UpstreamTimeoutFlag {
+				cancels[result.index]()
+				if pending > 0 {
+					logFailedHedge(ctrl, req, result, attempt)
+				}
+				last = result
+				continue
+			}
+			for i, cancel := range cancels {
+				if i != result.index {
+					cancel()
+				}
+			}
+			if result.resp.Body != nil {
+				result.resp.Body = cancelBody{ReadCloser: result.resp.Body, cancel: cancels[result.index]}
+			} else {
+				cancels[result.index]()
+			}
+			if pending > 0 {
+				go drainHedges(ctrl, req, results, pending, attempt)
+			}
+			if len(cancels) > 1 && result.statusFlags == "" {
+				result.statusFlags = controller.HedgeFlag
+			}
+			return result.resp, nil, result.statusFlags
+		}
+	}
+	return last.resp, last.err, last.statusFlags
+}
+
+// drainHedges - log the losing hedged requests, which have already been cancelled
+func drainHedges(ctrl controller.Controller, req *http.Request, results chan hedgeResult, pending, attempt int) {
+	for ; pending > 0; pending-- {
+		result := <-results
+		discard(result.resp)
+		logCancelledHedge(ctrl, req, result, attempt)
+	}
+}
+
+// logFailedHedge - log a hedged request that failed while other hedged requests are pending
+func logFailedHedge(ctrl controller.Controller, req *http.Request, result hedgeResult, attempt int) {
+	resp := result.resp
+	statusFlags := result.statusFlags
+	if result.err != nil {
+		resp = &http.Response{Request: req}
+		statusFlags = errorFlag(result.err)
+	}
+	ctrl.LogHttpEgress(result.start, time.Since(result.start), req, resp, attempt, statusFlags)
+}
+
+func logCancelledHedge(ctrl controller.Controller, req *http.Request, result hedgeResult, attempt int) {
+	resp := result.resp
+	if resp == nil {
+		resp = &http.Response{Request: req}
+	}
+	ctrl.LogHttpEgress(result.start, time.Since(result.start), req, resp, attempt, controller.HedgeCancelledFlag)
+}
+
+// replayable - determine if a request can be sent more than once
+func replayable(req *http.Request) bool {
+	return req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+}
@@ -0,0 +1,89 @@
+package middleware
+
+import (
+	"fmt"
+	"github.com/go-sre/host/controller"
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+type slowFirstTripper struct {
+	calls int32
+}
+
+func (t *slowFirstTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	if atomic.AddInt32(&t.calls, 1) == 1 {
+		select {
+		case <-time.After(time.Millisecond * 200):
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		}
+		return &http.Response{Request: req, StatusCode: http.StatusAccepted}, nil
+	}
+	return &http.Response{Request: req, StatusCode: http.StatusOK}, nil
+}
+
+func Example_hedge() {
+	name := "hedge-route"
+	t := controller.NewEgressTable()
+	errs := t.AddController(controller.NewRoute(name, controller.EgressTraffic, "", false, controller.NewHedgeConfig(true, time.Millisecond*20, 2, nil)))
+	ctrl := t.LookupByName(name)
+	fmt.Printf("test: AddController() -> [errs:%v] [hedgeable-get:%v] [hedgeable-post:%v]\n", errs, ctrl.Hedge().IsHedgeable(http.MethodGet), ctrl.Hedge().IsHedgeable(http.MethodPost))
+
+	rt := &slowFirstTripper{}
+	w := &controllerWrapper{rt}
+	req, _ := http.NewRequest(http.MethodGet, "https://www.google.com", nil)
+	resp, err, statusFlags := w.send(ctrl, req, 1)
+	fmt.Printf("test: send(GET) -> [status:%v] [err:%v] [flags:%v] [calls:%v]\n", resp.StatusCode, err, statusFlags, atomic.LoadInt32(&rt.calls))
+
+	// Wait for the cancelled request to be logged
+	time.Sleep(time.Millisecond * 50)
+
+	rt = &slowFirstTripper{}
+	w = &controllerWrapper{rt}
+	req, _ = http.NewRequest(http.MethodPost, "https://www.google.com", nil)
+	resp, err, statusFlags = w.send(ctrl, req, 1)
+	fmt.Printf("test: send(POST) -> [status:%v] [err:%v] [flags:%v] [calls:%v]\n", resp.StatusCode, err, statusFlags, atomic.LoadInt32(&rt.calls))
+
+	//Output:
+	//test: AddController() -> [errs:[]] [hedgeable-get:true] [hedgeable-post:false]
+	//test: send(GET) -> [status:200] [err:<nil>] [flags:HG] [calls:2]
+	//test: Write() -> [{"traffic":"egress","route-name":"hedge-route","method":"GET","host":"www.google.com","path":"","protocol":"HTTP/1.1","status-code":0,"status-flags":"HG-CX","bytes-received":-1,"bytes-sent":0,"timeout-ms":-1,"rate-limit":-1,"rate-burst":-1,"rate-threshold":,"retry":,"proxy":, "proxy-threshold":}]
+	//test: send(POST) -> [status:202] [err:<nil>] [flags:] [calls:1]
+
+}
+
+type timeoutFirstTripper struct {
+	calls int32
+}
+
+func (t *timeoutFirstTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	if atomic.AddInt32(&t.calls, 1) == 1 {
+		<-req.Context().Done()
+		return nil, req.Context().Err()
+	}
+	time.Sleep(time.Millisecond * 30)
+	return &http.Response{Request: req, StatusCode: http.StatusOK}, nil
+}
+
+func Example_hedge_Timeout() {
+	name := "hedge-timeout-route"
+	t := controller.NewEgressTable()
+	errs := t.AddController(controller.NewRoute(name, controller.EgressTraffic, "", false, controller.NewTimeoutConfig(true, 504, time.Millisecond*40),
+		controller.NewHedgeConfig(true, time.Millisecond*20, 1, nil)))
+	ctrl := t.LookupByName(name)
+	fmt.Printf("test: AddController() -> [errs:%v]\n", errs)
+
+	// The first request times out before the hedged request completes
+	rt := &timeoutFirstTripper{}
+	w := &controllerWrapper{rt}
+	req, _ := http.NewRequest(http.MethodGet, "https://www.google.com", nil)
+	resp, err, statusFlags := w.send(ctrl, req, 1)
+	fmt.Printf("test: send(GET) -> [status:%v] [err:%v] [flags:%v] [calls:%v]\n", resp.StatusCode, err, statusFlags, atomic.LoadInt32(&rt.calls))
+
+	//Output:
+	//test: AddController() -> [errs:[]]
+	//test: Write() -> [{"traffic":"egress","route-name":"hedge-timeout-route","method":"GET","host":"www.google.com","path":"","protocol":"HTTP/1.1","status-code":504,"status-flags":"UT","bytes-received":-1,"bytes-sent":0,"timeout-ms":40,"rate-limit":-1,"rate-burst":-1,"rate-threshold":,"retry":,"proxy":, "proxy-threshold":}]
+	//test: send(GET) -> [status:200] [err:<nil>] [flags:HG] [calls:2]
+}
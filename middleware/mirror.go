@@ -0,0 +1,64 @@
+package middleware
+
+import (
+	"context"
+	"github.com/go-sre/host/controller"
+	"net/http"
+	"time"
+)
+
+// mirror - send a fire-and-forget copy of the request to the mirror pattern. The request body is buffered so that
+// it can be read by both requests, and requests with a body larger than the maximum are not mirrored. The mirror
+// response is discarded, and the status and latency are logged with the mirror status flag. A mirror that can not
+// be sent, including when the maximum in flight mirror requests are being sent, is logged with the mirror not sent
+// status flag, and does not fail the original request
+func (w *controllerWrapper) mirror(ctrl controller.Controller, mc controller.Mirror, req *http.Request) {
+	start := time.Now()
+	if !mc.Acquire() {
+		ctrl.LogHttpEgress(start, time.Since(start), req, &http.Response{Request: req}, 0, controller.MirrorNotSentFlag)
+		return
+	}
+	ok, err := bufferBody(req, mc.MaxBodySize())
+	if err != nil {
+		ctrl.LogHttpEgress(start, time.Since(start), req, &http.Response{Request: req}, 0, controller.MirrorNotSentFlag)
+	}
+	if err != nil || !ok {
+		mc.Release()
+		return
+	}
+	// The mirror is not cancelled when the original request completes, and has its own timeout
+	ctx, cancel := context.WithTimeout(context.Background(), mc.Timeout())
+	r := req.Clone(ctx)
+	if req.GetBody != nil && req.Body != nil && req.Body != http.NoBody {
+		body, err1 := req.GetBody()
+		if err1 != nil {
+			cancel()
+			mc.Release()
+			ctrl.LogHttpEgress(start, time.Since(start), req, &http.Response{Request: req}, 0, controller.MirrorNotSentFlag)
+			return
+		}
+		r.Body = body
+	}
+	r.URL = mc.BuildUrl(r.URL)
+	if r.URL != nil {
+		r.Host = r.URL.Host
+	}
+	r.RequestURI = ""
+	go func() {
+		defer mc.Release()
+		defer cancel()
+		start := time.Now()
+		resp, err2, statusFlags := w.exchange(ctrl.Timeout(), r)
+		if err2 != nil {
+			statusFlags = errorFlag(err2)
+			resp = &http.Response{Request: r}
+		}
+		if statusFlags != "" {
+			statusFlags = controller.MirrorFlag + "-" + statusFlags
+		} else {
+			statusFlags = controller.MirrorFlag
+		}
+		ctrl.LogHttpEgress(start, time.Since(start), r, resp, 0, statusFlags)
+		discard(resp)
+	}()
+}
@@ -0,0 +1,116 @@
+package middleware
+
+import (
+	"fmt"
+	"github.com/go-sre/host/controller"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+type mirrorTripper struct {
+	hosts chan string
+}
+
+func (t *mirrorTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		body, _ = io.ReadAll(req.Body)
+	}
+	t.hosts <- fmt.Sprintf("%v %v", req.URL.Host, string(body))
+	if req.URL.Host == "localhost:8081" {
+		return &http.Response{Request: req, StatusCode: http.StatusInternalServerError, Body: io.NopCloser(strings.NewReader(""))}, nil
+	}
+	return &http.Response{Request: req, StatusCode: http.StatusOK}, nil
+}
+
+func Example_mirror() {
+	name := "mirror-route"
+	t := controller.NewEgressTable()
+	errs := t.AddController(controller.NewRoute(name, controller.EgressTraffic, "", false, controller.NewMirrorConfig(true, "http://localhost:8081", 100)))
+	ctrl := t.LookupByName(name)
+	fmt.Printf("test: AddController() -> [errs:%v]\n", errs)
+
+	rt := &mirrorTripper{hosts: make(chan string, 2)}
+	w := &controllerWrapper{rt}
+	req, _ := http.NewRequest(http.MethodPost, "https://www.google.com", strings.NewReader("body"))
+	w.mirror(ctrl, ctrl.Mirror(), req)
+	host := <-rt.hosts
+
+	// Wait for the mirror request to be logged
+	time.Sleep(time.Millisecond * 50)
+	fmt.Printf("test: mirror() -> [%v]\n", host)
+
+	resp, err1, _ := w.exchange(ctrl.Timeout(), req)
+	fmt.Printf("test: exchange() -> [status:%v] [err:%v] [%v]\n", resp.StatusCode, err1, <-rt.hosts)
+
+	//Output:
+	//test: AddController() -> [errs:[]]
+	//test: Write() -> [{"traffic":"egress","route-name":"mirror-route","method":"POST","host":"localhost:8081","path":"","protocol":"HTTP/1.1","status-code":500,"status-flags":"MR","bytes-received":-1,"bytes-sent":0,"timeout-ms":-1,"rate-limit":-1,"rate-burst":-1,"rate-threshold":,"retry":,"proxy":, "proxy-threshold":}]
+	//test: mirror() -> [localhost:8081 body]
+	//test: exchange() -> [status:200] [err:<nil>] [www.google.com body]
+
+}
+
+func Example_mirror_NotSent() {
+	name := "mirror-route"
+	t := controller.NewEgressTable()
+	errs := t.AddController(controller.NewRoute(name, controller.EgressTraffic, "", false, controller.NewMirrorConfig(true, "http://localhost:8081", 100)))
+	ctrl := t.LookupByName(name)
+	fmt.Printf("test: AddController() -> [errs:%v] [timeout:%v]\n", errs, ctrl.Mirror().Timeout())
+
+	rt := &mirrorTripper{hosts: make(chan string, 2)}
+	w := &controllerWrapper{rt}
+	req, _ := http.NewRequest(http.MethodPost, "https://www.google.com", io.MultiReader(strings.NewReader("body"), errReader{}))
+	w.mirror(ctrl, ctrl.Mirror(), req)
+
+	// The original request is sent with the body that was read
+	resp, err, _ := w.exchange(ctrl.Timeout(), req)
+	fmt.Printf("test: exchange() -> [status:%v] [err:%v] [%v]\n", resp.StatusCode, err, <-rt.hosts)
+
+	//Output:
+	//test: AddController() -> [errs:[]] [timeout:5s]
+	//test: Write() -> [{"traffic":"egress","route-name":"mirror-route","method":"POST","host":"www.google.com","path":"","protocol":"HTTP/1.1","status-code":0,"status-flags":"MR-NS","bytes-received":-1,"bytes-sent":0,"timeout-ms":-1,"rate-limit":-1,"rate-burst":-1,"rate-threshold":,"retry":,"proxy":, "proxy-threshold":}]
+	//test: exchange() -> [status:200] [err:<nil>] [www.google.com body]
+
+}
+
+type blockingTripper struct {
+	release chan struct{}
+}
+
+func (t *blockingTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	<-t.release
+	return &http.Response{Request: req, StatusCode: http.StatusOK}, nil
+}
+
+func Example_mirror_MaxInFlight() {
+	name := "mirror-route"
+	t := controller.NewEgressTable()
+	config := controller.NewMirrorConfig(true, "http://localhost:8081", 100)
+	config.MaxInFlight = 1
+	errs := t.AddController(controller.NewRoute(name, controller.EgressTraffic, "", false, config))
+	ctrl := t.LookupByName(name)
+	fmt.Printf("test: AddController() -> [errs:%v] [max-in-flight:%v]\n", errs, ctrl.Mirror().MaxInFlight())
+
+	// The second mirror is not sent while the first mirror is in flight
+	rt := &blockingTripper{release: make(chan struct{})}
+	w := &controllerWrapper{rt}
+	req, _ := http.NewRequest(http.MethodGet, "https://www.google.com", nil)
+	w.mirror(ctrl, ctrl.Mirror(), req)
+	w.mirror(ctrl, ctrl.Mirror(), req)
+	close(rt.release)
+
+	// Wait for the first mirror to complete, and release the in flight request
+	time.Sleep(time.Millisecond * 50)
+	fmt.Printf("test: Acquire() -> [%v]\n", ctrl.Mirror().Acquire())
+	ctrl.Mirror().Release()
+
+	//Output:
+	//test: AddController() -> [errs:[]] [max-in-flight:1]
+	//test: Write() -> [{"traffic":"egress","route-name":"mirror-route","method":"GET","host":"www.google.com","path":"","protocol":"HTTP/1.1","status-code":0,"status-flags":"MR-NS","bytes-received":-1,"bytes-sent":0,"timeout-ms":-1,"rate-limit":-1,"rate-burst":-1,"rate-threshold":,"retry":,"proxy":, "proxy-threshold":}]
+	//test: Write() -> [{"traffic":"egress","route-name":"mirror-route","method":"GET","host":"localhost:8081","path":"","protocol":"HTTP/1.1","status-code":200,"status-flags":"MR","bytes-received":-1,"bytes-sent":0,"timeout-ms":-1,"rate-limit":-1,"rate-burst":-1,"rate-threshold":,"retry":,"proxy":, "proxy-threshold":}]
+	//test: Acquire() -> [true]
+
+}
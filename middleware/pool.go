@@ -0,0 +1,31 @@
+package middleware
+
+import (
+	"github.com/go-sre/host/controller"
+	"net/http"
+	"net/url"
+)
+
+// balance - send the request to an endpoint selected from the pool. The request URL host is rewritten to the
+// endpoint, and the request host is unchanged, so the access log records both the route host and the upstream host.
+// The outcome is recorded by the pool for outlier detection.
+func (w *controllerWrapper) balance(ctrl controller.Controller, pl controller.Pool, req *http.Request, attempt int) (*http.Response, error, string) {
+	host := pl.Select()
+	if host == "" || req.URL == nil {
+		return w.forward(ctrl, req, attempt)
+	}
+	if req.Host == "" {
+		req.Host = req.URL.Host
+	}
+	uri := new(url.URL)
+	*uri = *req.URL
+	uri.Host = host
+	req.URL = uri
+	resp, err, statusFlags := w.forward(ctrl, req, attempt)
+	statusCode := 0
+	if resp != nil {
+		statusCode = resp.StatusCode
+	}
+	pl.Done(host, statusCode, err, statusFlags == controller.UpstreamTimeoutFlag)
+	return resp, err, statusFlags
+}
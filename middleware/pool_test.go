@@ -0,0 +1,41 @@
+package middleware
+
+import (
+	"fmt"
+	"github.com/go-sre/host/controller"
+	"net/http"
+)
+
+type poolTripper struct{}
+
+func (t *poolTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.URL.Host == "host-1:8080" {
+		return &http.Response{Request: req, StatusCode: http.StatusServiceUnavailable}, nil
+	}
+	return &http.Response{Request: req, StatusCode: http.StatusOK}, nil
+}
+
+func Example_balance() {
+	name := "pool-route"
+	t := controller.NewEgressTable()
+	config := controller.NewPoolConfig(true, controller.RoundRobinPolicy, []string{"host-1:8080", "host-2:8080"})
+	config.ConsecutiveErrors = 1
+	errs := t.AddController(controller.NewRoute(name, controller.EgressTraffic, "", false, config))
+	ctrl := t.LookupByName(name)
+	fmt.Printf("test: AddController() -> [errs:%v]\n", errs)
+
+	w := &controllerWrapper{&poolTripper{}}
+	for i := 0; i < 3; i++ {
+		req, _ := http.NewRequest(http.MethodGet, "https://www.google.com/search", nil)
+		resp, err, _ := w.send(ctrl, req, 1)
+		fmt.Printf("test: send() -> [status:%v] [err:%v] [host:%v] [upstream:%v]\n", resp.StatusCode, err, req.Host, req.URL.Host)
+	}
+
+	//Output:
+	//test: AddController() -> [errs:[]]
+	//{"route":"pool-route", "endpoint":"host-1:8080", "ejection":"30s"}
+	//test: send() -> [status:503] [err:<nil>] [host:www.google.com] [upstream:host-1:8080]
+	//test: send() -> [status:200] [err:<nil>] [host:www.google.com] [upstream:host-2:8080]
+	//test: send() -> [status:200] [err:<nil>] [host:www.google.com] [upstream:host-2:8080]
+
+}
@@ -1,9 +1,11 @@
 package middleware
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"github.com/go-sre/host/controller"
+	"io"
 	"net/http"
 	"time"
 )
@@ -13,9 +15,9 @@ type controllerWrapper struct {
 }
 
 // RoundTrip - implementation of the RoundTrip interface for a transport, also logs an access entry
-func (w *controllerWrapper) RoundTrip(req *http.Request) (*http.Response, error) {
+func (w *controllerWrapper) RoundTrip(req *http.Request) (resp *http.Response, err error) {
 	var start = time.Now().UTC()
-	var retry = false
+	var attempt = 1
 
 	// !panic
 	if w == nil || w.rt == nil {
@@ -23,67 +25,296 @@ func (w *controllerWrapper) RoundTrip(req *http.Request) (*http.Response, error)
 	}
 	ctrl := controller.EgressTable().LookupHttp(req)
 	ctrl.UpdateHeaders(req)
-	if rlc := ctrl.RateLimiter(); rlc.IsEnabled() && !rlc.Allow() {
+	if budget, ok := controller.RemainingBudget(req.Context()); ok && budget <= 0 {
+		resp := &http.Response{Request: req, StatusCode: http.StatusGatewayTimeout}
+		ctrl.LogHttpEgress(start, time.Since(start), req, resp, 0, controller.DeadlineExceededFlag)
+		return nil, context.DeadlineExceeded
+	}
+	if rlc := ctrl.RateLimiter(); rlc.IsEnabled() && !rlc.AllowKey(rlc.Key(req), "") {
 		resp := &http.Response{Request: req, StatusCode: rlc.StatusCode()}
-		ctrl.LogHttpEgress(start, time.Since(start), req, resp, false, controller.RateLimitFlag)
+		ctrl.LogHttpEgress(start, time.Since(start), req, resp, 0, controller.RateLimitFlag)
+		return resp, nil
+	}
+	// The bulkhead is acquired first, so that a rejected request does not hold a half-open probe of the circuit breaker
+	if bh := ctrl.Bulkhead(); bh.IsEnabled() {
+		if !bh.Acquire(req.Context()) {
+			resp := &http.Response{Request: req, StatusCode: bh.StatusCode()}
+			ctrl.LogHttpEgress(start, time.Since(start), req, resp, 0, controller.BulkheadFlag)
+			return resp, nil
+		}
+		defer bh.Release()
+	}
+	cb := ctrl.CircuitBreaker()
+	if cb.IsEnabled() && !cb.Allow() {
+		resp := &http.Response{Request: req, StatusCode: cb.StatusCode()}
+		ctrl.LogHttpEgress(start, time.Since(start), req, resp, 0, controller.CircuitBreakerFlag)
 		return resp, nil
 	}
+	if mc := ctrl.Mirror(); mc.IsEnabled() && mc.Sample() {
+		w.mirror(ctrl, mc, req)
+	}
 	if pc := ctrl.Proxy(); pc.IsEnabled() && len(pc.Pattern()) > 0 {
-		req.URL = pc.BuildUrl(req.URL)
+		if len(pc.Targets()) > 0 {
+			target := pc.Select(req)
+			req = req.WithContext(controller.NewProxyTargetContext(req.Context(), target.Name))
+			req.URL = pc.BuildTargetUrl(req.URL, target)
+		} else {
+			req.URL = pc.BuildUrl(req.URL)
+		}
 		if req.URL != nil {
 			req.Host = req.URL.Host
 		}
 		for _, header := range pc.Headers() {
 			req.Header.Add(header.Name, header.Value)
 		}
+	} else if pc.IsFailover() && len(pc.Pattern()) > 0 {
+		active, probe := pc.FailoverState()
+		if active && !probe {
+			req = req.WithContext(controller.NewProxyTargetContext(req.Context(), controller.FailoverTarget))
+			req.URL = pc.BuildUrl(req.URL)
+			if req.URL != nil {
+				req.Host = req.URL.Host
+			}
+			for _, header := range pc.Headers() {
+				req.Header.Add(header.Name, header.Value)
+			}
+		} else {
+			// Primary outcomes, including recovery probes, drive the failover state
+			defer func() { pc.Record(primaryStatus(resp), err, probe) }()
+		}
 	}
-	resp, err, statusFlags := w.exchange(ctrl.Timeout(), req)
-	if err != nil {
-		return resp, err
+	rc := ctrl.Retry()
+	retry := rc.IsEnabled() && rc.IsRetryableMethod(req.Method)
+	replay := false
+	if retry {
+		ok, err := bufferBody(req, rc.MaxBodySize())
+		if err != nil {
+			releaseCircuit(cb)
+			return nil, err
+		}
+		replay = ok
 	}
-	if rc := ctrl.Retry(); rc.IsEnabled() && rc.IsValidStatusCode(resp.StatusCode) {
-		var retryFlags string
+	var statusFlags string
+	resp, err, statusFlags = w.send(ctrl, req, attempt)
+	if retry {
+		var wait time.Duration
 
-		prevFlags := statusFlags
-		retry, retryFlags = rc.IsRetryable(resp.StatusCode)
-		if retry {
-			ctrl.LogHttpEgress(start, time.Since(start), req, resp, false, prevFlags)
+		rc.Deposit()
+		for ; attempt < rc.MaxAttempts() && retryable(rc, resp, err, statusFlags); attempt++ {
+			if !replay {
+				// Request body is too large to buffer, or cannot be read again
+				statusFlags = controller.RetryReplayFlag
+				break
+			}
+			ok, retryFlags := rc.AllowRetry()
+			if !ok {
+				// Retry rate limited or over budget
+				statusFlags = controller.RetryFlag + "-" + retryFlags
+				break
+			}
+			duration := time.Since(start)
+			wait = rc.Backoff(attempt, wait)
+			if err == nil {
+				wait = retryWait(rc, resp, wait)
+			}
+			if !rc.Sleep(req.Context(), wait) {
+				statusFlags = controller.RetryCancelledFlag
+				break
+			}
+			if err != nil {
+				if cb.IsEnabled() {
+					cb.Record(0, err)
+				}
+				ctrl.LogHttpEgress(start, duration, req, &http.Response{Request: req}, attempt, errorFlag(err))
+			} else {
+				ctrl.LogHttpEgress(start, duration, req, resp, attempt, statusFlags)
+				discard(resp)
+			}
+			if err = rewindBody(req); err != nil {
+				releaseCircuit(cb)
+				return nil, err
+			}
 			start = time.Now()
-			resp, err, statusFlags = w.exchange(ctrl.Timeout(), req)
-			//if len(retryFlags) > 0 {
-			//	statusFlags = controller.RetryFlag + "-" + retryFlags
-			//} else {
-			//	statusFlags = controller.RetryFlag
-			//}
-		} else {
-			// Retry rate limited
-			statusFlags = controller.RetryFlag + "-" + retryFlags
+			resp, err, statusFlags = w.send(ctrl, req, attempt+1)
+		}
+	}
+	if err != nil {
+		if statusFlags == controller.DeadlineExceededFlag {
+			// The deadline expired before the request was sent
+			releaseCircuit(cb)
+			ctrl.LogHttpEgress(start, time.Since(start), req, &http.Response{Request: req, StatusCode: http.StatusGatewayTimeout}, attempt, statusFlags)
+			return nil, err
 		}
+		if cb.IsEnabled() {
+			cb.Record(0, err)
+		}
+		// Retry status flags take precedence over the error class of the final attempt
+		if statusFlags == "" {
+			statusFlags = errorFlag(err)
+		}
+		ctrl.LogHttpEgress(start, time.Since(start), req, &http.Response{Request: req}, attempt, statusFlags)
+		return resp, err
 	}
-	ctrl.LogHttpEgress(start, time.Since(start), req, resp, retry, statusFlags)
+	if cb.IsEnabled() {
+		cb.Record(resp.StatusCode, nil)
+	}
+	ctrl.LogHttpEgress(start, time.Since(start), req, resp, attempt, statusFlags)
 	return resp, err
 }
 
+// retryWait - a Retry-After from the upstream replaces the backoff wait, and is bounded by the maximum wait, or the
+// default Retry-After wait if there is no maximum wait
+func retryWait(rc controller.Retry, resp *http.Response, wait time.Duration) time.Duration {
+	d, ok := controller.RetryAfter(resp)
+	if !ok {
+		return wait
+	}
+	maxWait := rc.MaxWait()
+	if maxWait <= 0 {
+		maxWait = controller.DefaultRetryAfterWait
+	}
+	if d > maxWait {
+		return maxWait
+	}
+	return d
+}
+
+// releaseCircuit - release the circuit breaker probe of a request that is not sent upstream
+func releaseCircuit(cb controller.CircuitBreaker) {
+	if cb.IsEnabled() {
+		cb.Release()
+	}
+}
+
+// primaryStatus - status code of a primary outcome, 0 for a transport error
+func primaryStatus(resp *http.Response) int {
+	if resp == nil {
+		return 0
+	}
+	return resp.StatusCode
+}
+
+// retryable - determine if the outcome of an attempt can be retried, either a transport error of a configured
+// class, a timeout from the Timeout behavior, or a configured status code
+func retryable(rc controller.Retry, resp *http.Response, err error, statusFlags string) bool {
+	if statusFlags == controller.DeadlineExceededFlag {
+		return false
+	}
+	if err != nil {
+		return rc.IsRetryableError(err)
+	}
+	if statusFlags == controller.UpstreamTimeoutFlag && rc.IsRetryableTimeout() {
+		return true
+	}
+	return resp != nil && rc.IsValidStatusCode(resp.StatusCode)
+}
+
+// errorFlag - status flag for a transport error that is being retried
+func errorFlag(err error) string {
+	switch controller.ErrorClass(err) {
+	case controller.DialError:
+		return controller.UpstreamFailureFlag
+	case controller.ResetError:
+		return controller.UpstreamResetFlag
+	case controller.TimeoutError:
+		return controller.UpstreamTimeoutFlag
+	}
+	return ""
+}
+
 func (w *controllerWrapper) exchange(tc controller.Timeout, req *http.Request) (resp *http.Response, err error, statusFlags string) {
-	if tc == nil || !tc.IsEnabled() || tc.Duration() == 0 {
+	var duration time.Duration
+	statusCode := http.StatusGatewayTimeout
+	if tc != nil && tc.IsEnabled() {
+		duration = tc.Duration()
+		statusCode = tc.StatusCode()
+	}
+	// The request deadline limits the timeout, and the remaining budget is propagated to the upstream if configured
+	duration, budgeted := controller.EffectiveTimeout(req.Context(), duration)
+	if budgeted && duration <= 0 {
+		return nil, context.DeadlineExceeded, controller.DeadlineExceededFlag
+	}
+	if duration == 0 {
 		resp, err = w.rt.RoundTrip(req)
 		return
 	}
-	ctx, cancel := context.WithTimeout(req.Context(), tc.Duration())
-	//defer cancel()
+	ctx, cancel := context.WithTimeout(req.Context(), duration)
 	req = req.Clone(ctx)
+	if budgeted && tc != nil && tc.Propagate() {
+		controller.SetRequestTimeout(req.Header, duration)
+	}
 	resp, err = w.rt.RoundTrip(req)
+	if err != nil || resp == nil || resp.Body == nil {
+		cancel()
+	} else {
+		// The timeout applies until the response body is closed
+		resp.Body = cancelBody{ReadCloser: resp.Body, cancel: cancel}
+	}
 	if w.deadlineExceeded(err) {
-		resp = &http.Response{Request: req, StatusCode: tc.StatusCode()}
+		resp = &http.Response{Request: req, StatusCode: statusCode}
 		err = nil
 		statusFlags = controller.UpstreamTimeoutFlag
-		cancel()
 	}
 	return
 }
 
+// bufferBody - make the request body replayable, a request with a GetBody function is already replayable,
+// otherwise the body is buffered up to max bytes. Returns false if the body is larger than max, or can not be read,
+// in which case the request body is left intact for the first attempt
+func bufferBody(req *http.Request, max int64) (bool, error) {
+	if req.Body == nil || req.Body == http.NoBody || req.GetBody != nil {
+		return true, nil
+	}
+	if req.ContentLength > max {
+		return false, nil
+	}
+	buf, err := io.ReadAll(io.LimitReader(req.Body, max+1))
+	if err != nil {
+		// The bytes that were read are restored, so the request body is not truncated
+		req.Body = readCloser{Reader: io.MultiReader(bytes.NewReader(buf), req.Body), Closer: req.Body}
+		return false, err
+	}
+	if int64(len(buf)) > max {
+		req.Body = readCloser{Reader: io.MultiReader(bytes.NewReader(buf), req.Body), Closer: req.Body}
+		return false, nil
+	}
+	req.Body.Close()
+	req.Body = io.NopCloser(bytes.NewReader(buf))
+	req.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(buf)), nil
+	}
+	return true, nil
+}
+
+// rewindBody - reset the request body before a retry
+func rewindBody(req *http.Request) error {
+	if req.Body == nil || req.Body == http.NoBody || req.GetBody == nil {
+		return nil
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return err
+	}
+	req.Body = body
+	return nil
+}
+
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// discard - drain and close the body of a response that will not be returned to the caller
+func discard(resp *http.Response) {
+	if resp == nil || resp.Body == nil {
+		return
+	}
+	io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+}
+
 func (w *controllerWrapper) deadlineExceeded(err error) bool {
-	return err != nil && errors.As(err, &context.DeadlineExceeded)
+	return errors.Is(err, context.DeadlineExceeded)
 }
 
 // ControllerWrapTransport - provides a RoundTrip wrapper that applies controller controllers
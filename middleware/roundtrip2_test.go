@@ -1,12 +1,17 @@
 package middleware
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"github.com/go-sre/host/controller"
 	"golang.org/x/time/rate"
+	"io"
 	"net/http"
 	"net/url"
 	"strconv"
+	"strings"
+	"syscall"
 	"time"
 )
 
@@ -17,9 +22,11 @@ var (
 	rateLimitRoute = "rate-limit-route"
 	retryRoute     = "retry-route"
 	proxyRoute     = "proxy-route"
+	circuitRoute   = "circuit-route"
 	//googleUrl      = "https://www.google.com/search?q=test"
 	twitterUrl  = "https://www.twitter.com"
 	facebookUrl = "https://www.facebook.com"
+	circuitUrl  = "https://www.circuit.com"
 	//instagramUrl   = "https://www.instagram.com"
 
 	/*
@@ -61,7 +68,7 @@ func rateLimiterSetValues(limit rate.Limit, burst int) url.Values {
 	return v
 }
 
-func testHttpLog(traffic string, start time.Time, duration time.Duration, req *http.Request, resp *http.Response, routeName string, timeout int, limit rate.Limit, burst int, rateThreshold, retry, proxy, proxyThreshold, statusFlags string) {
+func testHttpLog(traffic string, start time.Time, duration time.Duration, req *http.Request, resp *http.Response, routeName, priority string, timeout int, limit rate.Limit, burst int, rateThreshold, rateKey, retry string, attempt int, proxy, proxyThreshold, statusFlags string) {
 	s := fmt.Sprintf("\"traffic\":\"%v\","+
 		"\"route-name\":\"%v\","+
 		"\"method\":\"%v\","+
@@ -103,6 +110,12 @@ func init() {
 		if req.URL.String() == instagramUrl {
 			return proxyRoute, true
 		}
+		if req.URL.String() == circuitUrl {
+			return circuitRoute, true
+		}
+		if req.URL.Host == grpcTarget {
+			return grpcRoute, true
+		}
 		return "", true
 	})
 
@@ -110,6 +123,9 @@ func init() {
 	controller.EgressTable().AddController(controller.NewRoute(rateLimitRoute, controller.EgressTraffic, "", false, controller.NewRateLimiterConfig(true, 503, 2000, 10, "95/500ms")))
 	controller.EgressTable().AddController(controller.NewRoute(retryRoute, controller.EgressTraffic, "", false, controller.NewTimeoutConfig(true, 504, time.Millisecond), controller.NewRetryConfig(true, 0, 0, 0, []int{503, 504})))
 	controller.EgressTable().AddController(controller.NewRoute(proxyRoute, controller.EgressTraffic, "", false, controller.NewProxyConfig(true, googleUrl, nil, nil, "10")))
+	controller.EgressTable().AddController(controller.NewRoute(circuitRoute, controller.EgressTraffic, "", false, controller.NewCircuitBreakerConfig(true, 0, 0, 1, 0, time.Millisecond*50, nil),
+		controller.NewBulkheadConfig(true, 0, 1, 0, 0), controller.NewRetryConfig(true, 100, 10, 0, []int{503})))
+	controller.EgressTable().AddController(controller.NewRoute(grpcRoute, controller.EgressTraffic, "", false, controller.NewRetryConfig(true, 100, 10, 0, []int{503})))
 
 	controller.SetLogFn(testHttpLog)
 
@@ -281,3 +297,168 @@ func Example_Controller_Proxy() {
 	//test: RoundTrip(handler:true) -> [status_code:200] [err:<nil>]
 	
 }
+
+func Example_bufferBody() {
+	req, _ := http.NewRequest(http.MethodPost, "https://www.google.com", nil)
+	req.Body = io.NopCloser(strings.NewReader("replayable body"))
+	ok, err := bufferBody(req, 64)
+	buf, _ := io.ReadAll(req.Body)
+	rewindBody(req)
+	buf2, _ := io.ReadAll(req.Body)
+	fmt.Printf("test: bufferBody(replayable) -> [ok:%v] [err:%v] [first:%v] [rewind:%v]\n", ok, err, string(buf), string(buf2))
+
+	req, _ = http.NewRequest(http.MethodPost, "https://www.google.com", nil)
+	req.Body = io.NopCloser(strings.NewReader("body larger than the maximum"))
+	ok, err = bufferBody(req, 8)
+	buf, _ = io.ReadAll(req.Body)
+	fmt.Printf("test: bufferBody(too-large) -> [ok:%v] [err:%v] [body:%v] [get-body:%v]\n", ok, err, string(buf), req.GetBody != nil)
+
+	req, _ = http.NewRequest(http.MethodPut, "https://www.google.com", strings.NewReader("get body"))
+	ok, err = bufferBody(req, 4)
+	fmt.Printf("test: bufferBody(get-body) -> [ok:%v] [err:%v]\n", ok, err)
+
+	//Output:
+	//test: bufferBody(replayable) -> [ok:true] [err:<nil>] [first:replayable body] [rewind:replayable body]
+	//test: bufferBody(too-large) -> [ok:false] [err:<nil>] [body:body larger than the maximum] [get-body:false]
+	//test: bufferBody(get-body) -> [ok:true] [err:<nil>]
+
+}
+
+type headerTripper struct {
+	header http.Header
+}
+
+func (t *headerTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.header = req.Header.Clone()
+	return &http.Response{Request: req, StatusCode: http.StatusOK}, nil
+}
+
+func Example_exchange_Deadline() {
+	rt := &headerTripper{}
+	w := &controllerWrapper{rt}
+	// Use a separate table, as the actuator examples signal the timeout route
+	t := controller.NewEgressTable()
+	t.AddController(controller.NewRoute(timeoutRoute, controller.EgressTraffic, "", false, controller.NewTimeoutConfig(true, 504, time.Millisecond)))
+	config := controller.NewTimeoutConfig(true, 504, time.Millisecond)
+	config.Propagate = true
+	t.AddController(controller.NewRoute("propagate-route", controller.EgressTraffic, "", false, config))
+	tc := t.LookupByName(timeoutRoute).Timeout()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, "https://www.google.com", nil)
+	resp, err, statusFlags := w.exchange(nil, req)
+	fmt.Printf("test: exchange(budget) -> [status:%v] [err:%v] [flags:%v] [propagated:%v]\n", resp.StatusCode, err, statusFlags, rt.header.Get(controller.RequestTimeoutHeaderName) != "")
+
+	resp, err, statusFlags = w.exchange(tc, req)
+	fmt.Printf("test: exchange(route-timeout) -> [status:%v] [err:%v] [flags:%v] [propagated:%v]\n", resp.StatusCode, err, statusFlags, rt.header.Get(controller.RequestTimeoutHeaderName) != "")
+
+	resp, err, statusFlags = w.exchange(t.LookupByName("propagate-route").Timeout(), req)
+	fmt.Printf("test: exchange(propagate) -> [status:%v] [err:%v] [flags:%v] [%v:%v] [request:%v]\n", resp.StatusCode, err, statusFlags, controller.RequestTimeoutHeaderName, rt.header.Get(controller.RequestTimeoutHeaderName), req.Header.Get(controller.RequestTimeoutHeaderName))
+
+	ctx2, cancel2 := context.WithTimeout(context.Background(), 0)
+	defer cancel2()
+	req, _ = http.NewRequestWithContext(ctx2, http.MethodGet, "https://www.google.com", nil)
+	resp, err, statusFlags = w.exchange(tc, req)
+	fmt.Printf("test: exchange(expired) -> [resp:%v] [err:%v] [flags:%v]\n", resp, err, statusFlags)
+
+	resp, err = w.RoundTrip(req)
+	fmt.Printf("test: RoundTrip(expired) -> [resp:%v] [err:%v] [deadline-exceeded:%v]\n", resp, err, errors.Is(err, context.DeadlineExceeded))
+
+	//Output:
+	//test: exchange(budget) -> [status:200] [err:<nil>] [flags:] [propagated:false]
+	//test: exchange(route-timeout) -> [status:200] [err:<nil>] [flags:] [propagated:false]
+	//test: exchange(propagate) -> [status:200] [err:<nil>] [flags:] [X-Request-Timeout:1] [request:]
+	//test: exchange(expired) -> [resp:<nil>] [err:context deadline exceeded] [flags:DX]
+	//test: Write() -> [{"traffic":"egress","route-name":"*","method":"GET","host":"www.google.com","path":"","protocol":"HTTP/1.1","status-code":504,"status-flags":"DX","bytes-received":-1,"bytes-sent":0,"timeout-ms":-1,"rate-limit":-1,"rate-burst":-1,"rate-threshold":,"retry":,"proxy":, "proxy-threshold":}]
+	//test: RoundTrip(expired) -> [resp:<nil>] [err:context deadline exceeded] [deadline-exceeded:true]
+
+}
+
+type statusTripper struct {
+	statusCode int
+}
+
+func (t *statusTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	return &http.Response{Request: req, StatusCode: t.statusCode}, nil
+}
+
+type errReader struct{}
+
+func (errReader) Read(p []byte) (int, error) { return 0, io.ErrUnexpectedEOF }
+
+func Example_Controller_CircuitBreaker_HalfOpen() {
+	ctrl := controller.EgressTable().LookupByName(circuitRoute)
+	w := &controllerWrapper{&statusTripper{http.StatusInternalServerError}}
+	req, _ := http.NewRequest(http.MethodGet, circuitUrl, nil)
+	resp, _ := w.RoundTrip(req)
+	fmt.Printf("test: RoundTrip(500) -> [status:%v] [state:%v]\n", resp.StatusCode, ctrl.CircuitBreaker().CircuitState())
+
+	// A bulkhead rejection does not use the half-open probe
+	time.Sleep(time.Millisecond * 60)
+	ctrl.Bulkhead().Acquire(context.Background())
+	req, _ = http.NewRequest(http.MethodGet, circuitUrl, nil)
+	resp, _ = w.RoundTrip(req)
+	ctrl.Bulkhead().Release()
+	fmt.Printf("test: RoundTrip(bulkhead) -> [status:%v] [state:%v]\n", resp.StatusCode, ctrl.CircuitBreaker().CircuitState())
+
+	// A request body that cannot be buffered releases the half-open probe
+	req, _ = http.NewRequest(http.MethodPut, circuitUrl, errReader{})
+	_, err := w.RoundTrip(req)
+	fmt.Printf("test: RoundTrip(body) -> [err:%v] [state:%v]\n", err, ctrl.CircuitBreaker().CircuitState())
+
+	w = &controllerWrapper{&statusTripper{http.StatusOK}}
+	req, _ = http.NewRequest(http.MethodGet, circuitUrl, nil)
+	resp, _ = w.RoundTrip(req)
+	fmt.Printf("test: RoundTrip(200) -> [status:%v] [state:%v]\n", resp.StatusCode, ctrl.CircuitBreaker().CircuitState())
+
+	//Output:
+	//test: Write() -> [{"traffic":"egress","route-name":"circuit-route","method":"GET","host":"www.circuit.com","path":"","protocol":"HTTP/1.1","status-code":500,"status-flags":"","bytes-received":-1,"bytes-sent":0,"timeout-ms":-1,"rate-limit":-1,"rate-burst":-1,"rate-threshold":,"retry":false,"proxy":, "proxy-threshold":}]
+	//test: RoundTrip(500) -> [status:500] [state:open]
+	//test: Write() -> [{"traffic":"egress","route-name":"circuit-route","method":"GET","host":"www.circuit.com","path":"","protocol":"HTTP/1.1","status-code":503,"status-flags":"BH","bytes-received":-1,"bytes-sent":0,"timeout-ms":-1,"rate-limit":-1,"rate-burst":-1,"rate-threshold":,"retry":false,"proxy":, "proxy-threshold":}]
+	//test: RoundTrip(bulkhead) -> [status:503] [state:half-open]
+	//test: RoundTrip(body) -> [err:unexpected EOF] [state:half-open]
+	//test: Write() -> [{"traffic":"egress","route-name":"circuit-route","method":"GET","host":"www.circuit.com","path":"","protocol":"HTTP/1.1","status-code":200,"status-flags":"","bytes-received":-1,"bytes-sent":0,"timeout-ms":-1,"rate-limit":-1,"rate-burst":-1,"rate-threshold":,"retry":false,"proxy":, "proxy-threshold":}]
+	//test: RoundTrip(200) -> [status:200] [state:closed]
+
+}
+
+type errTripper struct {
+	err error
+}
+
+func (t *errTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	return nil, t.err
+}
+
+func Example_Controller_Error() {
+	w := &controllerWrapper{&errTripper{syscall.ECONNREFUSED}}
+	req, _ := http.NewRequest(http.MethodGet, "https://www.refused.com", nil)
+	resp, err := w.RoundTrip(req)
+	fmt.Printf("test: RoundTrip(refused) -> [resp:%v] [err:%v]\n", resp, err)
+
+	//Output:
+	//test: Write() -> [{"traffic":"egress","route-name":"*","method":"GET","host":"www.refused.com","path":"","protocol":"HTTP/1.1","status-code":0,"status-flags":"UF","bytes-received":-1,"bytes-sent":0,"timeout-ms":-1,"rate-limit":-1,"rate-burst":-1,"rate-threshold":,"retry":,"proxy":, "proxy-threshold":}]
+	//test: RoundTrip(refused) -> [resp:<nil>] [err:connection refused]
+}
+
+func Example_retryWait() {
+	t := controller.NewEgressTable()
+	t.AddController(controller.NewRoute("retry-wait-route", controller.EgressTraffic, "", false, controller.NewRetryConfig(true, 100, 10, time.Millisecond*10, []int{503})))
+	rc := t.LookupByName("retry-wait-route").Retry()
+	resp := &http.Response{StatusCode: http.StatusServiceUnavailable, Header: make(http.Header)}
+	fmt.Printf("test: retryWait(none) -> [wait:%v]\n", retryWait(rc, resp, time.Millisecond*10))
+
+	resp.Header.Set(controller.RetryAfterHeaderName, "2")
+	fmt.Printf("test: retryWait(2) -> [wait:%v]\n", retryWait(rc, resp, time.Millisecond*10))
+
+	// Without a maximum wait, the Retry-After is bounded by the default
+	resp.Header.Set(controller.RetryAfterHeaderName, "86400")
+	fmt.Printf("test: retryWait(86400) -> [wait:%v]\n", retryWait(rc, resp, time.Millisecond*10))
+
+	//Output:
+	//test: retryWait(none) -> [wait:10ms]
+	//test: retryWait(2) -> [wait:2s]
+	//test: retryWait(86400) -> [wait:1m0s]
+
+}
@@ -0,0 +1,421 @@
+package middleware
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"github.com/go-sre/core/runtime"
+	"github.com/go-sre/host/controller"
+	"google.golang.org/grpc/codes"
+	"io"
+	"reflect"
+	"strings"
+)
+
+const (
+	SqlQueryMethod = "query"
+	SqlExecMethod  = "exec"
+)
+
+// SqlUrn - function type that maps a statement to the URN used for the egress route lookup
+type SqlUrn func(method, query string) string
+
+// NewSqlUrn - create a SqlUrn that maps a statement to "urn:<nid>:<method>.<table>", "SELECT * FROM users" is
+// "urn:postgres:query.users" for a "postgres" nid. Statements without a table are mapped to "urn:<nid>:<method>"
+func NewSqlUrn(nid string) SqlUrn {
+	return func(method, query string) string {
+		if table := sqlTable(query); table != "" {
+			return fmt.Sprintf("urn:%v:%v.%v", nid, method, table)
+		}
+		return fmt.Sprintf("urn:%v:%v", nid, method)
+	}
+}
+
+// sqlTable - the first table named in a statement
+func sqlTable(query string) string {
+	tokens := strings.FieldsFunc(query, func(r rune) bool {
+		return r == ' ' || r == '\t' || r == '\n' || r == '\r' || r == '(' || r == ')' || r == ',' || r == ';'
+	})
+	for i := 0; i < len(tokens)-1; i++ {
+		switch strings.ToLower(tokens[i]) {
+		case "from", "into", "update", "join":
+			return strings.ToLower(strings.Trim(tokens[i+1], "\"`[]"))
+		}
+	}
+	return ""
+}
+
+// SqlStatusCode - the status code of a driver error, status codes are gRPC status codes as used by controller.Apply
+func SqlStatusCode(err error) int {
+	switch {
+	case err == nil:
+		return int(codes.OK)
+	case errors.Is(err, context.DeadlineExceeded):
+		return controller.StatusDeadlineExceeded
+	case errors.Is(err, context.Canceled):
+		return int(codes.Canceled)
+	case errors.Is(err, driver.ErrBadConn):
+		return int(codes.Unavailable)
+	case errors.Is(err, driver.ErrSkip):
+		return int(codes.Unimplemented)
+	}
+	return int(codes.Unknown)
+}
+
+// ErrSqlRateLimited - error returned when a statement is rejected by the egress controller
+var ErrSqlRateLimited = errors.New("sql statement was rate limited")
+
+// ControllerSqlConnector - database/sql connector that applies the egress controller of each query and exec
+func ControllerSqlConnector(c driver.Connector, urn SqlUrn) driver.Connector {
+	return &sqlConnector{c, urn}
+}
+
+// ControllerSqlDriverConnector - database/sql connector for a driver and data source name, that applies the egress
+// controller of each query and exec
+func ControllerSqlDriverConnector(d driver.Driver, dsn string, urn SqlUrn) (driver.Connector, error) {
+	if dc, ok := d.(driver.DriverContext); ok {
+		c, err := dc.OpenConnector(dsn)
+		if err != nil {
+			return nil, err
+		}
+		return ControllerSqlConnector(c, urn), nil
+	}
+	return ControllerSqlConnector(&dsnConnector{dsn, d}, urn), nil
+}
+
+// applySql - apply the egress controller of a statement, the returned function logs the status of the error
+// and must be called once the statement is done
+func applySql(ctx context.Context, urn SqlUrn, method, query string) (func(err error), context.Context, bool) {
+	var status error
+	fn, newCtx, limited := controller.Apply(ctx, func() int {
+		if status == ErrSqlRateLimited {
+			return controller.StatusRateLimited
+		}
+		return SqlStatusCode(status)
+	}, urn(method, query), runtime.ContextRequestId(ctx), strings.ToUpper(method))
+	return func(err error) {
+		status = err
+		fn()
+	}, newCtx, limited
+}
+
+// sqlApplied - a controller applied to a statement the driver skipped, database/sql then prepares the statement
+// on the same connection, and the prepared statement uses the applied controller
+type sqlApplied struct {
+	query string
+	done  func(err error)
+	ctx   context.Context
+}
+
+type dsnConnector struct {
+	dsn string
+	d   driver.Driver
+}
+
+func (c *dsnConnector) Connect(_ context.Context) (driver.Conn, error) {
+	return c.d.Open(c.dsn)
+}
+
+func (c *dsnConnector) Driver() driver.Driver {
+	return c.d
+}
+
+type sqlConnector struct {
+	driver.Connector
+	urn SqlUrn
+}
+
+func (c *sqlConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := c.Connector.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &sqlConn{Conn: conn, urn: c.urn}, nil
+}
+
+func (c *sqlConnector) Close() error {
+	if cl, ok := c.Connector.(io.Closer); ok {
+		return cl.Close()
+	}
+	return nil
+}
+
+type sqlConn struct {
+	driver.Conn
+	urn     SqlUrn
+	skipped *sqlApplied
+}
+
+func (c *sqlConn) Prepare(query string) (driver.Stmt, error) {
+	return c.PrepareContext(context.Background(), query)
+}
+
+func (c *sqlConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	applied := c.skipped
+	c.skipped = nil
+	if applied != nil && applied.query != query {
+		applied.done(driver.ErrSkip)
+		applied = nil
+	}
+	var stmt driver.Stmt
+	var err error
+	if pc, ok := c.Conn.(driver.ConnPrepareContext); ok {
+		stmt, err = pc.PrepareContext(ctx, query)
+	} else {
+		stmt, err = c.Conn.Prepare(query)
+	}
+	if err != nil {
+		if applied != nil {
+			applied.done(err)
+		}
+		return nil, err
+	}
+	return &sqlStmt{stmt, query, c.urn, applied}, nil
+}
+
+func (c *sqlConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	if bt, ok := c.Conn.(driver.ConnBeginTx); ok {
+		return bt.BeginTx(ctx, opts)
+	}
+	return c.Conn.Begin()
+}
+
+// QueryContext - for a driver.ErrSkip from the driver, the statement is then prepared and the applied controller is
+// used by the prepared statement, so the statement is limited, logged and recorded once
+func (c *sqlConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	qc, ok := c.Conn.(driver.QueryerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	fn, ctx, limited := applySql(ctx, c.urn, SqlQueryMethod, query)
+	if limited {
+		fn(ErrSqlRateLimited)
+		return nil, ErrSqlRateLimited
+	}
+	rows, err := qc.QueryContext(ctx, query, args)
+	if err == driver.ErrSkip {
+		c.skipped = &sqlApplied{query: query, done: fn, ctx: ctx}
+		return nil, err
+	}
+	if err != nil {
+		fn(err)
+		return nil, err
+	}
+	return &sqlRows{Rows: rows, done: fn}, nil
+}
+
+func (c *sqlConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	ec, ok := c.Conn.(driver.ExecerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	fn, ctx, limited := applySql(ctx, c.urn, SqlExecMethod, query)
+	if limited {
+		fn(ErrSqlRateLimited)
+		return nil, ErrSqlRateLimited
+	}
+	result, err := ec.ExecContext(ctx, query, args)
+	if err == driver.ErrSkip {
+		c.skipped = &sqlApplied{query: query, done: fn, ctx: ctx}
+		return nil, err
+	}
+	fn(err)
+	return result, err
+}
+
+func (c *sqlConn) Ping(ctx context.Context) error {
+	if p, ok := c.Conn.(driver.Pinger); ok {
+		return p.Ping(ctx)
+	}
+	return nil
+}
+
+func (c *sqlConn) ResetSession(ctx context.Context) error {
+	if sr, ok := c.Conn.(driver.SessionResetter); ok {
+		return sr.ResetSession(ctx)
+	}
+	return nil
+}
+
+func (c *sqlConn) IsValid() bool {
+	if v, ok := c.Conn.(driver.Validator); ok {
+		return v.IsValid()
+	}
+	return true
+}
+
+func (c *sqlConn) CheckNamedValue(nv *driver.NamedValue) error {
+	if nc, ok := c.Conn.(driver.NamedValueChecker); ok {
+		return nc.CheckNamedValue(nv)
+	}
+	return driver.ErrSkip
+}
+
+type sqlStmt struct {
+	driver.Stmt
+	query   string
+	urn     SqlUrn
+	applied *sqlApplied
+}
+
+// apply - use the controller applied to the skipped statement, or apply the egress controller
+func (s *sqlStmt) apply(ctx context.Context, method string) (func(err error), context.Context, bool) {
+	if a := s.applied; a != nil {
+		s.applied = nil
+		return a.done, a.ctx, false
+	}
+	return applySql(ctx, s.urn, method, s.query)
+}
+
+func (s *sqlStmt) Close() error {
+	if s.applied != nil {
+		s.applied.done(driver.ErrSkip)
+		s.applied = nil
+	}
+	return s.Stmt.Close()
+}
+
+func (s *sqlStmt) Exec(args []driver.Value) (driver.Result, error) {
+	return s.ExecContext(context.Background(), namedValues(args))
+}
+
+func (s *sqlStmt) Query(args []driver.Value) (driver.Rows, error) {
+	return s.QueryContext(context.Background(), namedValues(args))
+}
+
+func (s *sqlStmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	fn, ctx, limited := s.apply(ctx, SqlExecMethod)
+	if limited {
+		fn(ErrSqlRateLimited)
+		return nil, ErrSqlRateLimited
+	}
+	var result driver.Result
+	var err error
+	if ec, ok := s.Stmt.(driver.StmtExecContext); ok {
+		result, err = ec.ExecContext(ctx, args)
+	} else {
+		result, err = s.Stmt.Exec(values(args))
+	}
+	fn(err)
+	return result, err
+}
+
+func (s *sqlStmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	fn, ctx, limited := s.apply(ctx, SqlQueryMethod)
+	if limited {
+		fn(ErrSqlRateLimited)
+		return nil, ErrSqlRateLimited
+	}
+	var rows driver.Rows
+	var err error
+	if qc, ok := s.Stmt.(driver.StmtQueryContext); ok {
+		rows, err = qc.QueryContext(ctx, args)
+	} else {
+		rows, err = s.Stmt.Query(values(args))
+	}
+	if err != nil {
+		fn(err)
+		return nil, err
+	}
+	return &sqlRows{Rows: rows, done: fn}, nil
+}
+
+func (s *sqlStmt) CheckNamedValue(nv *driver.NamedValue) error {
+	if nc, ok := s.Stmt.(driver.NamedValueChecker); ok {
+		return nc.CheckNamedValue(nv)
+	}
+	return driver.ErrSkip
+}
+
+// sqlRows - the controller timeout applies until the rows are closed, and the query is logged on close
+type sqlRows struct {
+	driver.Rows
+	done func(err error)
+	err  error
+}
+
+func (r *sqlRows) Next(dest []driver.Value) error {
+	err := r.Rows.Next(dest)
+	if err != nil && err != io.EOF {
+		r.err = err
+	}
+	return err
+}
+
+func (r *sqlRows) Close() error {
+	err := r.Rows.Close()
+	if r.done != nil {
+		if r.err == nil {
+			r.err = err
+		}
+		r.done(r.err)
+		r.done = nil
+	}
+	return err
+}
+
+func (r *sqlRows) HasNextResultSet() bool {
+	if nr, ok := r.Rows.(driver.RowsNextResultSet); ok {
+		return nr.HasNextResultSet()
+	}
+	return false
+}
+
+func (r *sqlRows) NextResultSet() error {
+	if nr, ok := r.Rows.(driver.RowsNextResultSet); ok {
+		return nr.NextResultSet()
+	}
+	return io.EOF
+}
+
+func (r *sqlRows) ColumnTypeScanType(index int) reflect.Type {
+	if ct, ok := r.Rows.(driver.RowsColumnTypeScanType); ok {
+		return ct.ColumnTypeScanType(index)
+	}
+	return reflect.TypeOf(new(any)).Elem()
+}
+
+func (r *sqlRows) ColumnTypeDatabaseTypeName(index int) string {
+	if ct, ok := r.Rows.(driver.RowsColumnTypeDatabaseTypeName); ok {
+		return ct.ColumnTypeDatabaseTypeName(index)
+	}
+	return ""
+}
+
+func (r *sqlRows) ColumnTypeLength(index int) (int64, bool) {
+	if ct, ok := r.Rows.(driver.RowsColumnTypeLength); ok {
+		return ct.ColumnTypeLength(index)
+	}
+	return 0, false
+}
+
+func (r *sqlRows) ColumnTypeNullable(index int) (bool, bool) {
+	if ct, ok := r.Rows.(driver.RowsColumnTypeNullable); ok {
+		return ct.ColumnTypeNullable(index)
+	}
+	return false, false
+}
+
+func (r *sqlRows) ColumnTypePrecisionScale(index int) (int64, int64, bool) {
+	if ct, ok := r.Rows.(driver.RowsColumnTypePrecisionScale); ok {
+		return ct.ColumnTypePrecisionScale(index)
+	}
+	return 0, 0, false
+}
+
+func namedValues(args []driver.Value) []driver.NamedValue {
+	nv := make([]driver.NamedValue, len(args))
+	for i, v := range args {
+		nv[i] = driver.NamedValue{Ordinal: i + 1, Value: v}
+	}
+	return nv
+}
+
+func values(args []driver.NamedValue) []driver.Value {
+	v := make([]driver.Value, len(args))
+	for i, nv := range args {
+		v[i] = nv.Value
+	}
+	return v
+}
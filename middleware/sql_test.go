@@ -0,0 +1,152 @@
+package middleware
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"github.com/go-sre/host/controller"
+	"io"
+	"time"
+)
+
+type testSqlConnector struct{}
+
+func (c testSqlConnector) Connect(_ context.Context) (driver.Conn, error) { return &testSqlConn{}, nil }
+func (c testSqlConnector) Driver() driver.Driver                          { return nil }
+
+type testSqlConn struct{}
+
+func (c *testSqlConn) Prepare(query string) (driver.Stmt, error) { return nil, driver.ErrSkip }
+func (c *testSqlConn) Close() error                              { return nil }
+func (c *testSqlConn) Begin() (driver.Tx, error)                 { return nil, driver.ErrSkip }
+
+func (c *testSqlConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	return &testSqlRows{ctx: ctx}, nil
+}
+
+func (c *testSqlConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	select {
+	case <-time.After(time.Millisecond * 100):
+		return driver.RowsAffected(1), nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+type testSqlRows struct {
+	ctx  context.Context
+	read bool
+}
+
+func (r *testSqlRows) Columns() []string { return []string{"name"} }
+func (r *testSqlRows) Close() error      { return nil }
+
+func (r *testSqlRows) Next(dest []driver.Value) error {
+	if r.read {
+		return io.EOF
+	}
+	if r.ctx.Err() != nil {
+		return r.ctx.Err()
+	}
+	r.read = true
+	dest[0] = "bob"
+	return nil
+}
+
+func Example_sqlTable() {
+	urn := NewSqlUrn("postgres")
+	fmt.Printf("test: NewSqlUrn() -> [%v]\n", urn(SqlQueryMethod, "SELECT name FROM users WHERE id = $1"))
+	fmt.Printf("test: NewSqlUrn() -> [%v]\n", urn(SqlExecMethod, "insert into \"Orders\" (id) values ($1)"))
+	fmt.Printf("test: NewSqlUrn() -> [%v]\n", urn(SqlExecMethod, "UPDATE users SET name = $1"))
+	fmt.Printf("test: NewSqlUrn() -> [%v]\n", urn(SqlQueryMethod, "SELECT 1"))
+
+	//Output:
+	//test: NewSqlUrn() -> [urn:postgres:query.users]
+	//test: NewSqlUrn() -> [urn:postgres:exec.orders]
+	//test: NewSqlUrn() -> [urn:postgres:exec.users]
+	//test: NewSqlUrn() -> [urn:postgres:query]
+
+}
+
+func Example_sqlConnector() {
+	route := controller.NewRoute("sql-route", controller.EgressTraffic, "", false, controller.NewTimeoutConfig(true, 504, time.Millisecond*20))
+	route.Pattern = "postgres/exec.users"
+	errs := controller.EgressTable().AddController(route)
+	fmt.Printf("test: AddController() -> [errs:%v]\n", errs)
+
+	db := sql.OpenDB(ControllerSqlConnector(testSqlConnector{}, NewSqlUrn("postgres")))
+	defer db.Close()
+
+	var name string
+	err := db.QueryRow("SELECT name FROM users WHERE id = $1", 1).Scan(&name)
+	fmt.Printf("test: QueryRow() -> [err:%v] [name:%v]\n", err, name)
+
+	_, err = db.Exec("UPDATE users SET name = $1", "bob")
+	fmt.Printf("test: Exec() -> [err:%v]\n", err)
+
+	//Output:
+	//test: AddController() -> [errs:[]]
+	//test: Write() -> [{"traffic":"egress","route-name":"*","method":"QUERY","host":"","path":"","protocol":"HTTP/1.1","status-code":0,"status-flags":"","bytes-received":-1,"bytes-sent":0,"timeout-ms":-1,"rate-limit":-1,"rate-burst":-1,"rate-threshold":,"retry":,"proxy":, "proxy-threshold":}]
+	//test: QueryRow() -> [err:<nil>] [name:bob]
+	//test: Write() -> [{"traffic":"egress","route-name":"sql-route","method":"EXEC","host":"","path":"","protocol":"HTTP/1.1","status-code":4,"status-flags":"UT","bytes-received":-1,"bytes-sent":0,"timeout-ms":20,"rate-limit":-1,"rate-burst":-1,"rate-threshold":,"retry":,"proxy":, "proxy-threshold":}]
+	//test: Exec() -> [err:context deadline exceeded]
+
+}
+
+type testSqlSkipConnector struct{}
+
+func (c testSqlSkipConnector) Connect(_ context.Context) (driver.Conn, error) {
+	return &testSqlSkipConn{}, nil
+}
+func (c testSqlSkipConnector) Driver() driver.Driver { return nil }
+
+// testSqlSkipConn - a driver that requires a prepared statement for queries with arguments
+type testSqlSkipConn struct{ testSqlConn }
+
+func (c *testSqlSkipConn) Prepare(query string) (driver.Stmt, error) { return &testSqlStmt{}, nil }
+
+func (c *testSqlSkipConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	if len(args) > 0 {
+		return nil, driver.ErrSkip
+	}
+	return c.testSqlConn.QueryContext(ctx, query, args)
+}
+
+type testSqlStmt struct{}
+
+func (s *testSqlStmt) Close() error  { return nil }
+func (s *testSqlStmt) NumInput() int { return -1 }
+func (s *testSqlStmt) Exec(args []driver.Value) (driver.Result, error) {
+	return driver.RowsAffected(1), nil
+}
+func (s *testSqlStmt) Query(args []driver.Value) (driver.Rows, error) {
+	return &testSqlRows{ctx: context.Background()}, nil
+}
+
+func Example_sqlConnector_Skip() {
+	route := controller.NewRoute("sql-skip-route", controller.EgressTraffic, "", false, controller.NewRateLimiterConfig(true, 429, 1, 1, ""))
+	route.Pattern = "postgres/query.accounts"
+	errs := controller.EgressTable().AddController(route)
+	fmt.Printf("test: AddController() -> [errs:%v]\n", errs)
+
+	db := sql.OpenDB(ControllerSqlConnector(testSqlSkipConnector{}, NewSqlUrn("postgres")))
+	defer db.Close()
+
+	var name string
+	err := db.QueryRow("SELECT name FROM users WHERE id = $1", 1).Scan(&name)
+	fmt.Printf("test: QueryRow() -> [err:%v] [name:%v]\n", err, name)
+
+	// The prepared statement uses the rate limiter token of the skipped statement
+	name = ""
+	err = db.QueryRow("SELECT name FROM accounts WHERE id = $1", 1).Scan(&name)
+	fmt.Printf("test: QueryRow(rate-limited) -> [err:%v] [name:%v]\n", err, name)
+
+	//Output:
+	//test: AddController() -> [errs:[]]
+	//test: Write() -> [{"traffic":"egress","route-name":"*","method":"QUERY","host":"","path":"","protocol":"HTTP/1.1","status-code":0,"status-flags":"","bytes-received":-1,"bytes-sent":0,"timeout-ms":-1,"rate-limit":-1,"rate-burst":-1,"rate-threshold":,"retry":,"proxy":, "proxy-threshold":}]
+	//test: QueryRow() -> [err:<nil>] [name:bob]
+	//test: Write() -> [{"traffic":"egress","route-name":"sql-skip-route","method":"QUERY","host":"","path":"","protocol":"HTTP/1.1","status-code":0,"status-flags":"","bytes-received":-1,"bytes-sent":0,"timeout-ms":-1,"rate-limit":1,"rate-burst":1,"rate-threshold":,"retry":,"proxy":, "proxy-threshold":}]
+	//test: QueryRow(rate-limited) -> [err:<nil>] [name:bob]
+
+}